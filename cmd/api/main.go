@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"strings"
@@ -10,9 +11,11 @@ import (
 	"unila_helpdesk_backend/internal/domain"
 	"unila_helpdesk_backend/internal/fcm"
 	"unila_helpdesk_backend/internal/handler"
+	"unila_helpdesk_backend/internal/jwtkeys"
 	"unila_helpdesk_backend/internal/middleware"
 	"unila_helpdesk_backend/internal/repository"
 	"unila_helpdesk_backend/internal/service"
+	"unila_helpdesk_backend/internal/webpush"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -42,23 +45,48 @@ func main() {
 	userRepo := repository.NewUserRepository(database)
 	categoryRepo := repository.NewCategoryRepository(database)
 	ticketRepo := repository.NewTicketRepository(database)
+	ticketViewRepo := repository.NewTicketViewRepository(database)
+	tagRepo := repository.NewTagRepository(database)
+	cannedRepo := repository.NewCannedResponseRepository(database)
+	articleRepo := repository.NewArticleRepository(database)
+	announcementRepo := repository.NewAnnouncementRepository(database)
+	incidentRepo := repository.NewServiceIncidentRepository(database)
 	surveyRepo := repository.NewSurveyRepository(database)
 	notificationRepo := repository.NewNotificationRepository(database)
 	tokenRepo := repository.NewFCMTokenRepository(database)
 	refreshTokenRepo := repository.NewRefreshTokenRepository(database)
+	sessionRepo := repository.NewSessionRepository(database)
+	oidcStateRepo := repository.NewOIDCStateRepository(database)
 	attachmentRepo := repository.NewAttachmentRepository(database)
 	reportRepo := repository.NewReportRepository(database)
+	auditLogRepo := repository.NewAuditLogRepository(database)
+	pushLogRepo := repository.NewPushLogRepository(database)
+	webPushRepo := repository.NewWebPushRepository(database)
 
-	for _, category := range service.DefaultCategories() {
-		_ = categoryRepo.Upsert(category)
+	if err := service.SeedDefaultCategories(categoryRepo); err != nil {
+		log.Fatalf("seed categories failed: %v", err)
 	}
-	if err := service.CleanupDeprecatedCategories(database, service.CategoryLainnya); err != nil {
-		log.Fatalf("cleanup deprecated categories failed: %v", err)
+
+	var signingKeys *jwtkeys.KeySet
+	if cfg.JWTKeyDir != "" {
+		signingKeys, err = jwtkeys.LoadDir(cfg.JWTKeyDir, cfg.JWTActiveKeyID)
+		if err != nil {
+			log.Fatalf("load JWT keys failed: %v", err)
+		}
+		log.Printf("signing JWT with kid=%s alg=%s", signingKeys.Active().ID, signingKeys.Active().Algorithm)
 	}
 
-	authService := service.NewAuthService(cfg, userRepo, refreshTokenRepo)
-	categoryService := service.NewCategoryService(categoryRepo)
-	fcmClient := fcm.NewClient(cfg.FCMEnabled, cfg.FCMCredentials)
+	auditService := service.NewAuditService(auditLogRepo)
+	authService := service.NewAuthService(cfg, userRepo, refreshTokenRepo, sessionRepo, signingKeys, auditService)
+	oidcService := service.NewOIDCService(cfg, oidcStateRepo, authService)
+	categoryService := service.NewCategoryService(categoryRepo, auditService)
+	pushLogService := service.NewPushLogService(pushLogRepo, tokenRepo)
+	fcmClient := fcm.NewClient(fcm.Options{
+		Backend:     cfg.PushBackend,
+		Credentials: cfg.FCMCredentials,
+		FakeURL:     cfg.PushFakeURL,
+	})
+	fcmClient.SetRecorder(pushLogService)
 	ticketService := service.NewTicketService(
 		ticketRepo,
 		categoryRepo,
@@ -66,19 +94,60 @@ func main() {
 		tokenRepo,
 		attachmentRepo,
 		fcmClient,
+		auditService,
 		domain.TicketStatus(cfg.TicketInitialStatus),
 	)
-	surveyService := service.NewSurveyService(surveyRepo, ticketRepo)
-	notificationService := service.NewNotificationService(notificationRepo, tokenRepo)
-	reportService := service.NewReportService(reportRepo, categoryRepo, surveyRepo)
+	webPushClient, err := webpush.NewClient(cfg.VAPIDPublicKey, cfg.VAPIDPrivateKey, cfg.VAPIDSubject)
+	if err != nil {
+		log.Fatalf("invalid VAPID keys: %v", err)
+	}
+	webPushService := service.NewWebPushService(
+		webPushRepo,
+		userRepo,
+		ticketRepo,
+		categoryRepo,
+		pushLogRepo,
+		webPushClient,
+		cfg.SLAWarningWindow,
+	)
+	ticketService.SetStaffNotifier(webPushService)
+	ticketViewService := service.NewTicketViewService(ticketViewRepo, ticketRepo, ticketService)
+	tagService := service.NewTagService(tagRepo, ticketRepo, ticketService, auditService)
+	cannedService := service.NewCannedResponseService(cannedRepo, categoryRepo, ticketRepo, ticketService, auditService)
+	knowledgeBaseService := service.NewKnowledgeBaseService(articleRepo, categoryRepo, ticketRepo, ticketService, auditService)
+	announcementService := service.NewAnnouncementService(
+		announcementRepo,
+		categoryRepo,
+		ticketRepo,
+		notificationRepo,
+		tokenRepo,
+		fcmClient,
+		auditService,
+	)
+	statusService := service.NewStatusService(incidentRepo, categoryRepo, announcementService, auditService)
+	surveyService := service.NewSurveyService(surveyRepo, ticketRepo, auditService)
+	notificationService := service.NewNotificationService(notificationRepo, tokenRepo, sessionRepo, fcmClient)
+	sessionService := service.NewSessionService(userRepo, sessionRepo, refreshTokenRepo, notificationService, auditService)
+	reportService := service.NewReportService(reportRepo, categoryRepo, surveyRepo, tagRepo, articleRepo)
 
 	authHandler := handler.NewAuthHandler(authService)
+	sessionHandler := handler.NewSessionHandler(sessionService)
+	oidcHandler := handler.NewOIDCHandler(oidcService)
 	categoryHandler := handler.NewCategoryHandler(categoryService)
 	ticketHandler := handler.NewTicketHandler(ticketService)
+	ticketViewHandler := handler.NewTicketViewHandler(ticketViewService)
+	tagHandler := handler.NewTagHandler(tagService)
+	cannedHandler := handler.NewCannedResponseHandler(cannedService)
+	knowledgeBaseHandler := handler.NewKnowledgeBaseHandler(knowledgeBaseService)
+	announcementHandler := handler.NewAnnouncementHandler(announcementService)
+	statusHandler := handler.NewStatusHandler(statusService)
 	surveyHandler := handler.NewSurveyHandler(surveyService)
 	notificationHandler := handler.NewNotificationHandler(notificationService)
 	reportHandler := handler.NewReportHandler(reportService)
 	uploadHandler := handler.NewUploadHandler(cfg.BaseURL, attachmentRepo)
+	auditHandler := handler.NewAuditHandler(auditService)
+	pushLogHandler := handler.NewPushLogHandler(pushLogService)
+	webPushHandler := handler.NewWebPushHandler(webPushService)
 
 	router := gin.Default()
 	router.MaxMultipartMemory = 8 << 20
@@ -87,6 +156,7 @@ func main() {
 		corsOrigins[i] = strings.TrimSpace(origin)
 	}
 	router.Use(middleware.CORSMiddleware(corsOrigins))
+	router.Use(middleware.AuditMiddleware(auditService))
 
 	authRequired := middleware.AuthMiddleware(authService, userRepo, true)
 	authOptional := middleware.AuthMiddleware(authService, userRepo, false)
@@ -100,13 +170,46 @@ func main() {
 	})
 
 	authHandler.RegisterRoutes(api)
+	sessionHandler.RegisterRoutes(public, authGroup)
+	oidcHandler.RegisterRoutes(api)
+	sessionHandler.RegisterAdminRoutes(adminGroup)
 	categoryHandler.RegisterRoutes(public)
 	categoryHandler.RegisterAdminRoutes(adminGroup)
 	ticketHandler.RegisterRoutes(public, authGroup)
+	ticketHandler.RegisterAdminRoutes(adminGroup)
+	ticketViewHandler.RegisterRoutes(adminGroup)
+	tagHandler.RegisterRoutes(adminGroup)
+	cannedHandler.RegisterRoutes(adminGroup)
+	knowledgeBaseHandler.RegisterRoutes(public)
+	knowledgeBaseHandler.RegisterAdminRoutes(adminGroup)
+	announcementHandler.RegisterRoutes(public)
+	announcementHandler.RegisterAdminRoutes(adminGroup)
+	statusHandler.RegisterRoutes(api)
+	statusHandler.RegisterAdminRoutes(adminGroup)
 	surveyHandler.RegisterRoutes(public, authGroup, adminGroup)
 	notificationHandler.RegisterRoutes(authGroup)
 	reportHandler.RegisterRoutes(adminGroup)
 	uploadHandler.RegisterRoutes(public)
+	auditHandler.RegisterRoutes(adminGroup)
+	pushLogHandler.RegisterRoutes(adminGroup)
+	webPushHandler.RegisterRoutes(api)
+	webPushHandler.RegisterAdminRoutes(adminGroup)
+
+	go authService.RunCleanup(context.Background(), cfg.AuthCleanupInterval, cfg.SessionRetention)
+	go pushLogService.RunCleanup(context.Background(), cfg.AuthCleanupInterval, cfg.PushLogRetention)
+	go func() {
+		subscribed, err := notificationService.SubscribePendingTokens(context.Background())
+		if err != nil {
+			log.Printf("subscribe pending fcm tokens failed: %v", err)
+			return
+		}
+		if subscribed > 0 {
+			log.Printf("subscribed %d existing fcm tokens to %s", subscribed, fcm.TopicAnnouncements)
+		}
+	}()
+	if webPushService.Enabled() {
+		go webPushService.RunSLAWatcher(context.Background(), cfg.SLAWatchInterval)
+	}
 
 	log.Printf("%s running on :%s", cfg.AppName, cfg.HTTPPort)
 	if err := router.Run(":" + cfg.HTTPPort); err != nil {
@@ -128,8 +231,8 @@ func validateConfig(cfg config.Config) {
 	if strings.TrimSpace(cfg.BaseURL) == "" {
 		log.Fatal("BASE_URL is required")
 	}
-	if strings.TrimSpace(cfg.JWTSecret) == "" {
-		log.Fatal("JWT_SECRET is required")
+	if strings.TrimSpace(cfg.JWTSecret) == "" && strings.TrimSpace(cfg.JWTKeyDir) == "" {
+		log.Fatal("JWT_SECRET or JWT_KEY_DIR is required")
 	}
 	if cfg.JWTExpiry == 0 {
 		log.Fatal("JWT_EXPIRY is required")
@@ -149,6 +252,20 @@ func validateConfig(cfg config.Config) {
 	if strings.TrimSpace(cfg.CORSOrigins) == "" {
 		log.Fatal("CORS_ORIGINS is required")
 	}
+	for _, backend := range strings.Split(cfg.AuthBackends, ",") {
+		switch strings.ToLower(strings.TrimSpace(backend)) {
+		case "", "local":
+		case "ldap":
+			if strings.TrimSpace(cfg.LDAPURL) == "" || strings.TrimSpace(cfg.LDAPBaseDN) == "" {
+				log.Fatal("LDAP_URL and LDAP_BASE_DN are required when AUTH_BACKENDS includes ldap")
+			}
+			if !strings.Contains(cfg.LDAPUserFilter, "%s") && !strings.Contains(cfg.LDAPUserFilter, "%[1]s") {
+				log.Fatalf("LDAP_USER_FILTER must contain %q for the username", "%s")
+			}
+		default:
+			log.Fatalf("AUTH_BACKENDS contains unknown backend %q", strings.TrimSpace(backend))
+		}
+	}
 
 	// Production-specific validation
 	if strings.EqualFold(cfg.Environment, "production") {
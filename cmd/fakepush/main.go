@@ -0,0 +1,21 @@
+// Command fakepush menjalankan server push palsu (internal/fcm/fcmtest) untuk
+// PUSH_BACKEND=http. Payload yang diterima dapat dilihat di GET /messages dan
+// dikosongkan dengan DELETE /messages.
+package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+
+	"unila_helpdesk_backend/internal/fcm/fcmtest"
+)
+
+func main() {
+	addr := os.Getenv("FAKEPUSH_ADDR")
+	if addr == "" {
+		addr = ":9099"
+	}
+	log.Printf("fakepush listening on %s", addr)
+	log.Fatal(http.ListenAndServe(addr, fcmtest.NewServer()))
+}
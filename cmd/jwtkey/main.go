@@ -0,0 +1,43 @@
+// Command jwtkey membuat private key penandatangan JWT baru di JWT_KEY_DIR.
+//
+//	go run ./cmd/jwtkey -alg EdDSA -dir keys
+//
+// Nama file default berbasis tanggal sehingga kunci terbaru otomatis menjadi kunci
+// aktif jika JWT_ACTIVE_KID kosong.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"unila_helpdesk_backend/internal/jwtkeys"
+)
+
+func main() {
+	algorithm := flag.String("alg", jwtkeys.AlgEdDSA, "algoritma kunci: EdDSA atau RS256")
+	dir := flag.String("dir", "keys", "direktori kunci (JWT_KEY_DIR)")
+	kid := flag.String("kid", time.Now().Format("20060102-150405"), "kid sekaligus nama file")
+	flag.Parse()
+
+	key, err := jwtkeys.Generate(*algorithm)
+	if err != nil {
+		log.Fatalf("generate key failed: %v", err)
+	}
+	if err := os.MkdirAll(*dir, 0o700); err != nil {
+		log.Fatalf("create key dir failed: %v", err)
+	}
+	path := filepath.Join(*dir, *kid+".pem")
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		log.Fatalf("write key failed: %v", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(key); err != nil {
+		log.Fatalf("write key failed: %v", err)
+	}
+	fmt.Printf("kid=%s alg=%s file=%s\n", *kid, *algorithm, path)
+}
@@ -0,0 +1,83 @@
+// Command mockldap menjalankan server LDAP in-memory (internal/ldapauth/ldaptest)
+// berisi akun contoh untuk menguji AUTH_BACKENDS=ldap secara lokal. Semua user
+// memakai password "password"; akun layanan memakai password "helpdesk".
+//
+//	go run ./cmd/mockldap
+//	AUTH_BACKENDS=local,ldap LDAP_URL=ldap://localhost:3893 LDAP_BASE_DN=dc=unila,dc=local \
+//	  LDAP_BIND_DN=cn=helpdesk,ou=services,dc=unila,dc=local LDAP_BIND_PASSWORD=helpdesk \
+//	  LDAP_ADMIN_GROUPS=helpdesk-admin
+package main
+
+import (
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+
+	"unila_helpdesk_backend/internal/ldapauth/ldaptest"
+)
+
+const baseDN = "dc=unila,dc=local"
+
+func main() {
+	addr := strings.TrimSpace(os.Getenv("MOCKLDAP_ADDR"))
+	if addr == "" {
+		addr = ":3893"
+	}
+	server, err := ldaptest.Listen(addr, directory())
+	if err != nil {
+		log.Fatalf("mock ldap failed: %v", err)
+	}
+	log.Printf("mock ldap %s listening on %s", baseDN, addr)
+	for _, entry := range directory() {
+		if uid := entry.Attributes["uid"]; len(uid) > 0 {
+			log.Printf("  user %-10s %s", uid[0], entry.DN)
+		}
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt)
+	<-stop
+	_ = server.Close()
+}
+
+func directory() []ldaptest.Entry {
+	return []ldaptest.Entry{
+		{
+			DN: "cn=helpdesk,ou=services," + baseDN,
+			Attributes: map[string][]string{
+				"objectClass":  {"applicationProcess", "simpleSecurityObject"},
+				"cn":           {"helpdesk"},
+				"userPassword": {"helpdesk"},
+			},
+		},
+		{
+			DN: "cn=helpdesk-admin,ou=groups," + baseDN,
+			Attributes: map[string][]string{
+				"objectClass": {"groupOfNames"},
+				"cn":          {"helpdesk-admin"},
+				"member":      {"uid=tendik01,ou=people," + baseDN},
+			},
+		},
+		person("mhs01", "Mahasiswa LDAP", "student", "3f1c2a90-0001-4c1e-9a51-6d1f4b8e0001"),
+		person("dosen01", "Dosen LDAP", "faculty", "3f1c2a90-0002-4c1e-9a51-6d1f4b8e0002"),
+		person("tendik01", "Tendik LDAP", "staff", "3f1c2a90-0003-4c1e-9a51-6d1f4b8e0003",
+			"cn=helpdesk-admin,ou=groups,"+baseDN),
+	}
+}
+
+func person(uid string, name string, employeeType string, entryUUID string, groups ...string) ldaptest.Entry {
+	attributes := map[string][]string{
+		"objectClass":  {"inetOrgPerson"},
+		"uid":          {uid},
+		"cn":           {name},
+		"mail":         {uid + "@unila.local"},
+		"employeeType": {employeeType},
+		"entryUUID":    {entryUUID},
+		"userPassword": {"password"},
+	}
+	if len(groups) > 0 {
+		attributes["memberOf"] = groups
+	}
+	return ldaptest.Entry{DN: "uid=" + uid + ",ou=people," + baseDN, Attributes: attributes}
+}
@@ -0,0 +1,41 @@
+// Command mockoidc menjalankan provider OpenID Connect palsu (internal/oidc/oidctest)
+// untuk menguji login SSO secara lokal. Halaman /authorize menampilkan form identitas
+// (email, nama, entity, groups) lalu mengembalikan authorization code ke redirect_uri.
+// PKCE S256 wajib.
+//
+//	go run ./cmd/mockoidc
+//	OIDC_ISSUER=http://localhost:9098 OIDC_CLIENT_ID=helpdesk OIDC_REDIRECT_URL=http://localhost:3000/sso/callback
+package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"unila_helpdesk_backend/internal/oidc/oidctest"
+)
+
+func main() {
+	addr := envOr("MOCKOIDC_ADDR", ":9098")
+	config := oidctest.Config{
+		Issuer:       envOr("MOCKOIDC_ISSUER", "http://localhost"+addr),
+		ClientID:     envOr("MOCKOIDC_CLIENT_ID", "helpdesk"),
+		ClientSecret: os.Getenv("MOCKOIDC_CLIENT_SECRET"),
+	}
+	handler, err := oidctest.NewHandler(config)
+	if err != nil {
+		log.Fatalf("mock oidc failed: %v", err)
+	}
+	log.Printf("mock oidc issuer %s listening on %s (client_id=%s)", config.Issuer, addr, config.ClientID)
+	if err := http.ListenAndServe(addr, handler); err != nil {
+		log.Fatalf("mock oidc failed: %v", err)
+	}
+}
+
+func envOr(key string, fallback string) string {
+	if value := strings.TrimSpace(os.Getenv(key)); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,18 @@
+// Command vapidkeys mencetak pasangan kunci VAPID baru untuk VAPID_PUBLIC_KEY dan
+// VAPID_PRIVATE_KEY.
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"unila_helpdesk_backend/internal/webpush"
+)
+
+func main() {
+	publicKey, privateKey, err := webpush.GenerateVAPIDKeys()
+	if err != nil {
+		log.Fatalf("generate VAPID keys failed: %v", err)
+	}
+	fmt.Printf("VAPID_PUBLIC_KEY=%s\nVAPID_PRIVATE_KEY=%s\n", publicKey, privateKey)
+}
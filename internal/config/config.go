@@ -14,18 +14,60 @@ type Config struct {
 	BaseURL               string
 	TicketInitialStatus   string
 	JWTSecret             string
+	JWTKeyDir             string
+	JWTActiveKeyID        string
+	JWTIssuer             string
 	JWTExpiry             time.Duration
 	JWTExpiryUser         time.Duration
 	JWTExpiryAdmin        time.Duration
 	JWTRefreshExpiry      time.Duration
 	JWTRefreshExpiryUser  time.Duration
 	JWTRefreshExpiryAdmin time.Duration
+	// JWTRefreshReuseGrace adalah jeda setelah rotasi ketika refresh token lama
+	// masih diterima, agar refresh bersamaan dari satu perangkat tidak mencabut sesi.
+	JWTRefreshReuseGrace  time.Duration
 	DatabaseURL           string
 	DatabaseMaxConns      int
 	DatabaseIdleConns     int
 	CORSOrigins           string
 	FCMEnabled            bool
 	FCMCredentials        string
+	PushBackend           string
+	PushFakeURL           string
+	VAPIDPublicKey        string
+	VAPIDPrivateKey       string
+	VAPIDSubject          string
+	SLAWarningWindow      time.Duration
+	SLAWatchInterval      time.Duration
+	AuthCleanupInterval   time.Duration
+	SessionRetention      time.Duration
+	PushLogRetention      time.Duration
+	OIDCIssuer            string
+	OIDCClientID          string
+	OIDCClientSecret      string
+	OIDCRedirectURL       string
+	OIDCScopes            string
+	OIDCGroupsClaim       string
+	OIDCAdminGroups       string
+	OIDCEntityClaim       string
+	OIDCEntityMap         string
+	AuthBackends          string
+	LDAPURL               string
+	LDAPStartTLS          bool
+	LDAPInsecureSkipTLS   bool
+	LDAPBindDN            string
+	LDAPBindPassword      string
+	LDAPBaseDN            string
+	LDAPUserFilter        string
+	LDAPIDAttribute       string
+	LDAPUsernameAttribute string
+	LDAPEmailAttribute    string
+	LDAPNameAttribute     string
+	LDAPEntityAttribute   string
+	LDAPGroupAttribute    string
+	LDAPAdminGroups       string
+	LDAPEntityMap         string
+	LDAPTimeout           time.Duration
 }
 
 func Load() Config {
@@ -35,6 +77,12 @@ func Load() Config {
 	jwtRefreshExpiry := envDuration("JWT_REFRESH_EXPIRY", 0)
 	jwtRefreshExpiryUser := envDuration("JWT_REFRESH_EXPIRY_USER", jwtRefreshExpiry)
 	jwtRefreshExpiryAdmin := envDuration("JWT_REFRESH_EXPIRY_ADMIN", jwtRefreshExpiry)
+	fcmEnabled := envBool("FCM_ENABLED", false)
+	// PUSH_BACKEND kosong mempertahankan perilaku lama berbasis FCM_ENABLED.
+	pushBackend := "disabled"
+	if fcmEnabled {
+		pushBackend = "fcm"
+	}
 	return Config{
 		AppName:               envString("APP_NAME", ""),
 		Environment:           envString("APP_ENV", ""),
@@ -42,18 +90,58 @@ func Load() Config {
 		BaseURL:               envString("BASE_URL", ""),
 		TicketInitialStatus:   envString("TICKET_INITIAL_STATUS", "resolved"),
 		JWTSecret:             envString("JWT_SECRET", ""),
+		JWTKeyDir:             envString("JWT_KEY_DIR", ""),
+		JWTActiveKeyID:        envString("JWT_ACTIVE_KID", ""),
+		JWTIssuer:             envString("JWT_ISSUER", ""),
 		JWTExpiry:             jwtExpiry,
 		JWTExpiryUser:         jwtExpiryUser,
 		JWTExpiryAdmin:        jwtExpiryAdmin,
 		JWTRefreshExpiry:      jwtRefreshExpiry,
 		JWTRefreshExpiryUser:  jwtRefreshExpiryUser,
 		JWTRefreshExpiryAdmin: jwtRefreshExpiryAdmin,
+		JWTRefreshReuseGrace:  envDuration("JWT_REFRESH_REUSE_GRACE", 10*time.Second),
 		DatabaseURL:           envString("DATABASE_URL", ""),
 		DatabaseMaxConns:      envInt("DB_MAX_CONNS", 0),
 		DatabaseIdleConns:     envInt("DB_IDLE_CONNS", 0),
 		CORSOrigins:           envString("CORS_ORIGINS", ""),
-		FCMEnabled:            envBool("FCM_ENABLED", false),
+		FCMEnabled:            fcmEnabled,
 		FCMCredentials:        envString("FCM_CREDENTIALS", ""),
+		PushBackend:           envString("PUSH_BACKEND", pushBackend),
+		PushFakeURL:           envString("PUSH_FAKE_URL", ""),
+		VAPIDPublicKey:        envString("VAPID_PUBLIC_KEY", ""),
+		VAPIDPrivateKey:       envString("VAPID_PRIVATE_KEY", ""),
+		VAPIDSubject:          envString("VAPID_SUBJECT", ""),
+		SLAWarningWindow:      envDuration("SLA_WARNING_WINDOW", 2*time.Hour),
+		SLAWatchInterval:      envDuration("SLA_WATCH_INTERVAL", 5*time.Minute),
+		AuthCleanupInterval:   envDuration("AUTH_CLEANUP_INTERVAL", time.Hour),
+		SessionRetention:      envDuration("SESSION_RETENTION", 30*24*time.Hour),
+		PushLogRetention:      envDuration("PUSH_LOG_RETENTION", 30*24*time.Hour),
+		OIDCIssuer:            envString("OIDC_ISSUER", ""),
+		OIDCClientID:          envString("OIDC_CLIENT_ID", ""),
+		OIDCClientSecret:      envString("OIDC_CLIENT_SECRET", ""),
+		OIDCRedirectURL:       envString("OIDC_REDIRECT_URL", ""),
+		OIDCScopes:            envString("OIDC_SCOPES", "openid profile email"),
+		OIDCGroupsClaim:       envString("OIDC_GROUPS_CLAIM", "groups"),
+		OIDCAdminGroups:       envString("OIDC_ADMIN_GROUPS", ""),
+		OIDCEntityClaim:       envString("OIDC_ENTITY_CLAIM", "entity"),
+		OIDCEntityMap:         envString("OIDC_ENTITY_MAP", ""),
+		AuthBackends:          envString("AUTH_BACKENDS", "local"),
+		LDAPURL:               envString("LDAP_URL", ""),
+		LDAPStartTLS:          envBool("LDAP_START_TLS", false),
+		LDAPInsecureSkipTLS:   envBool("LDAP_INSECURE_SKIP_VERIFY", false),
+		LDAPBindDN:            envString("LDAP_BIND_DN", ""),
+		LDAPBindPassword:      envString("LDAP_BIND_PASSWORD", ""),
+		LDAPBaseDN:            envString("LDAP_BASE_DN", ""),
+		LDAPUserFilter:        envString("LDAP_USER_FILTER", "(uid=%s)"),
+		LDAPIDAttribute:       envString("LDAP_ID_ATTRIBUTE", "entryUUID"),
+		LDAPUsernameAttribute: envString("LDAP_USERNAME_ATTRIBUTE", "uid"),
+		LDAPEmailAttribute:    envString("LDAP_EMAIL_ATTRIBUTE", "mail"),
+		LDAPNameAttribute:     envString("LDAP_NAME_ATTRIBUTE", "cn"),
+		LDAPEntityAttribute:   envString("LDAP_ENTITY_ATTRIBUTE", "employeeType"),
+		LDAPGroupAttribute:    envString("LDAP_GROUP_ATTRIBUTE", "memberOf"),
+		LDAPAdminGroups:       envString("LDAP_ADMIN_GROUPS", ""),
+		LDAPEntityMap:         envString("LDAP_ENTITY_MAP", ""),
+		LDAPTimeout:           envDuration("LDAP_TIMEOUT", 5*time.Second),
 	}
 }
 
@@ -114,19 +114,64 @@ func AutoMigrate(database *gorm.DB) error {
             END IF;
         END $$;
     `).Error
+	// Articles promoted twice from the same ticket keep only the oldest link so the
+	// partial unique index on source_ticket_id can be created.
+	if err := database.Exec(`
+        DO $$
+        BEGIN
+            IF to_regclass('public.articles') IS NOT NULL THEN
+                UPDATE articles a
+                SET source_ticket_id = ''
+                WHERE a.source_ticket_id <> ''
+                  AND EXISTS (
+                      SELECT 1 FROM articles b
+                      WHERE b.source_ticket_id = a.source_ticket_id
+                        AND (b.created_at, b.id) < (a.created_at, a.id)
+                  );
+                DROP INDEX IF EXISTS idx_articles_source_ticket_id;
+            END IF;
+        END $$;
+    `).Error; err != nil {
+		return err
+	}
+	if err := database.SetupJoinTable(&domain.Ticket{}, "Tags", &domain.TicketTag{}); err != nil {
+		return err
+	}
+	if err := database.SetupJoinTable(&domain.TicketComment{}, "Articles", &domain.TicketCommentArticle{}); err != nil {
+		return err
+	}
 	if err := database.AutoMigrate(
 		&domain.User{},
+		&domain.UserIdentity{},
 		&domain.ServiceCategory{},
+		&domain.CategoryField{},
 		&domain.Ticket{},
+		&domain.Tag{},
+		&domain.TicketTag{},
+		&domain.TicketView{},
+		&domain.CannedResponse{},
+		&domain.Macro{},
+		&domain.Article{},
+		&domain.ArticleVote{},
+		&domain.ArticleDeflection{},
+		&domain.Announcement{},
+		&domain.ServiceIncident{},
 		&domain.Attachment{},
 		&domain.TicketHistory{},
 		&domain.TicketComment{},
+		&domain.TicketCommentArticle{},
 		&domain.SurveyTemplate{},
 		&domain.SurveyQuestion{},
 		&domain.SurveyResponse{},
 		&domain.Notification{},
 		&domain.FCMToken{},
+		&domain.PushLog{},
+		&domain.WebPushSubscription{},
+		&domain.WebPushPreference{},
 		&domain.RefreshToken{},
+		&domain.Session{},
+		&domain.OIDCLoginState{},
+		&domain.AuditLog{},
 	); err != nil {
 		return err
 	}
@@ -154,6 +199,19 @@ func AutoMigrate(database *gorm.DB) error {
 		return err
 	}
 
+	// Backfill SLA due dates for tickets created before due_at existed.
+	if err := database.Exec(`
+        UPDATE tickets
+        SET due_at = created_at + CASE priority
+            WHEN 'high' THEN interval '1 day'
+            WHEN 'low' THEN interval '7 days'
+            ELSE interval '3 days'
+        END
+        WHERE due_at IS NULL
+    `).Error; err != nil {
+		return err
+	}
+
 	// Keep only the newest row for each FCM token to prevent cross-account delivery
 	// on shared devices with historical duplicate mappings.
 	if err := database.Exec(`
@@ -166,6 +224,24 @@ func AutoMigrate(database *gorm.DB) error {
 		return err
 	}
 
+	// Refresh tokens issued before token families existed start their own family.
+	if err := database.Exec(`
+        UPDATE refresh_tokens
+        SET family_id = COALESCE(NULLIF(session_id, ''), id)
+        WHERE family_id IS NULL OR family_id = ''
+    `).Error; err != nil {
+		return err
+	}
+
+	// Full-text index for knowledge base search; the expression must match
+	// the one used in ArticleRepository.
+	if err := database.Exec(`
+        CREATE INDEX IF NOT EXISTS idx_articles_search ON articles
+        USING GIN (to_tsvector('simple', coalesce(title, '') || ' ' || coalesce(summary, '') || ' ' || coalesce(body, '')))
+    `).Error; err != nil {
+		return err
+	}
+
 	return nil
 }
 
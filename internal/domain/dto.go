@@ -14,17 +14,48 @@ type UserDTO struct {
 	Entity string   `json:"entity"`
 }
 
+type SessionDTO struct {
+	ID           string    `json:"id"`
+	DeviceName   string    `json:"deviceName"`
+	Platform     string    `json:"platform"`
+	ClientType   string    `json:"clientType"`
+	IPAddress    string    `json:"ipAddress"`
+	UserAgent    string    `json:"userAgent"`
+	HasPushToken bool      `json:"hasPushToken"`
+	Current      bool      `json:"current"`
+	LastUsedAt   time.Time `json:"lastUsedAt"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+type TicketFieldChangeDTO struct {
+	Field       string `json:"field"`
+	OldValue    string `json:"oldValue"`
+	NewValue    string `json:"newValue"`
+	Description string `json:"description"`
+}
+
 type TicketHistoryDTO struct {
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Timestamp   time.Time `json:"timestamp"`
+	Title       string                 `json:"title"`
+	Description string                 `json:"description"`
+	Actor       string                 `json:"actor,omitempty"`
+	Changes     []TicketFieldChangeDTO `json:"changes,omitempty"`
+	Timestamp   time.Time              `json:"timestamp"`
 }
 
 type TicketCommentDTO struct {
-	Author    string    `json:"author"`
-	Message   string    `json:"message"`
-	Timestamp time.Time `json:"timestamp"`
-	IsStaff   bool      `json:"isStaff"`
+	ID        string          `json:"id"`
+	Author    string          `json:"author"`
+	Message   string          `json:"message"`
+	Timestamp time.Time       `json:"timestamp"`
+	IsStaff   bool            `json:"isStaff"`
+	Articles  []ArticleRefDTO `json:"articles,omitempty"`
+}
+
+type ArticleRefDTO struct {
+	ID    string `json:"id"`
+	Slug  string `json:"slug"`
+	Title string `json:"title"`
 }
 
 type TicketDTO struct {
@@ -36,14 +67,21 @@ type TicketDTO struct {
 	Status         TicketStatus       `json:"status"`
 	Priority       TicketPriority     `json:"priority"`
 	CreatedAt      time.Time          `json:"createdAt"`
+	UpdatedAt      time.Time          `json:"updatedAt"`
+	DueAt          *time.Time         `json:"dueAt,omitempty"`
 	Reporter       string             `json:"reporter"`
 	IsGuest        bool               `json:"isGuest"`
 	Assignee       string             `json:"assignee,omitempty"`
 	Attachments    []string           `json:"attachments"`
+	CustomFields   map[string]any     `json:"customFields,omitempty"`
+	Tags           []string           `json:"tags"`
 	History        []TicketHistoryDTO `json:"history"`
 	Comments       []TicketCommentDTO `json:"comments"`
 	SurveyRequired bool               `json:"surveyRequired"`
 	SurveyScore    float64            `json:"surveyScore"`
+	DeletedAt      *time.Time         `json:"deletedAt,omitempty"`
+	DeletedBy      string             `json:"deletedBy,omitempty"`
+	DeleteReason   string             `json:"deleteReason,omitempty"`
 }
 
 type TicketPageDTO struct {
@@ -52,13 +90,197 @@ type TicketPageDTO struct {
 	Limit      int         `json:"limit"`
 	Total      int64       `json:"total"`
 	TotalPages int         `json:"totalPages"`
+	NextCursor string      `json:"nextCursor,omitempty"`
+	PrevCursor string      `json:"prevCursor,omitempty"`
+}
+
+type BulkTicketItemResultDTO struct {
+	TicketID string `json:"ticketId"`
+	Success  bool   `json:"success"`
+	Error    string `json:"error,omitempty"`
+}
+
+type BulkTicketResultDTO struct {
+	Action    string                    `json:"action"`
+	Total     int                       `json:"total"`
+	Succeeded int                       `json:"succeeded"`
+	Failed    int                       `json:"failed"`
+	Results   []BulkTicketItemResultDTO `json:"results"`
+}
+
+type AuditLogDTO struct {
+	ID         int64          `json:"id"`
+	ActorID    string         `json:"actorId,omitempty"`
+	ActorName  string         `json:"actorName,omitempty"`
+	ActorRole  UserRole       `json:"actorRole,omitempty"`
+	Action     string         `json:"action"`
+	EntityType string         `json:"entityType"`
+	EntityID   string         `json:"entityId,omitempty"`
+	Before     datatypes.JSON `json:"before,omitempty"`
+	After      datatypes.JSON `json:"after,omitempty"`
+	Changes    datatypes.JSON `json:"changes,omitempty"`
+	IP         string         `json:"ip,omitempty"`
+	UserAgent  string         `json:"userAgent,omitempty"`
+	Hash       string         `json:"hash"`
+	CreatedAt  time.Time      `json:"createdAt"`
+}
+
+type AuditLogPageDTO struct {
+	Items      []AuditLogDTO `json:"items"`
+	Page       int           `json:"page"`
+	Limit      int           `json:"limit"`
+	Total      int64         `json:"total"`
+	TotalPages int           `json:"totalPages"`
+}
+
+type AuditVerifyDTO struct {
+	Valid    bool  `json:"valid"`
+	Checked  int64 `json:"checked"`
+	BrokenAt int64 `json:"brokenAt,omitempty"`
+}
+
+type TicketViewDTO struct {
+	ID        string         `json:"id"`
+	Name      string         `json:"name"`
+	OwnerID   string         `json:"ownerId"`
+	Shared    bool           `json:"shared"`
+	Filter    datatypes.JSON `json:"filter"`
+	Sort      datatypes.JSON `json:"sort"`
+	Count     int64          `json:"count"`
+	CreatedAt time.Time      `json:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt"`
 }
 
 type ServiceCategoryDTO struct {
-	ID           string `json:"id"`
-	Name         string `json:"name"`
-	GuestAllowed bool   `json:"guestAllowed"`
-	TemplateID   string `json:"templateId,omitempty"`
+	ID                  string             `json:"id"`
+	ParentID            string             `json:"parentId,omitempty"`
+	Name                string             `json:"name"`
+	Path                string             `json:"path"`
+	IsLeaf              bool               `json:"isLeaf"`
+	Description         string             `json:"description,omitempty"`
+	Icon                string             `json:"icon,omitempty"`
+	GuestAllowed        bool               `json:"guestAllowed"`
+	TemplateID          string             `json:"templateId,omitempty"`
+	EffectiveTemplateID string             `json:"effectiveTemplateId,omitempty"`
+	SortOrder           int                `json:"sortOrder"`
+	Archived            bool               `json:"archived"`
+	ArchivedAt          *time.Time         `json:"archivedAt,omitempty"`
+	Fields              []CategoryFieldDTO `json:"fields"`
+}
+
+type TagDTO struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Color       string `json:"color,omitempty"`
+	Description string `json:"description,omitempty"`
+	Usage       int64  `json:"usage"`
+}
+
+type CannedResponseDTO struct {
+	ID         string    `json:"id"`
+	Title      string    `json:"title"`
+	Body       string    `json:"body"`
+	CategoryID string    `json:"categoryId,omitempty"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+type CannedResponseGroupDTO struct {
+	CategoryID string              `json:"categoryId"`
+	Category   string              `json:"category"`
+	Items      []CannedResponseDTO `json:"items"`
+}
+
+type CannedResponsePreviewDTO struct {
+	ID       string `json:"id"`
+	TicketID string `json:"ticketId"`
+	Body     string `json:"body"`
+}
+
+type MacroDTO struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	CategoryID       string       `json:"categoryId,omitempty"`
+	CannedResponseID string       `json:"cannedResponseId,omitempty"`
+	CannedResponse   string       `json:"cannedResponse,omitempty"`
+	Status           TicketStatus `json:"status,omitempty"`
+	Assignee         string       `json:"assignee,omitempty"`
+}
+
+type ArticleDTO struct {
+	ID              string        `json:"id"`
+	Slug            string        `json:"slug"`
+	Title           string        `json:"title"`
+	Summary         string        `json:"summary"`
+	Body            string        `json:"body,omitempty"`
+	CategoryID      string        `json:"categoryId,omitempty"`
+	Category        string        `json:"category,omitempty"`
+	Status          ArticleStatus `json:"status"`
+	Author          string        `json:"author,omitempty"`
+	ViewCount       int64         `json:"viewCount"`
+	HelpfulCount    int64         `json:"helpfulCount"`
+	NotHelpfulCount int64         `json:"notHelpfulCount"`
+	PublishedAt     *time.Time    `json:"publishedAt,omitempty"`
+	SourceTicketID  string        `json:"sourceTicketId,omitempty"`
+	UpdatedAt       time.Time     `json:"updatedAt"`
+}
+
+type ArticlePageDTO struct {
+	Items      []ArticleDTO `json:"items"`
+	Page       int          `json:"page"`
+	Limit      int          `json:"limit"`
+	Total      int64        `json:"total"`
+	TotalPages int          `json:"totalPages"`
+}
+
+type KBDeflectionRowDTO struct {
+	CategoryID  string  `json:"categoryId"`
+	Category    string  `json:"category"`
+	Deflections int     `json:"deflections"`
+	Tickets     int     `json:"tickets"`
+	Rate        float64 `json:"rate"`
+}
+
+type KBArticleStatDTO struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Deflections int    `json:"deflections"`
+	Views       int64  `json:"views"`
+	Helpful     int64  `json:"helpful"`
+	NotHelpful  int64  `json:"notHelpful"`
+}
+
+type KBArticleUsageDTO struct {
+	ID    string `json:"id"`
+	Slug  string `json:"slug"`
+	Title string `json:"title"`
+	Uses  int    `json:"uses"`
+}
+
+type KBCategoryUsageDTO struct {
+	CategoryID string              `json:"categoryId"`
+	Category   string              `json:"category"`
+	Tickets    int                 `json:"tickets"`
+	Articles   []KBArticleUsageDTO `json:"articles"`
+}
+
+type KBDeflectionReportDTO struct {
+	Period      string               `json:"period"`
+	Start       time.Time            `json:"start"`
+	End         time.Time            `json:"end"`
+	Deflections int                  `json:"deflections"`
+	Tickets     int                  `json:"tickets"`
+	Rate        float64              `json:"rate"`
+	Rows        []KBDeflectionRowDTO `json:"rows"`
+	TopArticles []KBArticleStatDTO   `json:"topArticles"`
+}
+
+type CategoryFieldDTO struct {
+	ID       string            `json:"id"`
+	Key      string            `json:"key"`
+	Label    string            `json:"label"`
+	Type     CategoryFieldType `json:"type"`
+	Required bool              `json:"required"`
+	Options  []string          `json:"options,omitempty"`
 }
 
 type SurveyQuestionDTO struct {
@@ -79,6 +301,83 @@ type SurveyTemplateDTO struct {
 	UpdatedAt   time.Time           `json:"updatedAt"`
 }
 
+type AnnouncementDTO struct {
+	ID                string               `json:"id"`
+	Title             string               `json:"title"`
+	Body              string               `json:"body"`
+	Severity          AnnouncementSeverity `json:"severity"`
+	CategoryIDs       []string             `json:"categoryIds"`
+	Categories        []string             `json:"categories"`
+	StartsAt          time.Time            `json:"startsAt"`
+	EndsAt            *time.Time           `json:"endsAt,omitempty"`
+	Active            bool                 `json:"active"`
+	CreatedBy         string               `json:"createdBy,omitempty"`
+	BroadcastAt       *time.Time           `json:"broadcastAt,omitempty"`
+	BroadcastAudience string               `json:"broadcastAudience,omitempty"`
+	CreatedAt         time.Time            `json:"createdAt"`
+	UpdatedAt         time.Time            `json:"updatedAt"`
+}
+
+type ServiceDayDTO struct {
+	Date            string       `json:"date"`
+	State           ServiceState `json:"state"`
+	DowntimeMinutes int          `json:"downtimeMinutes"`
+	DegradedMinutes int          `json:"degradedMinutes"`
+}
+
+type ServiceStatusDTO struct {
+	CategoryID    string             `json:"categoryId"`
+	Name          string             `json:"name"`
+	State         ServiceState       `json:"state"`
+	Since         *time.Time         `json:"since,omitempty"`
+	Message       string             `json:"message,omitempty"`
+	UptimePercent float64            `json:"uptimePercent"`
+	History       []ServiceDayDTO    `json:"history,omitempty"`
+	Components    []ServiceStatusDTO `json:"components,omitempty"`
+}
+
+type StatusPageDTO struct {
+	State         ServiceState       `json:"state"`
+	UpdatedAt     time.Time          `json:"updatedAt"`
+	Services      []ServiceStatusDTO `json:"services"`
+	Announcements []AnnouncementDTO  `json:"announcements"`
+}
+
+type PushLogDTO struct {
+	ID        string    `json:"id"`
+	Backend   string    `json:"backend"`
+	UserID    string    `json:"userId,omitempty"`
+	TokenHint string    `json:"tokenHint,omitempty"`
+	Topic     string    `json:"topic,omitempty"`
+	Title     string    `json:"title"`
+	Status    string    `json:"status"`
+	MessageID string    `json:"messageId,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type PushLogPageDTO struct {
+	Items      []PushLogDTO `json:"items"`
+	Page       int          `json:"page"`
+	Limit      int          `json:"limit"`
+	Total      int64        `json:"total"`
+	TotalPages int          `json:"totalPages"`
+}
+
+type WebPushPreferenceDTO struct {
+	CategoryIDs []string `json:"categoryIds"`
+	NewTickets  bool     `json:"newTickets"`
+	SLAWarnings bool     `json:"slaWarnings"`
+}
+
+type WebPushSubscriptionDTO struct {
+	ID         string     `json:"id"`
+	Endpoint   string     `json:"endpoint"`
+	UserAgent  string     `json:"userAgent"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
 type NotificationDTO struct {
 	ID        string    `json:"id"`
 	TicketID  string    `json:"ticketId,omitempty"`
@@ -97,6 +396,8 @@ type CohortRowDTO struct {
 }
 
 type ServiceTrendDTO struct {
+	CategoryID string  `json:"categoryId,omitempty"`
+	TagID      string  `json:"tagId,omitempty"`
 	Label      string  `json:"label"`
 	Percentage float64 `json:"percentage"`
 }
@@ -189,6 +490,8 @@ type SurveyResponsePageDTO struct {
 	Limit      int                     `json:"limit"`
 	Total      int64                   `json:"total"`
 	TotalPages int                     `json:"totalPages"`
+	NextCursor string                  `json:"nextCursor,omitempty"`
+	PrevCursor string                  `json:"prevCursor,omitempty"`
 }
 
 type EntityServiceDTO struct {
@@ -14,6 +14,10 @@ type TicketPriority string
 type TicketStatus string
 
 type SurveyQuestionType string
+type CategoryFieldType string
+type ArticleStatus string
+type AnnouncementSeverity string
+type ServiceState string
 
 const (
 	RoleRegistered UserRole = "registered"
@@ -46,6 +50,43 @@ const (
 	QuestionText           SurveyQuestionType = "text"
 )
 
+const (
+	ArticleDraft     ArticleStatus = "draft"
+	ArticlePublished ArticleStatus = "published"
+)
+
+const (
+	SeverityInfo     AnnouncementSeverity = "info"
+	SeverityWarning  AnnouncementSeverity = "warning"
+	SeverityCritical AnnouncementSeverity = "critical"
+)
+
+const (
+	StateOperational ServiceState = "operational"
+	StateDegraded    ServiceState = "degraded"
+	StateDown        ServiceState = "down"
+)
+
+const (
+	FieldText    CategoryFieldType = "text"
+	FieldNumber  CategoryFieldType = "number"
+	FieldSelect  CategoryFieldType = "select"
+	FieldDate    CategoryFieldType = "date"
+	FieldBoolean CategoryFieldType = "boolean"
+)
+
+// SLATarget mengembalikan batas waktu penyelesaian tiket berdasarkan prioritas.
+func SLATarget(priority TicketPriority) time.Duration {
+	switch priority {
+	case PriorityHigh:
+		return 24 * time.Hour
+	case PriorityLow:
+		return 7 * 24 * time.Hour
+	default:
+		return 3 * 24 * time.Hour
+	}
+}
+
 type User struct {
 	ID           string   `gorm:"primaryKey;type:varchar(36)"`
 	Username     string   `gorm:"size:60;uniqueIndex"`
@@ -55,18 +96,53 @@ type User struct {
 	Role         UserRole `gorm:"size:20"`
 	Entity       string   `gorm:"size:120"`
 	IsActive     bool     `gorm:"default:true"`
+	// TokenVersion dinaikkan saat logout paksa atau akun dinonaktifkan sehingga
+	// access token yang sudah terbit langsung ditolak.
+	TokenVersion int `gorm:"default:0"`
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
 	DeletedAt    gorm.DeletedAt `gorm:"index"`
 }
 
+// UserIdentity menautkan akun dengan identitas di penyedia eksternal, mis.
+// "oidc" + sub dari SSO kampus atau "ldap" + entryUUID. Satu akun boleh memiliki
+// satu identitas per provider.
+type UserIdentity struct {
+	ID        string `gorm:"primaryKey;type:varchar(36)"`
+	UserID    string `gorm:"type:varchar(36);uniqueIndex:idx_user_identities_user_provider"`
+	Provider  string `gorm:"size:20;uniqueIndex:idx_user_identities_subject;uniqueIndex:idx_user_identities_user_provider"`
+	Subject   string `gorm:"size:255;uniqueIndex:idx_user_identities_subject"`
+	CreatedAt time.Time
+}
+
 type ServiceCategory struct {
 	ID               string `gorm:"primaryKey;size:60"`
+	ParentID         string `gorm:"size:60;index"`
 	Name             string `gorm:"size:120"`
+	Description      string `gorm:"type:text"`
+	Icon             string `gorm:"size:60"`
 	GuestAllowed     bool
-	SurveyTemplateID string `gorm:"size:64"`
+	SurveyTemplateID string     `gorm:"size:64"`
+	SortOrder        int        `gorm:"default:0;index"`
+	ArchivedAt       *time.Time `gorm:"index"`
 	CreatedAt        time.Time
 	UpdatedAt        time.Time
+
+	Fields []CategoryField `gorm:"foreignKey:CategoryID"`
+}
+
+// CategoryField mendefinisikan data tambahan yang diisi pelapor untuk kategori tertentu.
+type CategoryField struct {
+	ID         string            `gorm:"primaryKey;type:varchar(36)"`
+	CategoryID string            `gorm:"size:60;uniqueIndex:idx_category_field_key"`
+	Key        string            `gorm:"size:40;uniqueIndex:idx_category_field_key"`
+	Label      string            `gorm:"size:120"`
+	Type       CategoryFieldType `gorm:"size:20"`
+	Required   bool              `gorm:"default:false"`
+	Options    datatypes.JSON    `gorm:"type:jsonb"`
+	SortOrder  int               `gorm:"default:0"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
 }
 
 type Ticket struct {
@@ -82,24 +158,175 @@ type Ticket struct {
 	Assignee       string         `gorm:"size:120"`
 	SurveyRequired bool           `gorm:"default:false"`
 	Attachments    datatypes.JSON `gorm:"type:jsonb"`
+	CustomFields   datatypes.JSON `gorm:"type:jsonb"`
+	DueAt          *time.Time     `gorm:"index"`
+	SLAWarnedAt    *time.Time
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
 	DeletedAt      gorm.DeletedAt `gorm:"index"`
+	DeletedBy      string         `gorm:"size:36"`
+	DeletedByName  string         `gorm:"size:120"`
+	DeleteReason   string         `gorm:"type:text"`
 
 	Category ServiceCategory `gorm:"foreignKey:CategoryID"`
 	History  []TicketHistory `gorm:"foreignKey:TicketID"`
 	Comments []TicketComment `gorm:"foreignKey:TicketID"`
+	Tags     []Tag           `gorm:"many2many:ticket_tags"`
+}
+
+// Tag adalah label lintas kategori (mis. "vpn", "keluhan-berulang") yang kosakatanya dikelola admin.
+type Tag struct {
+	ID          string `gorm:"primaryKey;type:varchar(36)"`
+	Name        string `gorm:"size:40;uniqueIndex"`
+	Color       string `gorm:"size:20"`
+	Description string `gorm:"type:text"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// TicketTag adalah tabel relasi ticket_tags antara tiket dan tag.
+type TicketTag struct {
+	TicketID  string `gorm:"primaryKey;size:64"`
+	TagID     string `gorm:"primaryKey;type:varchar(36);index"`
+	CreatedBy string `gorm:"size:36"`
+	CreatedAt time.Time
+}
+
+// CannedResponse adalah template balasan staf. CategoryID kosong berarti berlaku umum.
+type CannedResponse struct {
+	ID         string `gorm:"primaryKey;type:varchar(36)"`
+	Title      string `gorm:"size:160"`
+	Body       string `gorm:"type:text"`
+	CategoryID string `gorm:"size:60;index"`
+	CreatedBy  string `gorm:"size:36"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// Macro menggabungkan balasan template dengan perubahan status dan petugas dalam satu aksi.
+type Macro struct {
+	ID               string       `gorm:"primaryKey;type:varchar(36)"`
+	Name             string       `gorm:"size:160"`
+	CategoryID       string       `gorm:"size:60;index"`
+	CannedResponseID string       `gorm:"size:36;index"`
+	Status           TicketStatus `gorm:"size:20"`
+	Assignee         string       `gorm:"size:120"`
+	CreatedBy        string       `gorm:"size:36"`
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+// Article adalah artikel knowledge base. Hanya artikel published yang tampil publik.
+type Article struct {
+	ID              string        `gorm:"primaryKey;type:varchar(36)"`
+	Slug            string        `gorm:"size:80;uniqueIndex"`
+	Title           string        `gorm:"size:200"`
+	Summary         string        `gorm:"type:text"`
+	Body            string        `gorm:"type:text"`
+	CategoryID      string        `gorm:"size:60;index"`
+	Status          ArticleStatus `gorm:"size:20;index"`
+	AuthorID        string        `gorm:"size:36"`
+	AuthorName      string        `gorm:"size:120"`
+	ViewCount       int64         `gorm:"default:0"`
+	HelpfulCount    int64         `gorm:"default:0"`
+	NotHelpfulCount int64         `gorm:"default:0"`
+	PublishedAt     *time.Time    `gorm:"index"`
+	// SourceTicketID diisi jika artikel dibuat dari penyelesaian tiket; satu tiket
+	// hanya boleh menjadi sumber satu artikel.
+	SourceTicketID string `gorm:"size:64;uniqueIndex:idx_articles_source_ticket,where:source_ticket_id <> ''"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// ArticleVote menyimpan satu suara "apakah membantu" per pengunjung agar penghitung
+// tidak bisa dinaikkan berulang kali.
+type ArticleVote struct {
+	ArticleID string `gorm:"primaryKey;type:varchar(36)"`
+	VoterKey  string `gorm:"primaryKey;size:64"`
+	Helpful   bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// ArticleDeflection dicatat saat pengunjung menyatakan masalahnya selesai lewat artikel
+// yang disarankan sehingga tidak jadi membuat tiket.
+type ArticleDeflection struct {
+	ID         string    `gorm:"primaryKey;type:varchar(36)"`
+	ArticleID  string    `gorm:"type:varchar(36);index"`
+	CategoryID string    `gorm:"size:60;index"`
+	Query      string    `gorm:"size:200"`
+	Source     string    `gorm:"size:40"`
+	CreatedAt  time.Time `gorm:"index"`
+}
+
+// Announcement adalah pengumuman layanan (mis. maintenance) yang tampil publik selama
+// rentang StartsAt–EndsAt. CategoryIDs kosong berarti berlaku untuk semua layanan.
+type Announcement struct {
+	ID                string               `gorm:"primaryKey;type:varchar(36)"`
+	Title             string               `gorm:"size:160"`
+	Body              string               `gorm:"type:text"`
+	Severity          AnnouncementSeverity `gorm:"size:20;index"`
+	CategoryIDs       datatypes.JSON       `gorm:"type:jsonb"`
+	StartsAt          time.Time            `gorm:"index"`
+	EndsAt            *time.Time           `gorm:"index"`
+	CreatedBy         string               `gorm:"size:36"`
+	CreatedByName     string               `gorm:"size:120"`
+	BroadcastAt       *time.Time
+	BroadcastAudience string `gorm:"size:20"`
+	BroadcastTopic    string `gorm:"size:120"`
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// ServiceIncident mencatat periode layanan (kategori) tidak beroperasi normal.
+// Insiden tanpa ResolvedAt menentukan status layanan saat ini.
+type ServiceIncident struct {
+	ID             string       `gorm:"primaryKey;type:varchar(36)"`
+	CategoryID     string       `gorm:"size:60;index"`
+	State          ServiceState `gorm:"size:20"`
+	Message        string       `gorm:"type:text"`
+	AnnouncementID string       `gorm:"size:36"`
+	StartedAt      time.Time    `gorm:"index"`
+	ResolvedAt     *time.Time   `gorm:"index"`
+	CreatedBy      string       `gorm:"size:36"`
+	ResolvedBy     string       `gorm:"size:36"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+type TicketView struct {
+	ID        string         `gorm:"primaryKey;type:varchar(36)"`
+	OwnerID   string         `gorm:"size:36;index"`
+	Name      string         `gorm:"size:120"`
+	Filter    datatypes.JSON `gorm:"type:jsonb"`
+	Sort      datatypes.JSON `gorm:"type:jsonb"`
+	Shared    bool           `gorm:"default:false;index"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
 }
 
 type TicketHistory struct {
-	ID          string    `gorm:"primaryKey;type:varchar(36)"`
-	TicketID    string    `gorm:"size:64;index"`
-	Title       string    `gorm:"size:160"`
-	Description string    `gorm:"type:text"`
-	Timestamp   time.Time `gorm:"index"`
+	ID          string         `gorm:"primaryKey;type:varchar(36)"`
+	TicketID    string         `gorm:"size:64;index"`
+	ActorID     string         `gorm:"size:36;index"`
+	ActorName   string         `gorm:"size:120"`
+	Title       string         `gorm:"size:160"`
+	Description string         `gorm:"type:text"`
+	Changes     datatypes.JSON `gorm:"type:jsonb"`
+	Timestamp   time.Time      `gorm:"index"`
 	CreatedAt   time.Time
 }
 
+// TicketFieldChange disimpan sebagai JSON di TicketHistory.Changes. Label berisi
+// nilai yang mudah dibaca (mis. nama kategori) saat perubahan terjadi.
+type TicketFieldChange struct {
+	Field    string `json:"field"`
+	OldValue string `json:"old"`
+	NewValue string `json:"new"`
+	OldLabel string `json:"oldLabel,omitempty"`
+	NewLabel string `json:"newLabel,omitempty"`
+}
+
 type TicketComment struct {
 	ID        string `gorm:"primaryKey;type:varchar(36)"`
 	TicketID  string `gorm:"size:64;index"`
@@ -108,6 +335,15 @@ type TicketComment struct {
 	IsStaff   bool
 	Timestamp time.Time `gorm:"index"`
 	CreatedAt time.Time
+	Articles  []Article `gorm:"many2many:ticket_comment_articles;joinForeignKey:CommentID;joinReferences:ArticleID"`
+}
+
+// TicketCommentArticle menautkan artikel knowledge base ke komentar penyelesaian tiket.
+type TicketCommentArticle struct {
+	CommentID string    `gorm:"primaryKey;type:varchar(36)"`
+	ArticleID string    `gorm:"primaryKey;type:varchar(36);index"`
+	LinkedBy  string    `gorm:"size:36"`
+	CreatedAt time.Time `gorm:"index"`
 }
 
 type Attachment struct {
@@ -161,18 +397,118 @@ type Notification struct {
 }
 
 type FCMToken struct {
-	ID        string `gorm:"primaryKey;type:varchar(36)"`
-	UserID    string `gorm:"size:36;index"`
-	Token     string `gorm:"type:text"`
-	Platform  string `gorm:"size:40"`
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID       string `gorm:"primaryKey;type:varchar(36)"`
+	UserID   string `gorm:"size:36;index"`
+	Token    string `gorm:"type:text"`
+	Platform string `gorm:"size:40"`
+	// TopicSubscribedAt terisi setelah token terdaftar di topic pengumuman; token
+	// yang masih nil didaftarkan ulang saat API start.
+	TopicSubscribedAt *time.Time `gorm:"index"`
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// WebPushSubscription adalah PushSubscription browser (Web Push/VAPID) milik admin
+// yang memakai web console. Endpoint unik per browser.
+type WebPushSubscription struct {
+	ID         string `gorm:"primaryKey;type:varchar(36)"`
+	UserID     string `gorm:"size:36;index"`
+	Endpoint   string `gorm:"type:text;uniqueIndex"`
+	P256dh     string `gorm:"size:120"`
+	Auth       string `gorm:"size:40"`
+	UserAgent  string `gorm:"size:255"`
+	LastUsedAt *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// WebPushPreference menyimpan kategori yang dipantau admin. Kategori mencakup
+// turunannya; daftar kosong berarti semua kategori.
+type WebPushPreference struct {
+	UserID      string         `gorm:"primaryKey;size:36"`
+	CategoryIDs datatypes.JSON `gorm:"type:jsonb"`
+	NewTickets  bool           `gorm:"default:true"`
+	SLAWarnings bool           `gorm:"default:true"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// PushLog mencatat hasil setiap pengiriman push per token atau topic untuk
+// penelusuran keluhan "notifikasi tidak masuk". Token hanya disimpan sebagai hint.
+type PushLog struct {
+	ID        string    `gorm:"primaryKey;type:varchar(36)"`
+	Backend   string    `gorm:"size:20"`
+	UserID    string    `gorm:"size:36;index"`
+	TokenHint string    `gorm:"size:24"`
+	Topic     string    `gorm:"size:120;index"`
+	Title     string    `gorm:"size:160"`
+	Status    string    `gorm:"size:20;index"`
+	MessageID string    `gorm:"size:200"`
+	Error     string    `gorm:"type:text"`
+	CreatedAt time.Time `gorm:"index"`
 }
 
+// RefreshToken dirotasi setiap refresh. Token lama disimpan dengan UsedAt terisi;
+// jika token bekas dipakai lagi, seluruh FamilyID dicabut karena token bocor.
 type RefreshToken struct {
 	ID        string    `gorm:"primaryKey;type:varchar(36)"`
 	UserID    string    `gorm:"size:36;index"`
+	SessionID string    `gorm:"size:36;index"`
+	FamilyID  string    `gorm:"size:36;index"`
 	TokenHash string    `gorm:"size:64;uniqueIndex"`
 	ExpiresAt time.Time `gorm:"index"`
+	UsedAt    *time.Time
+	RevokedAt *time.Time
 	CreatedAt time.Time
 }
+
+// OIDCLoginState menyimpan state, nonce, dan PKCE verifier selama login SSO
+// berlangsung. Baris dihapus setelah callback atau kadaluarsa.
+type OIDCLoginState struct {
+	State        string    `gorm:"primaryKey;size:64"`
+	Nonce        string    `gorm:"size:64"`
+	CodeVerifier string    `gorm:"size:128"`
+	ClientType   string    `gorm:"size:20"`
+	DeviceName   string    `gorm:"size:120"`
+	Platform     string    `gorm:"size:40"`
+	ExpiresAt    time.Time `gorm:"index"`
+	CreatedAt    time.Time
+}
+
+// Session adalah satu perangkat yang login. Refresh token hasil rotasi tetap
+// memakai SessionID yang sama; FCMToken diisi saat aplikasi mendaftarkan push token.
+type Session struct {
+	ID         string `gorm:"primaryKey;type:varchar(36)"`
+	UserID     string `gorm:"size:36;index"`
+	DeviceName string `gorm:"size:120"`
+	Platform   string `gorm:"size:40"`
+	ClientType string `gorm:"size:20"`
+	IPAddress  string `gorm:"size:64"`
+	UserAgent  string `gorm:"size:255"`
+	FCMToken   string `gorm:"type:text;index"`
+	LastUsedAt time.Time
+	ExpiresAt  time.Time  `gorm:"index"`
+	RevokedAt  *time.Time `gorm:"index"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// AuditLog adalah catatan append-only. Hash dihitung dari PrevHash dan isi baris
+// sehingga perubahan pada baris lama memutus rantai dan dapat dideteksi.
+type AuditLog struct {
+	ID         int64          `gorm:"primaryKey;autoIncrement"`
+	ActorID    string         `gorm:"size:36;index"`
+	ActorName  string         `gorm:"size:120"`
+	ActorRole  UserRole       `gorm:"size:20"`
+	Action     string         `gorm:"size:80;index"`
+	EntityType string         `gorm:"size:60;index:idx_audit_entity"`
+	EntityID   string         `gorm:"size:64;index:idx_audit_entity"`
+	Before     datatypes.JSON `gorm:"type:jsonb"`
+	After      datatypes.JSON `gorm:"type:jsonb"`
+	Changes    datatypes.JSON `gorm:"type:jsonb"`
+	IP         string         `gorm:"size:64"`
+	UserAgent  string         `gorm:"type:text"`
+	PrevHash   string         `gorm:"size:64"`
+	Hash       string         `gorm:"size:64;uniqueIndex"`
+	CreatedAt  time.Time      `gorm:"index"`
+}
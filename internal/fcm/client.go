@@ -4,110 +4,315 @@ import (
 	"context"
 	"log"
 	"strings"
+)
+
+// TopicAnnouncements adalah topic global yang diikuti setiap token terdaftar.
+const TopicAnnouncements = "announcements"
 
-	firebase "firebase.google.com/go/v4"
-	"firebase.google.com/go/v4/messaging"
-	"google.golang.org/api/option"
+const (
+	// multicastBatchSize adalah batas token per panggilan SendEachForMulticast.
+	multicastBatchSize = 500
+	// topicBatchSize adalah batas token per panggilan subscribe/unsubscribe FCM.
+	topicBatchSize = 1000
 )
 
-type Client struct {
-	enabled bool
-	sender  *messaging.Client
+// Nama backend push yang dapat dipilih lewat PUSH_BACKEND.
+const (
+	BackendFirebase = "fcm"
+	BackendLog      = "log"
+	BackendHTTP     = "http"
+	BackendDisabled = "disabled"
+)
+
+// ResultStatus mengklasifikasikan hasil pengiriman ke satu token.
+type ResultStatus string
+
+const (
+	// StatusSent berarti pesan diterima backend.
+	StatusSent ResultStatus = "sent"
+	// StatusInvalid berarti token tidak lagi terdaftar atau milik project lain;
+	// token sebaiknya dihapus.
+	StatusInvalid ResultStatus = "invalid"
+	// StatusRetryable berarti kegagalan sementara (kuota, server tidak tersedia).
+	StatusRetryable ResultStatus = "retryable"
+	// StatusFailed berarti kegagalan lain, mis. payload tidak valid.
+	StatusFailed ResultStatus = "failed"
+)
+
+// Message adalah isi notifikasi yang dikirim ke token maupun topic.
+type Message struct {
+	Title string            `json:"title"`
+	Body  string            `json:"body"`
+	Data  map[string]string `json:"data,omitempty"`
 }
 
-func NewClient(enabled bool, credentialsPath string) *Client {
-	if !enabled {
-		return &Client{enabled: false}
+// TokenResult adalah hasil pengiriman untuk satu token.
+type TokenResult struct {
+	Token     string
+	Status    ResultStatus
+	MessageID string
+	Err       error
+}
+
+// SendReport merangkum hasil pengiriman per token.
+type SendReport struct {
+	Results []TokenResult
+}
+
+func (report SendReport) Count(status ResultStatus) int {
+	total := 0
+	for _, result := range report.Results {
+		if result.Status == status {
+			total++
+		}
 	}
-	app, err := firebase.NewApp(context.Background(), nil, option.WithCredentialsFile(credentialsPath))
-	if err != nil {
-		log.Printf("FCM disabled: failed to init app: %v", err)
-		return &Client{enabled: false}
+	return total
+}
+
+// InvalidTokens mengembalikan token unik yang sebaiknya dihapus dari database.
+func (report SendReport) InvalidTokens() []string {
+	tokens := make([]string, 0)
+	seen := make(map[string]struct{})
+	for _, result := range report.Results {
+		if result.Status != StatusInvalid {
+			continue
+		}
+		if _, ok := seen[result.Token]; ok {
+			continue
+		}
+		seen[result.Token] = struct{}{}
+		tokens = append(tokens, result.Token)
 	}
-	sender, err := app.Messaging(context.Background())
-	if err != nil {
-		log.Printf("FCM disabled: failed to init messaging: %v", err)
-		return &Client{enabled: false}
+	return tokens
+}
+
+// Err mengembalikan error terakhir dari token yang gagal selain token invalid.
+func (report SendReport) Err() error {
+	var lastErr error
+	for _, result := range report.Results {
+		if result.Status == StatusRetryable || result.Status == StatusFailed {
+			lastErr = result.Err
+		}
+	}
+	return lastErr
+}
+
+// Backend mengirim satu batch ke penyedia push. Client yang menangani batching,
+// logging, dan pencatatan delivery log.
+type Backend interface {
+	Name() string
+	SendMulticast(ctx context.Context, tokens []string, message Message) ([]TokenResult, error)
+	SendTopic(ctx context.Context, topic string, message Message) (string, error)
+	ManageTopic(ctx context.Context, topic string, tokens []string, subscribe bool) ([]TokenResult, error)
+}
+
+// DeliveryRecord adalah satu baris hasil pengiriman untuk delivery log.
+type DeliveryRecord struct {
+	Backend   string
+	Token     string
+	Topic     string
+	Title     string
+	Status    ResultStatus
+	MessageID string
+	Error     string
+}
+
+// Recorder menyimpan hasil pengiriman, mis. ke tabel push log.
+type Recorder interface {
+	RecordDeliveries(ctx context.Context, records []DeliveryRecord)
+}
+
+type Options struct {
+	// Backend berisi fcm, log, http, atau disabled.
+	Backend     string
+	Credentials string
+	// FakeURL adalah base URL server push palsu untuk backend http.
+	FakeURL string
+}
+
+type Client struct {
+	backend  Backend
+	recorder Recorder
+}
+
+// NewClient memilih backend sesuai Options. Kegagalan inisialisasi backend fcm
+// menonaktifkan push dan dicatat di log.
+func NewClient(options Options) *Client {
+	switch strings.ToLower(strings.TrimSpace(options.Backend)) {
+	case BackendFirebase:
+		backend, err := newFirebaseBackend(options.Credentials)
+		if err != nil {
+			log.Printf("push disabled: %v", err)
+			return &Client{}
+		}
+		return &Client{backend: backend}
+	case BackendLog:
+		return &Client{backend: newLogBackend()}
+	case BackendHTTP:
+		if strings.TrimSpace(options.FakeURL) == "" {
+			log.Printf("push disabled: PUSH_FAKE_URL is required for http backend")
+			return &Client{}
+		}
+		return &Client{backend: newHTTPBackend(options.FakeURL)}
+	default:
+		return &Client{}
 	}
-	return &Client{enabled: true, sender: sender}
 }
 
+// SetRecorder memasang penyimpan delivery log. Dipanggil sekali saat startup.
+func (client *Client) SetRecorder(recorder Recorder) {
+	client.recorder = recorder
+}
+
+// Enabled bernilai false jika PUSH_BACKEND=disabled.
+func (client *Client) Enabled() bool {
+	return client != nil && client.backend != nil
+}
+
+// SendToTokens mengirim pesan yang sama ke banyak token dalam batch 500 token.
+// Error hanya dikembalikan jika sebuah batch gagal total; kegagalan per token ada
+// di SendReport.
 func (client *Client) SendToTokens(
 	ctx context.Context,
 	tokens []string,
 	title string,
 	body string,
 	data map[string]string,
-) ([]string, error) {
-	if !client.enabled || client.sender == nil || len(tokens) == 0 {
-		return nil, nil
+) (SendReport, error) {
+	report := SendReport{Results: make([]TokenResult, 0, len(tokens))}
+	if !client.Enabled() {
+		return report, nil
 	}
-	successCount := 0
-	failureCount := 0
-	invalidCount := 0
-	invalidTokens := make([]string, 0)
-	var lastErr error
+	message := Message{Title: title, Body: body, Data: data}
+	filtered := nonEmptyTokens(tokens)
 
-	for index, token := range tokens {
-		if strings.TrimSpace(token) == "" {
-			continue
-		}
-		message := &messaging.Message{
-			Token: token,
-			Notification: &messaging.Notification{
-				Title: title,
-				Body:  body,
-			},
-			Data: data,
-			Android: &messaging.AndroidConfig{
-				Priority: "high",
-				Notification: &messaging.AndroidNotification{
-					ChannelID:    "helpdesk_updates",
-					Priority:     messaging.PriorityMax,
-					DefaultSound: true,
-				},
-			},
-			APNS: &messaging.APNSConfig{
-				Headers: map[string]string{
-					"apns-priority":  "10",
-					"apns-push-type": "alert",
-				},
-			},
-			Webpush: &messaging.WebpushConfig{
-				Headers: map[string]string{
-					"Urgency": "high",
-				},
-			},
+	var batchErr error
+	for start := 0; start < len(filtered); start += multicastBatchSize {
+		batch := filtered[start:min(start+multicastBatchSize, len(filtered))]
+		results, err := client.backend.SendMulticast(ctx, batch, message)
+		if err != nil {
+			batchErr = err
+			log.Printf("push multicast batch failed backend=%s size=%d: %v", client.backend.Name(), len(batch), err)
 		}
-		if _, err := client.sender.Send(ctx, message); err != nil {
-			tokenHint := token
-			if len(tokenHint) > 10 {
-				tokenHint = tokenHint[:10] + "..."
-			}
-			if isInvalidTokenError(err) {
-				invalidCount++
-				invalidTokens = append(invalidTokens, token)
-				log.Printf("fcm invalid token[%d]=%s: %v", index, tokenHint, err)
-				continue
+		for _, result := range results {
+			if result.Status != StatusSent {
+				log.Printf("push send %s token=%s: %v", result.Status, TokenHint(result.Token), result.Err)
 			}
-			failureCount++
-			lastErr = err
-			log.Printf("fcm send failed token[%d]=%s: %v", index, tokenHint, err)
-			continue
 		}
-		successCount++
+		report.Results = append(report.Results, results...)
+	}
+
+	log.Printf(
+		"push sent backend=%s: success=%d invalid=%d retryable=%d failure=%d",
+		client.backend.Name(),
+		report.Count(StatusSent),
+		report.Count(StatusInvalid),
+		report.Count(StatusRetryable),
+		report.Count(StatusFailed),
+	)
+	client.record(ctx, message, "", report.Results)
+	return report, batchErr
+}
+
+// SubscribeToTopic mendaftarkan token ke topic secara batch dan mengembalikan hasil
+// per token.
+func (client *Client) SubscribeToTopic(ctx context.Context, tokens []string, topic string) (SendReport, error) {
+	return client.manageTopic(ctx, tokens, topic, true)
+}
+
+func (client *Client) UnsubscribeFromTopic(ctx context.Context, tokens []string, topic string) (SendReport, error) {
+	return client.manageTopic(ctx, tokens, topic, false)
+}
+
+func (client *Client) manageTopic(ctx context.Context, tokens []string, topic string, subscribe bool) (SendReport, error) {
+	if !client.Enabled() {
+		return SendReport{}, nil
+	}
+	filtered := nonEmptyTokens(tokens)
+	report := SendReport{Results: make([]TokenResult, 0, len(filtered))}
+	var batchErr error
+	for start := 0; start < len(filtered); start += topicBatchSize {
+		batch := filtered[start:min(start+topicBatchSize, len(filtered))]
+		results, err := client.backend.ManageTopic(ctx, topic, batch, subscribe)
+		if err != nil {
+			batchErr = err
+			log.Printf("push topic %s failed backend=%s batch=%d: %v", topic, client.backend.Name(), len(batch), err)
+		}
+		report.Results = append(report.Results, results...)
+	}
+	return report, batchErr
+}
+
+// SendToTopic mengirim satu pesan ke seluruh pelanggan topic; fan-out dilakukan backend.
+func (client *Client) SendToTopic(
+	ctx context.Context,
+	topic string,
+	title string,
+	body string,
+	data map[string]string,
+) error {
+	if !client.Enabled() {
+		return nil
+	}
+	message := Message{Title: title, Body: body, Data: data}
+	messageID, err := client.backend.SendTopic(ctx, topic, message)
+	result := TokenResult{Status: StatusSent, MessageID: messageID}
+	if err != nil {
+		result.Status = StatusFailed
+		result.Err = err
+		log.Printf("push topic send failed backend=%s topic=%s: %v", client.backend.Name(), topic, err)
+	} else {
+		log.Printf("push topic sent backend=%s topic=%s", client.backend.Name(), topic)
+	}
+	client.record(ctx, message, topic, []TokenResult{result})
+	return err
+}
+
+func (client *Client) record(ctx context.Context, message Message, topic string, results []TokenResult) {
+	if client.recorder == nil || len(results) == 0 {
+		return
+	}
+	records := make([]DeliveryRecord, 0, len(results))
+	for _, result := range results {
+		record := DeliveryRecord{
+			Backend:   client.backend.Name(),
+			Token:     result.Token,
+			Topic:     topic,
+			Title:     message.Title,
+			Status:    result.Status,
+			MessageID: result.MessageID,
+		}
+		if result.Err != nil {
+			record.Error = result.Err.Error()
+		}
+		records = append(records, record)
+	}
+	client.recorder.RecordDeliveries(ctx, records)
+}
+
+// TokenHint memotong token agar aman ditampilkan di log.
+func TokenHint(token string) string {
+	if len(token) > 10 {
+		return token[:10] + "..."
 	}
+	return token
+}
 
-	log.Printf("fcm sent: success=%d invalid=%d failure=%d", successCount, invalidCount, failureCount)
-	return invalidTokens, lastErr
+func nonEmptyTokens(tokens []string) []string {
+	filtered := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		if strings.TrimSpace(token) != "" {
+			filtered = append(filtered, token)
+		}
+	}
+	return filtered
 }
 
-func isInvalidTokenError(err error) bool {
-	if err == nil {
-		return false
+// failBatch menandai seluruh token dalam batch dengan status dan error yang sama.
+func failBatch(tokens []string, status ResultStatus, err error) []TokenResult {
+	results := make([]TokenResult, 0, len(tokens))
+	for _, token := range tokens {
+		results = append(results, TokenResult{Token: token, Status: status, Err: err})
 	}
-	message := strings.ToLower(err.Error())
-	return strings.Contains(message, "requested entity was not found") ||
-		strings.Contains(message, "registration-token-not-registered") ||
-		strings.Contains(message, "unregistered")
+	return results
 }
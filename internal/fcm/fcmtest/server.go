@@ -0,0 +1,146 @@
+// Package fcmtest adalah server push palsu untuk PUSH_BACKEND=http. Server mencatat
+// setiap payload di memori sehingga pengiriman dapat diperiksa di dev/CI.
+//
+//	GET    /messages  daftar payload yang diterima
+//	DELETE /messages  kosongkan catatan
+//
+// Token yang diawali "invalid" dibalas status invalid dan token yang diawali
+// "retry" dibalas status retryable untuk mensimulasikan kegagalan FCM.
+package fcmtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Record adalah satu payload yang diterima server.
+type Record struct {
+	Path       string          `json:"path"`
+	Payload    json.RawMessage `json:"payload"`
+	ReceivedAt time.Time       `json:"receivedAt"`
+}
+
+type tokenResult struct {
+	Token     string `json:"token"`
+	Status    string `json:"status"`
+	MessageID string `json:"messageId,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+type Server struct {
+	mux      *http.ServeMux
+	mu       sync.Mutex
+	records  []Record
+	sequence int
+}
+
+// NewServer membuat handler server push palsu; jalankan dengan http.ListenAndServe
+// atau httptest.NewServer.
+func NewServer() *Server {
+	srv := &Server{mux: http.NewServeMux()}
+	srv.mux.HandleFunc("/send", srv.handleTokens(true))
+	srv.mux.HandleFunc("/topics/subscribe", srv.handleTokens(false))
+	srv.mux.HandleFunc("/topics/unsubscribe", srv.handleTokens(false))
+	srv.mux.HandleFunc("/topics/send", srv.handleTopicSend)
+	srv.mux.HandleFunc("/messages", srv.handleMessages)
+	return srv
+}
+
+func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	srv.mux.ServeHTTP(w, r)
+}
+
+// Records mengembalikan salinan payload yang sudah diterima.
+func (srv *Server) Records() []Record {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	return append([]Record(nil), srv.records...)
+}
+
+func (srv *Server) handleTokens(withMessageID bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		payload, ok := srv.capture(w, r)
+		if !ok {
+			return
+		}
+		var body struct {
+			Tokens []string `json:"tokens"`
+		}
+		if err := json.Unmarshal(payload, &body); err != nil {
+			http.Error(w, "invalid payload", http.StatusBadRequest)
+			return
+		}
+		results := make([]tokenResult, 0, len(body.Tokens))
+		for _, token := range body.Tokens {
+			result := tokenResult{Token: token, Status: "sent"}
+			switch {
+			case strings.HasPrefix(token, "invalid"):
+				result.Status = "invalid"
+				result.Error = "registration-token-not-registered"
+			case strings.HasPrefix(token, "retry"):
+				result.Status = "retryable"
+				result.Error = "unavailable"
+			case withMessageID:
+				result.MessageID = srv.nextID()
+			}
+			results = append(results, result)
+		}
+		writeJSON(w, map[string]any{"results": results})
+	}
+}
+
+func (srv *Server) handleTopicSend(w http.ResponseWriter, r *http.Request) {
+	if _, ok := srv.capture(w, r); !ok {
+		return
+	}
+	writeJSON(w, map[string]any{"messageId": srv.nextID()})
+}
+
+func (srv *Server) handleMessages(w http.ResponseWriter, r *http.Request) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, srv.records)
+	case http.MethodDelete:
+		srv.records = nil
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (srv *Server) capture(w http.ResponseWriter, r *http.Request) (json.RawMessage, bool) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return nil, false
+	}
+	var payload json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return nil, false
+	}
+	srv.mu.Lock()
+	srv.records = append(srv.records, Record{Path: r.URL.Path, Payload: payload, ReceivedAt: time.Now()})
+	srv.mu.Unlock()
+	return payload, true
+}
+
+func (srv *Server) nextID() string {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	srv.sequence++
+	return fmt.Sprintf("fake-%d", srv.sequence)
+}
+
+func writeJSON(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		log.Printf("fakepush: failed to write response: %v", err)
+	}
+}
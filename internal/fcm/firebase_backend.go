@@ -0,0 +1,154 @@
+package fcm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	firebase "firebase.google.com/go/v4"
+	"firebase.google.com/go/v4/messaging"
+	"google.golang.org/api/option"
+)
+
+// firebaseBackend mengirim push melalui Firebase Cloud Messaging.
+type firebaseBackend struct {
+	sender *messaging.Client
+}
+
+func newFirebaseBackend(credentialsPath string) (*firebaseBackend, error) {
+	app, err := firebase.NewApp(context.Background(), nil, option.WithCredentialsFile(credentialsPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to init firebase app: %w", err)
+	}
+	sender, err := app.Messaging(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to init firebase messaging: %w", err)
+	}
+	return &firebaseBackend{sender: sender}, nil
+}
+
+func (backend *firebaseBackend) Name() string {
+	return BackendFirebase
+}
+
+func (backend *firebaseBackend) SendMulticast(ctx context.Context, tokens []string, message Message) ([]TokenResult, error) {
+	response, err := backend.sender.SendEachForMulticast(ctx, &messaging.MulticastMessage{
+		Tokens:       tokens,
+		Notification: &messaging.Notification{Title: message.Title, Body: message.Body},
+		Data:         message.Data,
+		Android:      androidConfig(),
+		APNS:         apnsConfig(),
+		Webpush:      webpushConfig(),
+	})
+	if err != nil {
+		return failBatch(tokens, classifyError(err), err), err
+	}
+	results := make([]TokenResult, 0, len(tokens))
+	for index, item := range response.Responses {
+		if index >= len(tokens) || item == nil {
+			continue
+		}
+		result := TokenResult{Token: tokens[index], MessageID: item.MessageID, Status: StatusSent}
+		if !item.Success {
+			result.Status = classifyError(item.Error)
+			result.Err = item.Error
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (backend *firebaseBackend) SendTopic(ctx context.Context, topic string, message Message) (string, error) {
+	return backend.sender.Send(ctx, &messaging.Message{
+		Topic:        topic,
+		Notification: &messaging.Notification{Title: message.Title, Body: message.Body},
+		Data:         message.Data,
+		Android:      androidConfig(),
+		APNS:         apnsConfig(),
+		Webpush:      webpushConfig(),
+	})
+}
+
+func (backend *firebaseBackend) ManageTopic(ctx context.Context, topic string, tokens []string, subscribe bool) ([]TokenResult, error) {
+	call := backend.sender.UnsubscribeFromTopic
+	if subscribe {
+		call = backend.sender.SubscribeToTopic
+	}
+	response, err := call(ctx, tokens, topic)
+	if err != nil {
+		return failBatch(tokens, classifyError(err), err), err
+	}
+	failures := make(map[int]string, len(response.Errors))
+	for _, item := range response.Errors {
+		if item != nil {
+			failures[item.Index] = item.Reason
+		}
+	}
+	results := make([]TokenResult, 0, len(tokens))
+	for index, token := range tokens {
+		result := TokenResult{Token: token, Status: StatusSent}
+		if reason, failed := failures[index]; failed {
+			result.Status = classifyTopicReason(reason)
+			result.Err = errors.New(reason)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// classifyError memetakan error FCM memakai helper dari paket messaging.
+func classifyError(err error) ResultStatus {
+	switch {
+	case err == nil:
+		return StatusSent
+	case messaging.IsUnregistered(err), messaging.IsSenderIDMismatch(err):
+		return StatusInvalid
+	case messaging.IsQuotaExceeded(err), messaging.IsUnavailable(err), messaging.IsInternal(err):
+		return StatusRetryable
+	default:
+		return StatusFailed
+	}
+}
+
+// classifyTopicReason memetakan kode status dari respons topic management (mis.
+// NOT_FOUND), yang tidak menyertakan error bertipe sehingga helper messaging tidak
+// dapat dipakai.
+func classifyTopicReason(reason string) ResultStatus {
+	switch strings.ToUpper(reason) {
+	case "NOT_FOUND", "INVALID_ARGUMENT":
+		return StatusInvalid
+	case "RESOURCE_EXHAUSTED", "UNAVAILABLE", "INTERNAL":
+		return StatusRetryable
+	default:
+		return StatusFailed
+	}
+}
+
+func androidConfig() *messaging.AndroidConfig {
+	return &messaging.AndroidConfig{
+		Priority: "high",
+		Notification: &messaging.AndroidNotification{
+			ChannelID:    "helpdesk_updates",
+			Priority:     messaging.PriorityMax,
+			DefaultSound: true,
+		},
+	}
+}
+
+func apnsConfig() *messaging.APNSConfig {
+	return &messaging.APNSConfig{
+		Headers: map[string]string{
+			"apns-priority":  "10",
+			"apns-push-type": "alert",
+		},
+	}
+}
+
+func webpushConfig() *messaging.WebpushConfig {
+	return &messaging.WebpushConfig{
+		Headers: map[string]string{
+			"Urgency": "high",
+		},
+	}
+}
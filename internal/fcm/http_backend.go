@@ -0,0 +1,145 @@
+package fcm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// httpBackend mengirim payload ke server push palsu (lihat cmd/fakepush) agar
+// pengiriman dapat diperiksa di dev/CI tanpa Firebase.
+//
+// Endpoint yang dipanggil:
+//   - POST {base}/send             {tokens, message} -> {results: [{token, status, messageId, error}]}
+//   - POST {base}/topics/send      {topic, message}  -> {messageId}
+//   - POST {base}/topics/subscribe {topic, tokens}   -> {results: [...]}
+//   - POST {base}/topics/unsubscribe
+type httpBackend struct {
+	baseURL string
+	client  *http.Client
+}
+
+type httpTokenResult struct {
+	Token     string       `json:"token"`
+	Status    ResultStatus `json:"status"`
+	MessageID string       `json:"messageId"`
+	Error     string       `json:"error"`
+}
+
+type httpResultsResponse struct {
+	Results []httpTokenResult `json:"results"`
+}
+
+func newHTTPBackend(baseURL string) *httpBackend {
+	return &httpBackend{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (backend *httpBackend) Name() string {
+	return BackendHTTP
+}
+
+func (backend *httpBackend) SendMulticast(ctx context.Context, tokens []string, message Message) ([]TokenResult, error) {
+	var response httpResultsResponse
+	payload := map[string]any{"tokens": tokens, "message": message}
+	if err := backend.post(ctx, "/send", payload, &response); err != nil {
+		return failBatch(tokens, httpErrorStatus(err), err), err
+	}
+	return toTokenResults(tokens, response.Results), nil
+}
+
+func (backend *httpBackend) SendTopic(ctx context.Context, topic string, message Message) (string, error) {
+	var response struct {
+		MessageID string `json:"messageId"`
+	}
+	payload := map[string]any{"topic": topic, "message": message}
+	if err := backend.post(ctx, "/topics/send", payload, &response); err != nil {
+		return "", err
+	}
+	return response.MessageID, nil
+}
+
+func (backend *httpBackend) ManageTopic(ctx context.Context, topic string, tokens []string, subscribe bool) ([]TokenResult, error) {
+	path := "/topics/unsubscribe"
+	if subscribe {
+		path = "/topics/subscribe"
+	}
+	var response httpResultsResponse
+	if err := backend.post(ctx, path, map[string]any{"topic": topic, "tokens": tokens}, &response); err != nil {
+		return failBatch(tokens, httpErrorStatus(err), err), err
+	}
+	return toTokenResults(tokens, response.Results), nil
+}
+
+type httpStatusError struct {
+	code int
+	body string
+}
+
+func (err httpStatusError) Error() string {
+	return fmt.Sprintf("push server returned %d: %s", err.code, err.body)
+}
+
+func (backend *httpBackend) post(ctx context.Context, path string, payload any, target any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, backend.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := backend.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode >= 300 {
+		var buffer bytes.Buffer
+		_, _ = buffer.ReadFrom(response.Body)
+		return httpStatusError{code: response.StatusCode, body: strings.TrimSpace(buffer.String())}
+	}
+	return json.NewDecoder(response.Body).Decode(target)
+}
+
+// toTokenResults mencocokkan hasil server dengan urutan token; token tanpa hasil
+// dianggap gagal.
+func toTokenResults(tokens []string, items []httpTokenResult) []TokenResult {
+	byToken := make(map[string]httpTokenResult, len(items))
+	for _, item := range items {
+		byToken[item.Token] = item
+	}
+	results := make([]TokenResult, 0, len(tokens))
+	for _, token := range tokens {
+		item, ok := byToken[token]
+		if !ok {
+			results = append(results, TokenResult{Token: token, Status: StatusFailed, Err: errors.New("missing result")})
+			continue
+		}
+		result := TokenResult{Token: token, Status: item.Status, MessageID: item.MessageID}
+		if result.Status == "" {
+			result.Status = StatusSent
+		}
+		if item.Error != "" {
+			result.Err = errors.New(item.Error)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func httpErrorStatus(err error) ResultStatus {
+	var statusErr httpStatusError
+	if errors.As(err, &statusErr) && statusErr.code < 500 && statusErr.code != http.StatusTooManyRequests {
+		return StatusFailed
+	}
+	return StatusRetryable
+}
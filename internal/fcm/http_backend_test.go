@@ -0,0 +1,154 @@
+package fcm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"unila_helpdesk_backend/internal/fcm/fcmtest"
+)
+
+type memoryRecorder struct {
+	mu      sync.Mutex
+	records []DeliveryRecord
+}
+
+func (recorder *memoryRecorder) RecordDeliveries(_ context.Context, records []DeliveryRecord) {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	recorder.records = append(recorder.records, records...)
+}
+
+func newFakePushClient(t *testing.T) (*Client, *fcmtest.Server, *memoryRecorder) {
+	t.Helper()
+	fake := fcmtest.NewServer()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	client := NewClient(Options{Backend: BackendHTTP, FakeURL: server.URL + "/"})
+	recorder := &memoryRecorder{}
+	client.SetRecorder(recorder)
+	return client, fake, recorder
+}
+
+func TestHTTPBackendSendToTokens(t *testing.T) {
+	client, fake, recorder := newFakePushClient(t)
+	if !client.Enabled() {
+		t.Fatal("backend http seharusnya aktif")
+	}
+
+	report, err := client.SendToTokens(
+		context.Background(),
+		[]string{"tok-1", "invalid-1", "", "retry-1"},
+		"Tiket diperbarui",
+		"Status tiket berubah",
+		map[string]string{"ticket_id": "TK-1"},
+	)
+	if err != nil {
+		t.Fatalf("SendToTokens: %v", err)
+	}
+	if len(report.Results) != 3 {
+		t.Fatalf("results = %d, want 3 (token kosong dilewati)", len(report.Results))
+	}
+	if report.Count(StatusSent) != 1 || report.Count(StatusInvalid) != 1 || report.Count(StatusRetryable) != 1 {
+		t.Fatalf("report = %+v", report.Results)
+	}
+	if invalid := report.InvalidTokens(); len(invalid) != 1 || invalid[0] != "invalid-1" {
+		t.Fatalf("InvalidTokens = %v", invalid)
+	}
+	if report.Results[0].MessageID == "" || report.Err() == nil {
+		t.Fatalf("messageId/err tidak sesuai: %+v", report.Results)
+	}
+
+	records := fake.Records()
+	if len(records) != 1 || records[0].Path != "/send" {
+		t.Fatalf("records = %+v", records)
+	}
+	var payload struct {
+		Tokens  []string `json:"tokens"`
+		Message Message  `json:"message"`
+	}
+	if err := json.Unmarshal(records[0].Payload, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if len(payload.Tokens) != 3 || payload.Message.Title != "Tiket diperbarui" || payload.Message.Data["ticket_id"] != "TK-1" {
+		t.Fatalf("payload = %+v", payload)
+	}
+	if len(recorder.records) != 3 || recorder.records[0].Backend != BackendHTTP || recorder.records[0].Title != "Tiket diperbarui" {
+		t.Fatalf("delivery log = %+v", recorder.records)
+	}
+}
+
+func TestHTTPBackendBatchesMulticast(t *testing.T) {
+	client, fake, _ := newFakePushClient(t)
+	tokens := make([]string, multicastBatchSize+1)
+	for index := range tokens {
+		tokens[index] = fmt.Sprintf("tok-%d", index)
+	}
+	report, err := client.SendToTokens(context.Background(), tokens, "Judul", "Isi", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Count(StatusSent) != len(tokens) {
+		t.Fatalf("sent = %d, want %d", report.Count(StatusSent), len(tokens))
+	}
+	if records := fake.Records(); len(records) != 2 {
+		t.Fatalf("batch = %d, want 2", len(records))
+	}
+}
+
+func TestHTTPBackendTopics(t *testing.T) {
+	client, fake, recorder := newFakePushClient(t)
+	ctx := context.Background()
+
+	report, err := client.SubscribeToTopic(ctx, []string{"tok-1", "invalid-1"}, TopicAnnouncements)
+	if err != nil {
+		t.Fatalf("SubscribeToTopic: %v", err)
+	}
+	if report.Count(StatusSent) != 1 || report.Count(StatusInvalid) != 1 {
+		t.Fatalf("subscribe report = %+v", report.Results)
+	}
+	if err := client.SendToTopic(ctx, TopicAnnouncements, "Maintenance", "SIAKAD offline", nil); err != nil {
+		t.Fatalf("SendToTopic: %v", err)
+	}
+
+	records := fake.Records()
+	if len(records) != 2 || records[0].Path != "/topics/subscribe" || records[1].Path != "/topics/send" {
+		t.Fatalf("records = %+v", records)
+	}
+	if len(recorder.records) != 1 || recorder.records[0].Topic != TopicAnnouncements || recorder.records[0].MessageID == "" {
+		t.Fatalf("delivery log = %+v", recorder.records)
+	}
+}
+
+func TestHTTPBackendServerErrors(t *testing.T) {
+	cases := []struct {
+		name   string
+		status int
+		want   ResultStatus
+	}{
+		{name: "server error", status: http.StatusServiceUnavailable, want: StatusRetryable},
+		{name: "rate limit", status: http.StatusTooManyRequests, want: StatusRetryable},
+		{name: "payload ditolak", status: http.StatusBadRequest, want: StatusFailed},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				http.Error(w, "gagal", tc.status)
+			}))
+			t.Cleanup(server.Close)
+			client := NewClient(Options{Backend: BackendHTTP, FakeURL: server.URL})
+
+			report, err := client.SendToTokens(context.Background(), []string{"tok-1", "tok-2"}, "Judul", "Isi", nil)
+			if err == nil {
+				t.Fatal("error batch seharusnya dikembalikan")
+			}
+			if report.Count(tc.want) != 2 {
+				t.Fatalf("report = %+v, want semua %s", report.Results, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package fcm
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync/atomic"
+)
+
+// logBackend hanya menulis pesan ke log dan selalu berhasil. Dipakai saat
+// pengembangan tanpa kredensial Firebase.
+type logBackend struct {
+	sequence atomic.Int64
+}
+
+func newLogBackend() *logBackend {
+	return &logBackend{}
+}
+
+func (backend *logBackend) Name() string {
+	return BackendLog
+}
+
+func (backend *logBackend) SendMulticast(_ context.Context, tokens []string, message Message) ([]TokenResult, error) {
+	results := make([]TokenResult, 0, len(tokens))
+	for _, token := range tokens {
+		messageID := backend.nextID()
+		log.Printf("push[log] token=%s id=%s title=%q body=%q data=%v", TokenHint(token), messageID, message.Title, message.Body, message.Data)
+		results = append(results, TokenResult{Token: token, Status: StatusSent, MessageID: messageID})
+	}
+	return results, nil
+}
+
+func (backend *logBackend) SendTopic(_ context.Context, topic string, message Message) (string, error) {
+	messageID := backend.nextID()
+	log.Printf("push[log] topic=%s id=%s title=%q body=%q data=%v", topic, messageID, message.Title, message.Body, message.Data)
+	return messageID, nil
+}
+
+func (backend *logBackend) ManageTopic(_ context.Context, topic string, tokens []string, subscribe bool) ([]TokenResult, error) {
+	action := "unsubscribe"
+	if subscribe {
+		action = "subscribe"
+	}
+	log.Printf("push[log] %s topic=%s tokens=%d", action, topic, len(tokens))
+	results := make([]TokenResult, 0, len(tokens))
+	for _, token := range tokens {
+		results = append(results, TokenResult{Token: token, Status: StatusSent})
+	}
+	return results, nil
+}
+
+func (backend *logBackend) nextID() string {
+	return fmt.Sprintf("log-%d", backend.sequence.Add(1))
+}
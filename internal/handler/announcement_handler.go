@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"net/http"
+
+	"unila_helpdesk_backend/internal/middleware"
+	"unila_helpdesk_backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AnnouncementHandler struct {
+	announcements *service.AnnouncementService
+}
+
+func NewAnnouncementHandler(announcements *service.AnnouncementService) *AnnouncementHandler {
+	return &AnnouncementHandler{announcements: announcements}
+}
+
+func (handler *AnnouncementHandler) RegisterRoutes(public *gin.RouterGroup) {
+	public.GET("/announcements", handler.listActive)
+}
+
+func (handler *AnnouncementHandler) RegisterAdminRoutes(admin *gin.RouterGroup) {
+	admin.GET("/admin/announcements", handler.listAll)
+	admin.POST("/announcements", handler.createAnnouncement)
+	admin.PUT("/announcements/:id", handler.updateAnnouncement)
+	admin.DELETE("/announcements/:id", handler.deleteAnnouncement)
+	admin.POST("/announcements/:id/broadcast", handler.broadcastAnnouncement)
+}
+
+func (handler *AnnouncementHandler) listActive(c *gin.Context) {
+	result, err := handler.announcements.ListActive(c.Query("categoryId"))
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondOK(c, result)
+}
+
+func (handler *AnnouncementHandler) listAll(c *gin.Context) {
+	result, err := handler.announcements.ListAll()
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondOK(c, result)
+}
+
+func (handler *AnnouncementHandler) createAnnouncement(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	var req service.AnnouncementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "payload tidak valid")
+		return
+	}
+	result, err := handler.announcements.Create(c, user, req)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondCreated(c, result)
+}
+
+func (handler *AnnouncementHandler) updateAnnouncement(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	var req service.AnnouncementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "payload tidak valid")
+		return
+	}
+	result, err := handler.announcements.Update(c, user, c.Param("id"), req)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondOK(c, result)
+}
+
+func (handler *AnnouncementHandler) deleteAnnouncement(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	if err := handler.announcements.Delete(c, user, c.Param("id")); err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondOK(c, gin.H{"deleted": true})
+}
+
+func (handler *AnnouncementHandler) broadcastAnnouncement(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	var req service.AnnouncementBroadcastRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "payload tidak valid")
+		return
+	}
+	result, err := handler.announcements.Broadcast(c, user, c.Param("id"), req)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondOK(c, result)
+}
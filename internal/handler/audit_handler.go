@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"net/http"
+
+	"unila_helpdesk_backend/internal/repository"
+	"unila_helpdesk_backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	audit *service.AuditService
+}
+
+func NewAuditHandler(audit *service.AuditService) *AuditHandler {
+	return &AuditHandler{audit: audit}
+}
+
+func (handler *AuditHandler) RegisterRoutes(admin *gin.RouterGroup) {
+	admin.GET("/admin/audit-logs", handler.listLogs)
+	admin.GET("/admin/audit-logs/verify", handler.verifyChain)
+}
+
+func (handler *AuditHandler) listLogs(c *gin.Context) {
+	start, ok := parseOptionalTime(c, "start")
+	if !ok {
+		return
+	}
+	end, ok := parseOptionalTime(c, "end")
+	if !ok {
+		return
+	}
+	page, limit := parsePageAndLimit(c, 20, 100)
+
+	result, err := handler.audit.List(repository.AuditLogFilter{
+		ActorID:    c.Query("actorId"),
+		Action:     c.Query("action"),
+		EntityType: c.Query("entityType"),
+		EntityID:   c.Query("entityId"),
+		Start:      start,
+		End:        end,
+	}, page, limit)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondOK(c, result)
+}
+
+func (handler *AuditHandler) verifyChain(c *gin.Context) {
+	result, err := handler.audit.Verify()
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondOK(c, result)
+}
@@ -14,8 +14,10 @@ type AuthHandler struct {
 }
 
 type loginRequest struct {
-    Username string `json:"username"`
-    Password string `json:"password"`
+    Username   string `json:"username"`
+    Password   string `json:"password"`
+    DeviceName string `json:"device_name"`
+    Platform   string `json:"platform"`
 }
 
 type refreshRequest struct {
@@ -29,6 +31,14 @@ func NewAuthHandler(auth *service.AuthService) *AuthHandler {
 func (handler *AuthHandler) RegisterRoutes(router *gin.RouterGroup) {
     router.POST("/auth/login", handler.login)
     router.POST("/auth/refresh", handler.refreshToken)
+    router.GET("/.well-known/jwks.json", handler.jwks)
+}
+
+// jwks tidak memakai envelope data karena formatnya ditentukan RFC 7517 dan
+// dibaca langsung oleh library JWT layanan lain.
+func (handler *AuthHandler) jwks(c *gin.Context) {
+    c.Header("Cache-Control", "public, max-age=300")
+    c.JSON(http.StatusOK, handler.auth.JWKS())
 }
 
 func (handler *AuthHandler) login(c *gin.Context) {
@@ -37,8 +47,7 @@ func (handler *AuthHandler) login(c *gin.Context) {
         respondError(c, http.StatusBadRequest, "payload tidak valid")
         return
     }
-    clientType := c.GetHeader("X-Client-Type")
-    result, err := handler.auth.LoginWithPasswordClient(req.Username, req.Password, clientType)
+    result, err := handler.auth.LoginWithPasswordClient(c, req.Username, req.Password, clientInfo(c, req.DeviceName, req.Platform))
     if err != nil {
         if errors.Is(err, service.ErrAdminWebOnly) {
             respondError(c, http.StatusForbidden, err.Error())
@@ -56,8 +65,7 @@ func (handler *AuthHandler) refreshToken(c *gin.Context) {
         respondError(c, http.StatusBadRequest, "payload tidak valid")
         return
     }
-    clientType := c.GetHeader("X-Client-Type")
-    result, err := handler.auth.RefreshWithTokenClient(req.RefreshToken, clientType)
+    result, err := handler.auth.RefreshWithTokenClient(c, req.RefreshToken, clientInfo(c, "", ""))
     if err != nil {
         if errors.Is(err, service.ErrAdminWebOnly) {
             respondError(c, http.StatusForbidden, err.Error())
@@ -68,3 +76,21 @@ func (handler *AuthHandler) refreshToken(c *gin.Context) {
     }
     respondOK(c, result)
 }
+
+// clientInfo mengambil identitas perangkat dari request. Nama perangkat dan
+// platform juga dapat dikirim lewat header X-Device-Name / X-Platform.
+func clientInfo(c *gin.Context, deviceName string, platform string) service.ClientInfo {
+    if deviceName == "" {
+        deviceName = c.GetHeader("X-Device-Name")
+    }
+    if platform == "" {
+        platform = c.GetHeader("X-Platform")
+    }
+    return service.ClientInfo{
+        Type:       c.GetHeader("X-Client-Type"),
+        DeviceName: deviceName,
+        Platform:   platform,
+        IPAddress:  c.ClientIP(),
+        UserAgent:  c.Request.UserAgent(),
+    }
+}
@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"net/http"
+
+	"unila_helpdesk_backend/internal/middleware"
+	"unila_helpdesk_backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CannedResponseHandler struct {
+	canned *service.CannedResponseService
+}
+
+func NewCannedResponseHandler(canned *service.CannedResponseService) *CannedResponseHandler {
+	return &CannedResponseHandler{canned: canned}
+}
+
+func (handler *CannedResponseHandler) RegisterRoutes(admin *gin.RouterGroup) {
+	admin.GET("/canned-responses", handler.listResponses)
+	admin.POST("/canned-responses", handler.createResponse)
+	admin.PUT("/canned-responses/:id", handler.updateResponse)
+	admin.DELETE("/canned-responses/:id", handler.deleteResponse)
+	admin.GET("/canned-responses/:id/preview", handler.previewResponse)
+	admin.GET("/macros", handler.listMacros)
+	admin.POST("/macros", handler.createMacro)
+	admin.PUT("/macros/:id", handler.updateMacro)
+	admin.DELETE("/macros/:id", handler.deleteMacro)
+	admin.POST("/tickets/:id/macros/:macroId", handler.applyMacro)
+}
+
+func (handler *CannedResponseHandler) listResponses(c *gin.Context) {
+	result, err := handler.canned.List(c.Query("categoryId"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondOK(c, result)
+}
+
+func (handler *CannedResponseHandler) createResponse(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	var req service.CannedResponseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "payload tidak valid")
+		return
+	}
+	result, err := handler.canned.Create(c, user, req)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondCreated(c, result)
+}
+
+func (handler *CannedResponseHandler) updateResponse(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	var req service.CannedResponseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "payload tidak valid")
+		return
+	}
+	result, err := handler.canned.Update(c, user, c.Param("id"), req)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondOK(c, result)
+}
+
+func (handler *CannedResponseHandler) deleteResponse(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	if err := handler.canned.Delete(c, user, c.Param("id")); err != nil {
+		respondError(c, http.StatusNotFound, err.Error())
+		return
+	}
+	respondOK(c, gin.H{"deleted": true})
+}
+
+func (handler *CannedResponseHandler) previewResponse(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	ticketID := c.Query("ticketId")
+	if ticketID == "" {
+		respondError(c, http.StatusBadRequest, "ticketId wajib diisi")
+		return
+	}
+	result, err := handler.canned.Preview(user, c.Param("id"), ticketID)
+	if err != nil {
+		respondError(c, http.StatusNotFound, err.Error())
+		return
+	}
+	respondOK(c, result)
+}
+
+func (handler *CannedResponseHandler) listMacros(c *gin.Context) {
+	result, err := handler.canned.ListMacros(c.Query("categoryId"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondOK(c, result)
+}
+
+func (handler *CannedResponseHandler) createMacro(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	var req service.MacroRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "payload tidak valid")
+		return
+	}
+	result, err := handler.canned.CreateMacro(c, user, req)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondCreated(c, result)
+}
+
+func (handler *CannedResponseHandler) updateMacro(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	var req service.MacroRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "payload tidak valid")
+		return
+	}
+	result, err := handler.canned.UpdateMacro(c, user, c.Param("id"), req)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondOK(c, result)
+}
+
+func (handler *CannedResponseHandler) deleteMacro(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	if err := handler.canned.DeleteMacro(c, user, c.Param("id")); err != nil {
+		respondError(c, http.StatusNotFound, err.Error())
+		return
+	}
+	respondOK(c, gin.H{"deleted": true})
+}
+
+func (handler *CannedResponseHandler) applyMacro(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	result, err := handler.canned.ApplyMacro(c, user, c.Param("id"), c.Param("macroId"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondOK(c, result)
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"net/http"
 
+	"unila_helpdesk_backend/internal/middleware"
 	"unila_helpdesk_backend/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -19,10 +20,19 @@ func NewCategoryHandler(categories *service.CategoryService) *CategoryHandler {
 func (handler *CategoryHandler) RegisterRoutes(public *gin.RouterGroup) {
 	public.GET("/categories", handler.listAll)
 	public.GET("/categories/guest", handler.listGuest)
+	public.GET("/categories/:id/fields", handler.listFields)
 }
 
 func (handler *CategoryHandler) RegisterAdminRoutes(admin *gin.RouterGroup) {
+	admin.GET("/admin/categories", handler.listAdmin)
+	admin.POST("/categories", handler.create)
+	admin.PUT("/categories/order", handler.reorder)
+	admin.PUT("/categories/:id", handler.update)
+	admin.POST("/categories/:id/archive", handler.archive)
+	admin.POST("/categories/:id/unarchive", handler.unarchive)
+	admin.POST("/categories/:id/merge", handler.merge)
 	admin.PUT("/categories/:id/template", handler.assignTemplate)
+	admin.PUT("/categories/:id/fields", handler.replaceFields)
 }
 
 func (handler *CategoryHandler) listAll(c *gin.Context) {
@@ -34,6 +44,15 @@ func (handler *CategoryHandler) listAll(c *gin.Context) {
 	respondOK(c, items)
 }
 
+func (handler *CategoryHandler) listAdmin(c *gin.Context) {
+	items, err := handler.categories.ListAdmin()
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondOK(c, items)
+}
+
 func (handler *CategoryHandler) listGuest(c *gin.Context) {
 	items, err := handler.categories.ListGuest()
 	if err != nil {
@@ -48,15 +67,158 @@ type assignTemplateRequest struct {
 }
 
 func (handler *CategoryHandler) assignTemplate(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
 	categoryID := c.Param("id")
 	var req assignTemplateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		respondError(c, http.StatusBadRequest, "payload tidak valid")
 		return
 	}
-	if err := handler.categories.AssignTemplate(categoryID, req.TemplateID); err != nil {
+	if err := handler.categories.AssignTemplate(c, user, categoryID, req.TemplateID); err != nil {
 		respondError(c, http.StatusBadRequest, err.Error())
 		return
 	}
 	respondOK(c, gin.H{"categoryId": categoryID, "templateId": req.TemplateID})
 }
+
+func (handler *CategoryHandler) listFields(c *gin.Context) {
+	items, err := handler.categories.ListFields(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusNotFound, err.Error())
+		return
+	}
+	respondOK(c, items)
+}
+
+type replaceFieldsRequest struct {
+	Fields []service.CategoryFieldRequest `json:"fields"`
+}
+
+func (handler *CategoryHandler) replaceFields(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	var req replaceFieldsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "payload tidak valid")
+		return
+	}
+	items, err := handler.categories.ReplaceFields(c, user, c.Param("id"), req.Fields)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondOK(c, items)
+}
+
+func (handler *CategoryHandler) create(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	var req service.CategoryCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "payload tidak valid")
+		return
+	}
+	item, err := handler.categories.Create(c, user, req)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondOK(c, item)
+}
+
+func (handler *CategoryHandler) update(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	var req service.CategoryUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "payload tidak valid")
+		return
+	}
+	item, err := handler.categories.Update(c, user, c.Param("id"), req)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondOK(c, item)
+}
+
+func (handler *CategoryHandler) archive(c *gin.Context) {
+	handler.setArchived(c, true)
+}
+
+func (handler *CategoryHandler) unarchive(c *gin.Context) {
+	handler.setArchived(c, false)
+}
+
+func (handler *CategoryHandler) setArchived(c *gin.Context, archived bool) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	item, err := handler.categories.SetArchived(c, user, c.Param("id"), archived)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondOK(c, item)
+}
+
+type reorderCategoriesRequest struct {
+	IDs []string `json:"ids"`
+}
+
+func (handler *CategoryHandler) reorder(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	var req reorderCategoriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "payload tidak valid")
+		return
+	}
+	items, err := handler.categories.Reorder(c, user, req.IDs)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondOK(c, items)
+}
+
+type mergeCategoryRequest struct {
+	TargetID string `json:"targetId"`
+}
+
+func (handler *CategoryHandler) merge(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	var req mergeCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.TargetID == "" {
+		respondError(c, http.StatusBadRequest, "payload tidak valid")
+		return
+	}
+	item, err := handler.categories.Merge(c, user, c.Param("id"), req.TargetID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondOK(c, item)
+}
@@ -1,10 +1,14 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"unila_helpdesk_backend/internal/util"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -23,6 +27,36 @@ func parseOptionalTime(c *gin.Context, param string) (*time.Time, bool) {
 	return &parsed, true
 }
 
+// parseOptionalBool parses an optional boolean query parameter.
+// Returns nil if the param is empty, or responds with an error and returns false.
+func parseOptionalBool(c *gin.Context, param string) (*bool, bool) {
+	raw := c.Query(param)
+	if raw == "" {
+		return nil, true
+	}
+	parsed, err := strconv.ParseBool(raw)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, param+" tidak valid")
+		return nil, false
+	}
+	return &parsed, true
+}
+
+// splitQueryList splits a comma-separated query value and drops empty items.
+func splitQueryList(raw string) []string {
+	if raw == "" {
+		return nil
+	}
+	parts := strings.Split(raw, ",")
+	items := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if cleaned := strings.TrimSpace(part); cleaned != "" {
+			items = append(items, cleaned)
+		}
+	}
+	return items
+}
+
 func parsePageAndLimit(
 	c *gin.Context,
 	defaultLimit int,
@@ -47,3 +81,12 @@ func parsePositiveIntQuery(c *gin.Context, key string, fallback int) int {
 	}
 	return value
 }
+
+// respondCursorError maps an invalid cursor to 400 and anything else to 500.
+func respondCursorError(c *gin.Context, err error) {
+	if errors.Is(err, util.ErrInvalidCursor) {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondError(c, http.StatusInternalServerError, err.Error())
+}
@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"net/http"
+
+	"unila_helpdesk_backend/internal/domain"
+	"unila_helpdesk_backend/internal/middleware"
+	"unila_helpdesk_backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type KnowledgeBaseHandler struct {
+	kb *service.KnowledgeBaseService
+}
+
+func NewKnowledgeBaseHandler(kb *service.KnowledgeBaseService) *KnowledgeBaseHandler {
+	return &KnowledgeBaseHandler{kb: kb}
+}
+
+func (handler *KnowledgeBaseHandler) RegisterRoutes(public *gin.RouterGroup) {
+	public.GET("/kb/articles", handler.listPublished)
+	public.GET("/kb/articles/:id", handler.getPublished)
+	public.POST("/kb/articles/:id/vote", handler.vote)
+	public.GET("/kb/suggest", handler.suggest)
+	public.POST("/kb/deflections", handler.recordDeflection)
+}
+
+func (handler *KnowledgeBaseHandler) RegisterAdminRoutes(admin *gin.RouterGroup) {
+	admin.GET("/admin/kb/articles", handler.listAdmin)
+	admin.GET("/admin/kb/articles/:id", handler.getAdmin)
+	admin.POST("/kb/articles", handler.createArticle)
+	admin.PUT("/kb/articles/:id", handler.updateArticle)
+	admin.POST("/kb/articles/:id/publish", handler.publishArticle)
+	admin.POST("/kb/articles/:id/unpublish", handler.unpublishArticle)
+	admin.DELETE("/kb/articles/:id", handler.deleteArticle)
+	admin.POST("/tickets/:id/resolve", handler.resolveTicket)
+	admin.POST("/tickets/:id/promote-article", handler.promoteResolution)
+}
+
+func (handler *KnowledgeBaseHandler) listPublished(c *gin.Context) {
+	page, limit := parsePageAndLimit(c, 10, 50)
+	result, err := handler.kb.ListPublished(c.Query("q"), c.Query("categoryId"), page, limit)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondOK(c, result)
+}
+
+func (handler *KnowledgeBaseHandler) getPublished(c *gin.Context) {
+	result, err := handler.kb.GetPublished(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusNotFound, err.Error())
+		return
+	}
+	respondOK(c, result)
+}
+
+func (handler *KnowledgeBaseHandler) vote(c *gin.Context) {
+	var req struct {
+		Helpful *bool `json:"helpful"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.Helpful == nil {
+		respondError(c, http.StatusBadRequest, "payload tidak valid")
+		return
+	}
+	var voter *domain.User
+	if user, ok := middleware.GetUser(c); ok {
+		voter = &user
+	}
+	voterKey := service.ArticleVoterKey(voter, c.ClientIP(), c.Request.UserAgent())
+	result, err := handler.kb.Vote(c.Param("id"), voterKey, *req.Helpful)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondOK(c, result)
+}
+
+func (handler *KnowledgeBaseHandler) suggest(c *gin.Context) {
+	result, err := handler.kb.Suggest(c.Query("q"), c.Query("categoryId"))
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondOK(c, result)
+}
+
+func (handler *KnowledgeBaseHandler) recordDeflection(c *gin.Context) {
+	var req service.ArticleDeflectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "payload tidak valid")
+		return
+	}
+	if err := handler.kb.RecordDeflection(req); err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondCreated(c, gin.H{"recorded": true})
+}
+
+func (handler *KnowledgeBaseHandler) listAdmin(c *gin.Context) {
+	page, limit := parsePageAndLimit(c, 20, 50)
+	result, err := handler.kb.ListAdmin(
+		c.Query("q"),
+		c.Query("categoryId"),
+		domain.ArticleStatus(c.Query("status")),
+		page,
+		limit,
+	)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondOK(c, result)
+}
+
+func (handler *KnowledgeBaseHandler) getAdmin(c *gin.Context) {
+	result, err := handler.kb.GetAdmin(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusNotFound, err.Error())
+		return
+	}
+	respondOK(c, result)
+}
+
+func (handler *KnowledgeBaseHandler) createArticle(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	var req service.ArticleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "payload tidak valid")
+		return
+	}
+	result, err := handler.kb.Create(c, user, req)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondCreated(c, result)
+}
+
+func (handler *KnowledgeBaseHandler) updateArticle(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	var req service.ArticleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "payload tidak valid")
+		return
+	}
+	result, err := handler.kb.Update(c, user, c.Param("id"), req)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondOK(c, result)
+}
+
+func (handler *KnowledgeBaseHandler) publishArticle(c *gin.Context) {
+	handler.setPublished(c, true)
+}
+
+func (handler *KnowledgeBaseHandler) unpublishArticle(c *gin.Context) {
+	handler.setPublished(c, false)
+}
+
+func (handler *KnowledgeBaseHandler) setPublished(c *gin.Context, published bool) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	result, err := handler.kb.SetPublished(c, user, c.Param("id"), published)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondOK(c, result)
+}
+
+func (handler *KnowledgeBaseHandler) deleteArticle(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	if err := handler.kb.Delete(c, user, c.Param("id")); err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondOK(c, gin.H{"deleted": true})
+}
+
+func (handler *KnowledgeBaseHandler) resolveTicket(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	var req service.TicketResolutionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "payload tidak valid")
+		return
+	}
+	result, err := handler.kb.ResolveTicket(c, user, c.Param("id"), req)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondOK(c, result)
+}
+
+func (handler *KnowledgeBaseHandler) promoteResolution(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	var req service.PromoteResolutionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, http.StatusBadRequest, "payload tidak valid")
+			return
+		}
+	}
+	result, err := handler.kb.PromoteResolution(c, user, c.Param("id"), req)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondCreated(c, result)
+}
@@ -48,7 +48,7 @@ func (handler *NotificationHandler) registerFcm(c *gin.Context) {
 		respondError(c, http.StatusBadRequest, "payload tidak valid")
 		return
 	}
-	if err := handler.notifications.RegisterToken(user, req); err != nil {
+	if err := handler.notifications.RegisterToken(user, middleware.GetSessionID(c), req); err != nil {
 		respondError(c, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"unila_helpdesk_backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OIDCHandler struct {
+	oidc *service.OIDCService
+}
+
+func NewOIDCHandler(oidc *service.OIDCService) *OIDCHandler {
+	return &OIDCHandler{oidc: oidc}
+}
+
+func (handler *OIDCHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/auth/oidc/authorize", handler.authorize)
+	router.POST("/auth/oidc/callback", handler.callback)
+}
+
+func (handler *OIDCHandler) authorize(c *gin.Context) {
+	if !handler.oidc.Enabled() {
+		respondError(c, http.StatusNotFound, "login SSO belum dikonfigurasi")
+		return
+	}
+	result, err := handler.oidc.Authorize(c, clientInfo(c, c.Query("device_name"), c.Query("platform")))
+	if err != nil {
+		respondError(c, http.StatusBadGateway, err.Error())
+		return
+	}
+	respondOK(c, result)
+}
+
+func (handler *OIDCHandler) callback(c *gin.Context) {
+	var req service.OIDCCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "payload tidak valid")
+		return
+	}
+	result, err := handler.oidc.Callback(c, req, clientInfo(c, "", ""))
+	if err != nil {
+		if errors.Is(err, service.ErrAdminWebOnly) {
+			respondError(c, http.StatusForbidden, err.Error())
+			return
+		}
+		respondError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+	respondOK(c, result)
+}
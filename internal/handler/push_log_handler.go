@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"net/http"
+
+	"unila_helpdesk_backend/internal/repository"
+	"unila_helpdesk_backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PushLogHandler struct {
+	pushLogs *service.PushLogService
+}
+
+func NewPushLogHandler(pushLogs *service.PushLogService) *PushLogHandler {
+	return &PushLogHandler{pushLogs: pushLogs}
+}
+
+func (handler *PushLogHandler) RegisterRoutes(admin *gin.RouterGroup) {
+	admin.GET("/admin/push-logs", handler.listLogs)
+}
+
+func (handler *PushLogHandler) listLogs(c *gin.Context) {
+	start, ok := parseOptionalTime(c, "start")
+	if !ok {
+		return
+	}
+	end, ok := parseOptionalTime(c, "end")
+	if !ok {
+		return
+	}
+	page, limit := parsePageAndLimit(c, 20, 100)
+
+	result, err := handler.pushLogs.List(repository.PushLogFilter{
+		UserID: c.Query("userId"),
+		Status: c.Query("status"),
+		Topic:  c.Query("topic"),
+		Start:  start,
+		End:    end,
+	}, page, limit)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondOK(c, result)
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"unila_helpdesk_backend/internal/domain"
 	"unila_helpdesk_backend/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -34,6 +35,8 @@ func (handler *ReportHandler) RegisterRoutes(admin *gin.RouterGroup) {
 	admin.GET("/reports/templates", handler.templatesByCategory)
 	admin.GET("/reports/usage", handler.usageCohort)
 	admin.GET("/reports/entity-service", handler.entityService)
+	admin.GET("/reports/kb-deflection", handler.knowledgeBaseDeflection)
+	admin.GET("/reports/kb-usage", handler.knowledgeBaseUsage)
 }
 
 func (handler *ReportHandler) dashboardSummary(c *gin.Context) {
@@ -67,7 +70,17 @@ func (handler *ReportHandler) serviceTrends(c *gin.Context) {
 			end = parsed.In(reportHandlerLocationWIB)
 		}
 	}
-	trends, err := handler.reports.ServiceTrends(start, end)
+	var trends []domain.ServiceTrendDTO
+	var err error
+	switch c.DefaultQuery("dimension", "category") {
+	case "category":
+		trends, err = handler.reports.ServiceTrends(start, end, c.Query("parentId"))
+	case "tag":
+		trends, err = handler.reports.ServiceTagTrends(start, end, c.Query("categoryId"))
+	default:
+		respondError(c, http.StatusBadRequest, "dimension tidak valid")
+		return
+	}
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, err.Error())
 		return
@@ -77,7 +90,7 @@ func (handler *ReportHandler) serviceTrends(c *gin.Context) {
 
 func (handler *ReportHandler) satisfactionSummary(c *gin.Context) {
 	period, periods := parsePeriodParams(c, 6)
-	rows, err := handler.reports.ServiceSatisfactionSummary(period, periods)
+	rows, err := handler.reports.ServiceSatisfactionSummary(period, periods, c.Query("parentId"))
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, err.Error())
 		return
@@ -198,6 +211,27 @@ func (handler *ReportHandler) entityService(c *gin.Context) {
 	respondOK(c, rows)
 }
 
+func (handler *ReportHandler) knowledgeBaseDeflection(c *gin.Context) {
+	period, periods := parsePeriodParams(c, 6)
+	report, err := handler.reports.KnowledgeBaseDeflection(period, periods)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondOK(c, report)
+}
+
+func (handler *ReportHandler) knowledgeBaseUsage(c *gin.Context) {
+	period, periods := parsePeriodParams(c, 6)
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	rows, err := handler.reports.KnowledgeBaseUsage(period, periods, c.Query("parentId"), limit)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondOK(c, rows)
+}
+
 func parsePeriodParams(c *gin.Context, defaultPeriods int) (string, int) {
 	periods := defaultPeriods
 	if raw := c.Query("periods"); raw != "" {
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"net/http"
+
+	"unila_helpdesk_backend/internal/middleware"
+	"unila_helpdesk_backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SessionHandler struct {
+	sessions *service.SessionService
+}
+
+type userActiveRequest struct {
+	Active *bool `json:"active"`
+}
+
+func NewSessionHandler(sessions *service.SessionService) *SessionHandler {
+	return &SessionHandler{sessions: sessions}
+}
+
+// RegisterRoutes memasang logout di grup public agar client dengan access token
+// kadaluarsa tetap bisa logout memakai refresh token.
+func (handler *SessionHandler) RegisterRoutes(public *gin.RouterGroup, auth *gin.RouterGroup) {
+	public.POST("/auth/logout", handler.logout)
+	auth.GET("/me/sessions", handler.listSessions)
+	auth.DELETE("/me/sessions/:id", handler.revokeSession)
+}
+
+func (handler *SessionHandler) RegisterAdminRoutes(admin *gin.RouterGroup) {
+	admin.POST("/admin/users/:id/logout", handler.forceLogout)
+	admin.PUT("/admin/users/:id/active", handler.setUserActive)
+}
+
+func (handler *SessionHandler) logout(c *gin.Context) {
+	var req service.LogoutRequest
+	// Body opsional: refresh token yang ikut dihapus dan flag all.
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, http.StatusBadRequest, "payload tidak valid")
+			return
+		}
+	}
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		if err := handler.sessions.LogoutWithRefreshToken(c, req); err != nil {
+			respondError(c, http.StatusUnauthorized, err.Error())
+			return
+		}
+		respondOK(c, gin.H{"loggedOut": true})
+		return
+	}
+	if err := handler.sessions.Logout(c, user, middleware.GetSessionID(c), req); err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondOK(c, gin.H{"loggedOut": true})
+}
+
+func (handler *SessionHandler) listSessions(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	result, err := handler.sessions.List(user, middleware.GetSessionID(c))
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondOK(c, result)
+}
+
+func (handler *SessionHandler) revokeSession(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	if err := handler.sessions.Revoke(c, user, c.Param("id")); err != nil {
+		respondError(c, http.StatusNotFound, err.Error())
+		return
+	}
+	respondOK(c, gin.H{"revoked": true})
+}
+
+func (handler *SessionHandler) forceLogout(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	if err := handler.sessions.ForceLogout(c, user, c.Param("id")); err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondOK(c, gin.H{"loggedOut": true})
+}
+
+func (handler *SessionHandler) setUserActive(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	var req userActiveRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Active == nil {
+		respondError(c, http.StatusBadRequest, "payload tidak valid")
+		return
+	}
+	result, err := handler.sessions.SetUserActive(c, user, c.Param("id"), *req.Active)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondOK(c, result)
+}
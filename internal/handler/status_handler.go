@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"net/http"
+
+	"unila_helpdesk_backend/internal/middleware"
+	"unila_helpdesk_backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StatusHandler struct {
+	status *service.StatusService
+}
+
+func NewStatusHandler(status *service.StatusService) *StatusHandler {
+	return &StatusHandler{status: status}
+}
+
+func (handler *StatusHandler) RegisterRoutes(api *gin.RouterGroup) {
+	api.GET("/status", handler.statusPage)
+	api.GET("/status/services/:id", handler.serviceStatus)
+}
+
+func (handler *StatusHandler) RegisterAdminRoutes(admin *gin.RouterGroup) {
+	admin.PUT("/status/services/:id", handler.setServiceState)
+}
+
+func (handler *StatusHandler) statusPage(c *gin.Context) {
+	result, err := handler.status.Page()
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondOK(c, result)
+}
+
+func (handler *StatusHandler) serviceStatus(c *gin.Context) {
+	result, err := handler.status.ServiceStatus(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusNotFound, err.Error())
+		return
+	}
+	respondOK(c, result)
+}
+
+func (handler *StatusHandler) setServiceState(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	var req service.ServiceStateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "payload tidak valid")
+		return
+	}
+	result, err := handler.status.SetState(c, user, c.Param("id"), req)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondOK(c, result)
+}
@@ -50,12 +50,17 @@ func (handler *SurveyHandler) templateByCategory(c *gin.Context) {
 }
 
 func (handler *SurveyHandler) createTemplate(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
 	var req service.SurveyTemplateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		respondError(c, http.StatusBadRequest, "payload tidak valid")
 		return
 	}
-	template, err := handler.surveys.CreateTemplate(req)
+	template, err := handler.surveys.CreateTemplate(c, user, req)
 	if err != nil {
 		respondError(c, http.StatusBadRequest, err.Error())
 		return
@@ -64,13 +69,18 @@ func (handler *SurveyHandler) createTemplate(c *gin.Context) {
 }
 
 func (handler *SurveyHandler) updateTemplate(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
 	templateID := c.Param("id")
 	var req service.SurveyTemplateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		respondError(c, http.StatusBadRequest, "payload tidak valid")
 		return
 	}
-	template, err := handler.surveys.UpdateTemplate(templateID, req)
+	template, err := handler.surveys.UpdateTemplate(c, user, templateID, req)
 	if err != nil {
 		respondError(c, http.StatusBadRequest, err.Error())
 		return
@@ -79,8 +89,13 @@ func (handler *SurveyHandler) updateTemplate(c *gin.Context) {
 }
 
 func (handler *SurveyHandler) deleteTemplate(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
 	templateID := c.Param("id")
-	if err := handler.surveys.DeleteTemplate(templateID); err != nil {
+	if err := handler.surveys.DeleteTemplate(c, user, templateID); err != nil {
 		respondError(c, http.StatusBadRequest, err.Error())
 		return
 	}
@@ -124,14 +139,25 @@ func (handler *SurveyHandler) listResponses(c *gin.Context) {
 	}
 
 	page, limit := parsePageAndLimit(c, 50, 0)
-
-	result, err := handler.surveys.ListResponsesPaged(repository.SurveyResponseFilter{
+	filter := repository.SurveyResponseFilter{
 		Query:      query,
 		CategoryID: categoryID,
 		TemplateID: templateID,
 		Start:      start,
 		End:        end,
-	}, page, limit)
+	}
+
+	if rawCursor, useCursor := c.GetQuery("cursor"); useCursor {
+		result, err := handler.surveys.ListResponsesCursor(filter, rawCursor, limit)
+		if err != nil {
+			respondCursorError(c, err)
+			return
+		}
+		respondOK(c, result)
+		return
+	}
+
+	result, err := handler.surveys.ListResponsesPaged(filter, page, limit)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, err.Error())
 		return
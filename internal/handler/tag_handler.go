@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"net/http"
+
+	"unila_helpdesk_backend/internal/middleware"
+	"unila_helpdesk_backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TagHandler struct {
+	tags *service.TagService
+}
+
+func NewTagHandler(tags *service.TagService) *TagHandler {
+	return &TagHandler{tags: tags}
+}
+
+func (handler *TagHandler) RegisterRoutes(admin *gin.RouterGroup) {
+	admin.GET("/tags", handler.listTags)
+	admin.POST("/tags", handler.createTag)
+	admin.PUT("/tags/:id", handler.updateTag)
+	admin.DELETE("/tags/:id", handler.deleteTag)
+	admin.POST("/tickets/:id/tags", handler.addTicketTags)
+	admin.DELETE("/tickets/:id/tags/:tagId", handler.removeTicketTag)
+}
+
+func (handler *TagHandler) listTags(c *gin.Context) {
+	result, err := handler.tags.List()
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondOK(c, result)
+}
+
+func (handler *TagHandler) createTag(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	var req service.TagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "payload tidak valid")
+		return
+	}
+	result, err := handler.tags.Create(c, user, req)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondCreated(c, result)
+}
+
+func (handler *TagHandler) updateTag(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	var req service.TagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "payload tidak valid")
+		return
+	}
+	result, err := handler.tags.Update(c, user, c.Param("id"), req)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondOK(c, result)
+}
+
+func (handler *TagHandler) deleteTag(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	if err := handler.tags.Delete(c, user, c.Param("id")); err != nil {
+		respondError(c, http.StatusNotFound, err.Error())
+		return
+	}
+	respondOK(c, gin.H{"deleted": true})
+}
+
+type ticketTagsRequest struct {
+	Tags []string `json:"tags"`
+}
+
+func (handler *TagHandler) addTicketTags(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	var req ticketTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "payload tidak valid")
+		return
+	}
+	result, err := handler.tags.AddToTicket(c, user, c.Param("id"), req.Tags)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondOK(c, result)
+}
+
+func (handler *TagHandler) removeTicketTag(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	result, err := handler.tags.RemoveFromTicket(c, user, c.Param("id"), c.Param("tagId"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondOK(c, result)
+}
@@ -3,6 +3,8 @@ package handler
 import (
 	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"unila_helpdesk_backend/internal/domain"
 	"unila_helpdesk_backend/internal/middleware"
@@ -20,6 +22,10 @@ type commentRequest struct {
 	Message string `json:"message"`
 }
 
+type deleteTicketRequest struct {
+	Reason string `json:"reason"`
+}
+
 func NewTicketHandler(tickets *service.TicketService) *TicketHandler {
 	return &TicketHandler{tickets: tickets}
 }
@@ -36,6 +42,13 @@ func (handler *TicketHandler) RegisterRoutes(public *gin.RouterGroup, auth *gin.
 	auth.POST("/tickets/:id/comments", handler.addComment)
 }
 
+func (handler *TicketHandler) RegisterAdminRoutes(admin *gin.RouterGroup) {
+	admin.POST("/tickets/bulk", handler.bulkUpdate)
+	admin.GET("/tickets/trash", handler.listTrash)
+	admin.POST("/tickets/trash/:id/restore", handler.restoreTicket)
+	admin.DELETE("/tickets/trash/:id", handler.purgeTicket)
+}
+
 func (handler *TicketHandler) listTickets(c *gin.Context) {
 	user, ok := middleware.GetUser(c)
 	if !ok {
@@ -57,38 +70,33 @@ func (handler *TicketHandler) listTicketsPaged(c *gin.Context) {
 		return
 	}
 
-	query := c.Query("q")
-	categoryID := c.Query("categoryId")
-	statusRaw := c.Query("status")
-
-	var status *domain.TicketStatus
-	if statusRaw != "" {
-		parsed, err := parseTicketStatus(statusRaw)
-		if err != nil {
-			respondError(c, http.StatusBadRequest, err.Error())
-			return
-		}
-		status = &parsed
-	}
-
-	start, ok := parseOptionalTime(c, "start")
+	filter, ok := parseTicketListFilter(c)
 	if !ok {
 		return
 	}
-	end, ok := parseOptionalTime(c, "end")
+	sort, ok := parseTicketListSort(c)
 	if !ok {
 		return
 	}
 
 	page, limit := parsePageAndLimit(c, 15, 50)
 
-	result, err := handler.tickets.ListTicketsPaged(user, repository.TicketListFilter{
-		Query:      query,
-		Status:     status,
-		CategoryID: categoryID,
-		Start:      start,
-		End:        end,
-	}, page, limit)
+	// Mode cursor aktif jika parameter cursor dikirim (kosong untuk halaman pertama).
+	if rawCursor, useCursor := c.GetQuery("cursor"); useCursor {
+		if sort.Field != repository.TicketSortCreated {
+			respondError(c, http.StatusBadRequest, "cursor hanya mendukung sort created")
+			return
+		}
+		result, err := handler.tickets.ListTicketsCursor(user, filter, sort.Asc, rawCursor, limit)
+		if err != nil {
+			respondCursorError(c, err)
+			return
+		}
+		respondOK(c, result)
+		return
+	}
+
+	result, err := handler.tickets.ListTicketsPaged(user, filter, sort, page, limit)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, err.Error())
 		return
@@ -96,6 +104,104 @@ func (handler *TicketHandler) listTicketsPaged(c *gin.Context) {
 	respondOK(c, result)
 }
 
+// parseTicketListFilter membaca filter daftar tiket dari query string.
+// Status dan prioritas menerima beberapa nilai dipisah koma.
+func parseTicketListFilter(c *gin.Context) (repository.TicketListFilter, bool) {
+	filter := repository.TicketListFilter{
+		Query:          c.Query("q"),
+		CategoryID:     c.Query("categoryId"),
+		Assignee:       strings.TrimSpace(c.Query("assignee")),
+		ReporterEntity: strings.TrimSpace(c.Query("entity")),
+	}
+
+	for _, raw := range splitQueryList(c.Query("tag")) {
+		filter.Tags = append(filter.Tags, strings.ToLower(raw))
+	}
+
+	// Filter field kustom memakai format field[npm]=2015061001.
+	for key, value := range c.QueryMap("field") {
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		if key == "" || value == "" {
+			continue
+		}
+		if filter.CustomFields == nil {
+			filter.CustomFields = map[string]string{}
+		}
+		filter.CustomFields[key] = value
+	}
+
+	for _, raw := range splitQueryList(c.Query("status")) {
+		parsed, err := parseTicketStatus(raw)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, err.Error())
+			return filter, false
+		}
+		filter.Statuses = append(filter.Statuses, parsed)
+	}
+	for _, raw := range splitQueryList(c.Query("priority")) {
+		parsed, err := parseTicketPriority(raw)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, err.Error())
+			return filter, false
+		}
+		filter.Priorities = append(filter.Priorities, parsed)
+	}
+
+	var ok bool
+	if filter.IsGuest, ok = parseOptionalBool(c, "guest"); !ok {
+		return filter, false
+	}
+	if filter.HasSurvey, ok = parseOptionalBool(c, "hasSurvey"); !ok {
+		return filter, false
+	}
+	if filter.SLABreached, ok = parseOptionalBool(c, "slaBreached"); !ok {
+		return filter, false
+	}
+	if filter.Start, ok = parseOptionalTime(c, "start"); !ok {
+		return filter, false
+	}
+	if filter.End, ok = parseOptionalTime(c, "end"); !ok {
+		return filter, false
+	}
+	if raw := c.Query("olderThanHours"); raw != "" {
+		hours, err := strconv.Atoi(raw)
+		if err != nil || hours < 0 {
+			respondError(c, http.StatusBadRequest, "olderThanHours tidak valid")
+			return filter, false
+		}
+		filter.OlderThanHours = hours
+	}
+	return filter, true
+}
+
+func parseTicketListSort(c *gin.Context) (repository.TicketListSort, bool) {
+	sort := repository.TicketListSort{Field: repository.TicketSortCreated}
+	switch c.DefaultQuery("sort", "created") {
+	case "created", "createdAt":
+		sort.Field = repository.TicketSortCreated
+	case "updated", "updatedAt":
+		sort.Field = repository.TicketSortUpdated
+	case "priority":
+		sort.Field = repository.TicketSortPriority
+	case "due", "dueAt":
+		sort.Field = repository.TicketSortDue
+	default:
+		respondError(c, http.StatusBadRequest, "sort tidak valid")
+		return sort, false
+	}
+	switch strings.ToLower(c.DefaultQuery("order", "desc")) {
+	case "asc":
+		sort.Asc = true
+	case "desc":
+		sort.Asc = false
+	default:
+		respondError(c, http.StatusBadRequest, "order tidak valid")
+		return sort, false
+	}
+	return sort, true
+}
+
 func parseTicketStatus(raw string) (domain.TicketStatus, error) {
 	switch raw {
 	case string(domain.StatusWaiting):
@@ -111,6 +217,18 @@ func parseTicketStatus(raw string) (domain.TicketStatus, error) {
 	return "", errors.New("status tiket tidak valid")
 }
 
+func parseTicketPriority(raw string) (domain.TicketPriority, error) {
+	switch raw {
+	case string(domain.PriorityLow):
+		return domain.PriorityLow, nil
+	case string(domain.PriorityMedium):
+		return domain.PriorityMedium, nil
+	case string(domain.PriorityHigh):
+		return domain.PriorityHigh, nil
+	}
+	return "", errors.New("prioritas tiket tidak valid")
+}
+
 func (handler *TicketHandler) searchTickets(c *gin.Context) {
 	query := c.Query("q")
 	user, hasUser := middleware.GetUser(c)
@@ -200,7 +318,15 @@ func (handler *TicketHandler) deleteTicket(c *gin.Context) {
 		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
 		return
 	}
-	if err := handler.tickets.DeleteTicket(user, c.Param("id")); err != nil {
+	// Alasan bersifat opsional; klien lama mengirim body kosong.
+	var req deleteTicketRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, http.StatusBadRequest, "payload tidak valid")
+			return
+		}
+	}
+	if err := handler.tickets.DeleteTicket(c, user, c.Param("id"), req.Reason); err != nil {
 		respondError(c, http.StatusBadRequest, err.Error())
 		return
 	}
@@ -218,10 +344,70 @@ func (handler *TicketHandler) addComment(c *gin.Context) {
 		respondError(c, http.StatusBadRequest, "payload tidak valid")
 		return
 	}
-	result, err := handler.tickets.AddComment(user, c.Param("id"), req.Message)
+	result, err := handler.tickets.AddComment(c, user, c.Param("id"), req.Message)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondOK(c, result)
+}
+
+func (handler *TicketHandler) bulkUpdate(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	var req service.TicketBulkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "payload tidak valid")
+		return
+	}
+	result, err := handler.tickets.BulkUpdate(c, user, req)
 	if err != nil {
 		respondError(c, http.StatusBadRequest, err.Error())
 		return
 	}
 	respondOK(c, result)
 }
+
+func (handler *TicketHandler) listTrash(c *gin.Context) {
+	page, limit := parsePageAndLimit(c, 15, 50)
+	result, err := handler.tickets.ListDeletedTickets(c.Query("q"), page, limit)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondOK(c, result)
+}
+
+func (handler *TicketHandler) restoreTicket(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	result, err := handler.tickets.RestoreTicket(c, user, c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusNotFound, err.Error())
+		return
+	}
+	respondOK(c, result)
+}
+
+func (handler *TicketHandler) purgeTicket(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	if err := handler.tickets.PurgeTicket(c, user, c.Param("id")); err != nil {
+		if errors.Is(err, service.ErrTicketNotInTrash) {
+			respondError(c, http.StatusNotFound, err.Error())
+			return
+		}
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondOK(c, gin.H{"purged": true})
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"unila_helpdesk_backend/internal/middleware"
+	"unila_helpdesk_backend/internal/service"
+	"unila_helpdesk_backend/internal/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TicketViewHandler struct {
+	views *service.TicketViewService
+}
+
+func NewTicketViewHandler(views *service.TicketViewService) *TicketViewHandler {
+	return &TicketViewHandler{views: views}
+}
+
+func (handler *TicketViewHandler) RegisterRoutes(admin *gin.RouterGroup) {
+	admin.GET("/tickets/views", handler.listViews)
+	admin.POST("/tickets/views", handler.createView)
+	admin.GET("/tickets/views/:id", handler.executeView)
+	admin.PUT("/tickets/views/:id", handler.updateView)
+	admin.DELETE("/tickets/views/:id", handler.deleteView)
+}
+
+func (handler *TicketViewHandler) listViews(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	result, err := handler.views.List(user)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondOK(c, result)
+}
+
+func (handler *TicketViewHandler) createView(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	var req service.TicketViewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "payload tidak valid")
+		return
+	}
+	result, err := handler.views.Create(user, req)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondCreated(c, result)
+}
+
+func (handler *TicketViewHandler) updateView(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	var req service.TicketViewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "payload tidak valid")
+		return
+	}
+	result, err := handler.views.Update(user, c.Param("id"), req)
+	if err != nil {
+		respondTicketViewError(c, err, http.StatusBadRequest)
+		return
+	}
+	respondOK(c, result)
+}
+
+func (handler *TicketViewHandler) deleteView(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	if err := handler.views.Delete(user, c.Param("id")); err != nil {
+		respondTicketViewError(c, err, http.StatusInternalServerError)
+		return
+	}
+	respondOK(c, gin.H{"deleted": true})
+}
+
+func (handler *TicketViewHandler) executeView(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	page, limit := parsePageAndLimit(c, 15, 50)
+	var rawCursor *string
+	if value, useCursor := c.GetQuery("cursor"); useCursor {
+		rawCursor = &value
+	}
+	result, err := handler.views.Execute(user, c.Param("id"), page, limit, rawCursor)
+	if err != nil {
+		respondTicketViewError(c, err, http.StatusInternalServerError)
+		return
+	}
+	respondOK(c, result)
+}
+
+// respondTicketViewError memetakan error view ke status HTTP; error lain memakai
+// fallback, kecuali cursor tidak valid yang selalu 400.
+func respondTicketViewError(c *gin.Context, err error, fallback int) {
+	switch {
+	case errors.Is(err, service.ErrTicketViewNotFound):
+		respondError(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrTicketViewForbidden):
+		respondError(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrTicketViewCursorSort), errors.Is(err, util.ErrInvalidCursor):
+		respondError(c, http.StatusBadRequest, err.Error())
+	default:
+		respondError(c, fallback, err.Error())
+	}
+}
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"net/http"
+
+	"unila_helpdesk_backend/internal/middleware"
+	"unila_helpdesk_backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebPushHandler struct {
+	webPush *service.WebPushService
+}
+
+type webPushUnregisterRequest struct {
+	Endpoint string `json:"endpoint"`
+}
+
+func NewWebPushHandler(webPush *service.WebPushService) *WebPushHandler {
+	return &WebPushHandler{webPush: webPush}
+}
+
+func (handler *WebPushHandler) RegisterRoutes(api *gin.RouterGroup) {
+	api.GET("/webpush/public-key", handler.publicKey)
+}
+
+func (handler *WebPushHandler) RegisterAdminRoutes(admin *gin.RouterGroup) {
+	admin.GET("/webpush/subscriptions", handler.listSubscriptions)
+	admin.POST("/webpush/subscriptions", handler.subscribe)
+	admin.POST("/webpush/subscriptions/unregister", handler.unsubscribe)
+	admin.GET("/webpush/preferences", handler.getPreference)
+	admin.PUT("/webpush/preferences", handler.updatePreference)
+}
+
+func (handler *WebPushHandler) publicKey(c *gin.Context) {
+	respondOK(c, gin.H{
+		"enabled":   handler.webPush.Enabled(),
+		"publicKey": handler.webPush.PublicKey(),
+	})
+}
+
+func (handler *WebPushHandler) listSubscriptions(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	result, err := handler.webPush.ListSubscriptions(user)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondOK(c, result)
+}
+
+func (handler *WebPushHandler) subscribe(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	var req service.WebPushSubscribeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "payload tidak valid")
+		return
+	}
+	result, err := handler.webPush.Subscribe(user, req, c.Request.UserAgent())
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondCreated(c, result)
+}
+
+func (handler *WebPushHandler) unsubscribe(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	var req webPushUnregisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "payload tidak valid")
+		return
+	}
+	if err := handler.webPush.Unsubscribe(user, req.Endpoint); err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondOK(c, gin.H{"unregistered": true})
+}
+
+func (handler *WebPushHandler) getPreference(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	result, err := handler.webPush.GetPreference(user)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondOK(c, result)
+}
+
+func (handler *WebPushHandler) updatePreference(c *gin.Context) {
+	user, ok := middleware.GetUser(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "token dibutuhkan")
+		return
+	}
+	var req service.WebPushPreferenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "payload tidak valid")
+		return
+	}
+	result, err := handler.webPush.UpdatePreference(user, req)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondOK(c, result)
+}
@@ -0,0 +1,236 @@
+// Package jwtkeys memuat kunci penandatangan JWT asimetris (RS256 atau EdDSA) dari
+// direktori, memilih kunci verifikasi berdasarkan kid, dan menyajikan JWKS.
+//
+// Setiap file <kid>.pem di direktori kunci adalah satu kunci. Kunci aktif dipakai
+// untuk menandatangani token baru; kunci lain tetap dipakai untuk verifikasi dan
+// dipublikasikan di JWKS sampai file-nya dihapus.
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+type Key struct {
+	ID        string
+	Algorithm string
+	Public    crypto.PublicKey
+	private   crypto.Signer
+}
+
+func (key Key) SigningMethod() jwt.SigningMethod {
+	if key.Algorithm == AlgEdDSA {
+		return jwt.SigningMethodEdDSA
+	}
+	return jwt.SigningMethodRS256
+}
+
+type KeySet struct {
+	active Key
+	keys   map[string]Key
+}
+
+// JWK adalah representasi kunci publik sesuai RFC 7517.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// LoadDir membaca seluruh file *.pem di dir. Nama file tanpa ekstensi menjadi kid.
+// activeID memilih kunci penandatangan; kosong berarti kid terakhir secara leksikografis
+// sehingga penamaan berbasis tanggal (mis. 2026-10-18.pem) langsung memilih kunci terbaru.
+func LoadDir(dir string, activeID string) (*KeySet, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("tidak ada kunci JWT di %s", dir)
+	}
+	sort.Strings(paths)
+
+	set := &KeySet{keys: make(map[string]Key, len(paths))}
+	for _, path := range paths {
+		kid := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		key, err := parseKey(kid, raw)
+		if err != nil {
+			return nil, fmt.Errorf("kunci %s: %w", path, err)
+		}
+		set.keys[kid] = key
+		if activeID == "" && key.private != nil {
+			set.active = key
+		}
+	}
+	if activeID != "" {
+		key, ok := set.keys[activeID]
+		if !ok {
+			return nil, fmt.Errorf("kunci aktif %s tidak ditemukan", activeID)
+		}
+		set.active = key
+	}
+	if set.active.private == nil {
+		return nil, errors.New("kunci aktif harus berupa private key")
+	}
+	return set, nil
+}
+
+func (set *KeySet) Active() Key {
+	return set.active
+}
+
+// Sign menandatangani claims dengan kunci aktif dan mengisi header kid.
+func (set *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(set.active.SigningMethod(), claims)
+	token.Header["kid"] = set.active.ID
+	return token.SignedString(set.active.private)
+}
+
+// Lookup mencari kunci verifikasi berdasarkan header kid dan memastikan algoritma
+// token sesuai jenis kunci.
+func (set *KeySet) Lookup(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := set.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("kid %q tidak dikenal", kid)
+	}
+	if token.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("algoritma %s tidak cocok dengan kid %s", token.Method.Alg(), kid)
+	}
+	return key.Public, nil
+}
+
+// Algorithms mengembalikan algoritma yang dipakai kunci-kunci di set.
+func (set *KeySet) Algorithms() []string {
+	algorithms := make([]string, 0, 2)
+	for _, key := range set.keys {
+		if !slices.Contains(algorithms, key.Algorithm) {
+			algorithms = append(algorithms, key.Algorithm)
+		}
+	}
+	sort.Strings(algorithms)
+	return algorithms
+}
+
+func (set *KeySet) JWKS() JWKS {
+	ids := make([]string, 0, len(set.keys))
+	for id := range set.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	result := JWKS{Keys: make([]JWK, 0, len(ids))}
+	for _, id := range ids {
+		key := set.keys[id]
+		jwk := JWK{KeyID: key.ID, Use: "sig", Algorithm: key.Algorithm}
+		switch public := key.Public.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		}
+		result.Keys = append(result.Keys, jwk)
+	}
+	return result
+}
+
+// parseKey menerima PKCS#8/PKCS#1 private key atau PKIX public key. File public key
+// hanya bisa memverifikasi, cocok untuk kunci lama yang private key-nya sudah dibuang.
+func parseKey(kid string, raw []byte) (Key, error) {
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return Key{}, errors.New("format PEM tidak valid")
+	}
+	var parsed any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return Key{}, fmt.Errorf("tipe PEM %s tidak didukung", block.Type)
+	}
+	if err != nil {
+		return Key{}, err
+	}
+
+	switch value := parsed.(type) {
+	case *rsa.PrivateKey:
+		if value.N.BitLen() < 2048 {
+			return Key{}, errors.New("kunci RSA minimal 2048 bit")
+		}
+		return Key{ID: kid, Algorithm: AlgRS256, Public: &value.PublicKey, private: value}, nil
+	case ed25519.PrivateKey:
+		return Key{ID: kid, Algorithm: AlgEdDSA, Public: value.Public(), private: value}, nil
+	case *rsa.PublicKey:
+		return Key{ID: kid, Algorithm: AlgRS256, Public: value}, nil
+	case ed25519.PublicKey:
+		return Key{ID: kid, Algorithm: AlgEdDSA, Public: value}, nil
+	}
+	return Key{}, errors.New("hanya kunci RSA dan Ed25519 yang didukung")
+}
+
+// Generate membuat private key baru dalam format PEM PKCS#8.
+func Generate(algorithm string) ([]byte, error) {
+	var private any
+	switch algorithm {
+	case AlgEdDSA:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		private = key
+	case AlgRS256:
+		key, err := rsa.GenerateKey(rand.Reader, 3072)
+		if err != nil {
+			return nil, err
+		}
+		private = key
+	default:
+		return nil, fmt.Errorf("algoritma %s tidak didukung", algorithm)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
@@ -0,0 +1,198 @@
+// Package ldapauth mengautentikasi user ke LDAP/Active Directory kampus: cari entry
+// user dengan akun layanan (search-then-bind), lalu bind ulang sebagai DN user untuk
+// memverifikasi password dan membaca atributnya.
+package ldapauth
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// ErrInvalidCredentials berarti user tidak ditemukan, ambigu, atau password salah.
+var ErrInvalidCredentials = errors.New("username atau password LDAP salah")
+
+type Config struct {
+	URL                string
+	StartTLS           bool
+	InsecureSkipVerify bool
+	// BindDN kosong berarti pencarian user dilakukan secara anonim.
+	BindDN       string
+	BindPassword string
+	BaseDN       string
+	// UserFilter memakai %s untuk username yang sudah di-escape, mis. "(uid=%s)".
+	UserFilter string
+	Attributes []string
+	Timeout    time.Duration
+}
+
+// Entry adalah entry user hasil autentikasi. Nama atribut disimpan lowercase.
+type Entry struct {
+	DN         string
+	Attributes map[string][]string
+	raw        map[string][][]byte
+}
+
+type Client struct {
+	config Config
+}
+
+func NewClient(config Config) *Client {
+	if strings.TrimSpace(config.UserFilter) == "" {
+		config.UserFilter = "(uid=%s)"
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 5 * time.Second
+	}
+	return &Client{config: config}
+}
+
+// Authenticate mengembalikan ErrInvalidCredentials untuk kegagalan kredensial;
+// error lain berarti server LDAP tidak dapat dipakai.
+func (client *Client) Authenticate(ctx context.Context, username string, password string) (*Entry, error) {
+	// Bind dengan password kosong adalah unauthenticated bind yang selalu berhasil
+	// di banyak server, jadi ditolak sebelum menghubungi LDAP.
+	if strings.TrimSpace(username) == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+	conn, err := client.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	if client.config.BindDN != "" {
+		if err := conn.Bind(client.config.BindDN, client.config.BindPassword); err != nil {
+			return nil, fmt.Errorf("bind akun layanan LDAP gagal: %w", err)
+		}
+	}
+	result, err := conn.Search(ldap.NewSearchRequest(
+		client.config.BaseDN,
+		ldap.ScopeWholeSubtree,
+		ldap.NeverDerefAliases,
+		2,
+		int(client.config.Timeout/time.Second),
+		false,
+		fmt.Sprintf(client.config.UserFilter, ldap.EscapeFilter(username)),
+		client.config.Attributes,
+		nil,
+	))
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, fmt.Errorf("pencarian user LDAP gagal: %w", err)
+	}
+	if len(result.Entries) != 1 {
+		return nil, ErrInvalidCredentials
+	}
+
+	found := result.Entries[0]
+	if err := conn.Bind(found.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("bind user LDAP gagal: %w", err)
+	}
+
+	entry := &Entry{
+		DN:         found.DN,
+		Attributes: make(map[string][]string, len(found.Attributes)),
+		raw:        make(map[string][][]byte, len(found.Attributes)),
+	}
+	for _, attribute := range found.Attributes {
+		name := strings.ToLower(attribute.Name)
+		entry.Attributes[name] = append(entry.Attributes[name], attribute.Values...)
+		entry.raw[name] = append(entry.raw[name], attribute.ByteValues...)
+	}
+	return entry, nil
+}
+
+func (client *Client) dial() (*ldap.Conn, error) {
+	tlsConfig := &tls.Config{
+		ServerName:         hostName(client.config.URL),
+		InsecureSkipVerify: client.config.InsecureSkipVerify,
+	}
+	conn, err := ldap.DialURL(
+		client.config.URL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: client.config.Timeout}),
+		ldap.DialWithTLSConfig(tlsConfig),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("koneksi LDAP gagal: %w", err)
+	}
+	conn.SetTimeout(client.config.Timeout)
+	if client.config.StartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("StartTLS LDAP gagal: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+func hostName(rawURL string) string {
+	_, rest, found := strings.Cut(rawURL, "://")
+	if !found {
+		rest = rawURL
+	}
+	host, _, _ := strings.Cut(rest, "/")
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		return name
+	}
+	return host
+}
+
+// First mengembalikan nilai pertama atribut atau string kosong.
+func (entry *Entry) First(name string) string {
+	values := entry.Values(name)
+	if len(values) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(values[0])
+}
+
+func (entry *Entry) Values(name string) []string {
+	if name == "" {
+		return nil
+	}
+	return entry.Attributes[strings.ToLower(name)]
+}
+
+// ID mengembalikan atribut identitas permanen (mis. entryUUID atau objectGUID AD).
+// Nilai biner di-encode hex; tanpa atribut tersebut DN dipakai sebagai gantinya.
+func (entry *Entry) ID(name string) string {
+	values := entry.raw[strings.ToLower(name)]
+	if name == "" || len(values) == 0 || len(values[0]) == 0 {
+		return strings.ToLower(entry.DN)
+	}
+	if utf8.Valid(values[0]) {
+		return strings.ToLower(strings.TrimSpace(string(values[0])))
+	}
+	return hex.EncodeToString(values[0])
+}
+
+// GroupNames mengembalikan DN grup (mis. dari memberOf) beserta nilai RDN
+// pertamanya, sehingga "cn=helpdesk-admin,ou=groups,..." cocok dengan "helpdesk-admin".
+func GroupNames(values []string) []string {
+	names := make([]string, 0, len(values)*2)
+	for _, value := range values {
+		names = append(names, value)
+		parsed, err := ldap.ParseDN(value)
+		if err != nil || len(parsed.RDNs) == 0 || len(parsed.RDNs[0].Attributes) == 0 {
+			continue
+		}
+		names = append(names, parsed.RDNs[0].Attributes[0].Value)
+	}
+	return names
+}
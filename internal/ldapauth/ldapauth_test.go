@@ -0,0 +1,122 @@
+package ldapauth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"unila_helpdesk_backend/internal/ldapauth/ldaptest"
+)
+
+const testBaseDN = "dc=unila,dc=local"
+
+// newTestClient menjalankan directory dengan akun layanan dan satu user. Hanya ada
+// satu entry ber-uid sehingga filter "(uid=*)" yang tidak di-escape akan cocok
+// dengan budi dan bind dengan password yang benar akan berhasil.
+func newTestClient(t *testing.T) *Client {
+	t.Helper()
+	server, err := ldaptest.NewServer([]ldaptest.Entry{
+		{
+			DN: "cn=helpdesk,ou=services," + testBaseDN,
+			Attributes: map[string][]string{
+				"cn":           {"helpdesk"},
+				"userPassword": {"helpdesk"},
+			},
+		},
+		{
+			DN: "uid=budi,ou=people," + testBaseDN,
+			Attributes: map[string][]string{
+				"objectClass":  {"inetOrgPerson"},
+				"uid":          {"budi"},
+				"cn":           {"Budi Santoso"},
+				"mail":         {"budi@unila.local"},
+				"entryUUID":    {"3F1C2A90-0001-4C1E-9A51-6D1F4B8E0001"},
+				"memberOf":     {"cn=helpdesk-admin,ou=groups," + testBaseDN},
+				"userPassword": {"rahasia"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("start ldap: %v", err)
+	}
+	t.Cleanup(func() { _ = server.Close() })
+	return NewClient(Config{
+		URL:          server.URL,
+		BindDN:       "cn=helpdesk,ou=services," + testBaseDN,
+		BindPassword: "helpdesk",
+		BaseDN:       testBaseDN,
+		UserFilter:   "(uid=%s)",
+		Attributes:   []string{"uid", "cn", "mail", "entryUUID", "memberOf"},
+		Timeout:      2 * time.Second,
+	})
+}
+
+func TestAuthenticateSuccess(t *testing.T) {
+	client := newTestClient(t)
+	entry, err := client.Authenticate(context.Background(), "budi", "rahasia")
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if entry.DN != "uid=budi,ou=people,"+testBaseDN {
+		t.Errorf("DN = %q", entry.DN)
+	}
+	if got := entry.First("MAIL"); got != "budi@unila.local" {
+		t.Errorf("mail = %q", got)
+	}
+	if got := entry.ID("entryUUID"); got != "3f1c2a90-0001-4c1e-9a51-6d1f4b8e0001" {
+		t.Errorf("ID = %q", got)
+	}
+	if _, ok := entry.Attributes["userpassword"]; ok {
+		t.Error("userPassword tidak boleh dikembalikan")
+	}
+	groups := GroupNames(entry.Values("memberOf"))
+	if len(groups) != 2 || groups[1] != "helpdesk-admin" {
+		t.Errorf("GroupNames = %v", groups)
+	}
+}
+
+func TestAuthenticateRejectsInvalidCredentials(t *testing.T) {
+	client := newTestClient(t)
+	cases := []struct {
+		name     string
+		username string
+		password string
+	}{
+		{name: "password salah", username: "budi", password: "salah"},
+		{name: "user tidak ada", username: "siti", password: "rahasia"},
+		{name: "password kosong", username: "budi", password: ""},
+		{name: "username kosong", username: " ", password: "rahasia"},
+		{name: "injeksi filter", username: "budi)(uid=*", password: "rahasia"},
+		{name: "wildcard", username: "*", password: "rahasia"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := client.Authenticate(context.Background(), tc.username, tc.password)
+			if !errors.Is(err, ErrInvalidCredentials) {
+				t.Fatalf("err = %v, want ErrInvalidCredentials", err)
+			}
+		})
+	}
+}
+
+// Password kosong harus ditolak sebelum menghubungi server, karena banyak server
+// menganggap bind tanpa password sebagai unauthenticated bind yang berhasil.
+func TestAuthenticateEmptyPasswordSkipsServer(t *testing.T) {
+	client := NewClient(Config{URL: "ldap://127.0.0.1:1", BaseDN: testBaseDN, Timeout: time.Second})
+	if _, err := client.Authenticate(context.Background(), "budi", ""); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("err = %v, want ErrInvalidCredentials", err)
+	}
+	if _, err := client.Authenticate(context.Background(), "budi", "rahasia"); err == nil || errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("err = %v, want error koneksi", err)
+	}
+}
+
+func TestAuthenticateServiceBindFailure(t *testing.T) {
+	client := newTestClient(t)
+	client.config.BindPassword = "salah"
+	_, err := client.Authenticate(context.Background(), "budi", "rahasia")
+	if err == nil || errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("err = %v, want error akun layanan", err)
+	}
+}
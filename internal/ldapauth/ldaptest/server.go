@@ -0,0 +1,329 @@
+// Package ldaptest adalah server LDAP in-memory untuk menguji login LDAP tanpa
+// directory kampus. Hanya simple bind, search, dan unbind yang didukung; filter
+// and/or/not/equality/substring/present dicocokkan tanpa membedakan huruf besar.
+package ldaptest
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+)
+
+// Entry adalah entry directory. Atribut userPassword berisi password plaintext
+// dan tidak pernah dikembalikan dalam hasil search.
+type Entry struct {
+	DN         string
+	Attributes map[string][]string
+}
+
+type Server struct {
+	// URL alamat server, mis. "ldap://127.0.0.1:38913".
+	URL string
+
+	listener net.Listener
+	entries  []Entry
+	wg       sync.WaitGroup
+}
+
+// NewServer menjalankan server pada port acak di localhost.
+func NewServer(entries []Entry) (*Server, error) {
+	return Listen("127.0.0.1:0", entries)
+}
+
+func Listen(addr string, entries []Entry) (*Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	server := &Server{
+		URL:      "ldap://" + listener.Addr().String(),
+		listener: listener,
+		entries:  entries,
+	}
+	server.wg.Add(1)
+	go server.serve()
+	return server, nil
+}
+
+func (server *Server) Close() error {
+	err := server.listener.Close()
+	server.wg.Wait()
+	return err
+}
+
+func (server *Server) serve() {
+	defer server.wg.Done()
+	for {
+		conn, err := server.listener.Accept()
+		if err != nil {
+			return
+		}
+		go server.handle(conn)
+	}
+}
+
+func (server *Server) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		packet, err := ber.ReadPacket(reader)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+		messageID, _ := packet.Children[0].Value.(int64)
+		request := packet.Children[1]
+		if request.ClassType != ber.ClassApplication {
+			return
+		}
+		var responses []*ber.Packet
+		switch request.Tag {
+		case ldap.ApplicationBindRequest:
+			responses = []*ber.Packet{server.bind(request)}
+		case ldap.ApplicationSearchRequest:
+			responses = server.search(request)
+		case ldap.ApplicationUnbindRequest:
+			return
+		case ldap.ApplicationExtendedRequest:
+			responses = []*ber.Packet{result(ldap.ApplicationExtendedResponse, ldap.LDAPResultProtocolError, "operasi tidak didukung")}
+		default:
+			return
+		}
+		for _, response := range responses {
+			envelope := ber.NewSequence("LDAP Response")
+			envelope.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, "MessageID"))
+			envelope.AppendChild(response)
+			if _, err := conn.Write(envelope.Bytes()); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (server *Server) bind(request *ber.Packet) *ber.Packet {
+	if len(request.Children) < 3 || request.Children[2].Tag != 0 {
+		return result(ldap.ApplicationBindResponse, ldap.LDAPResultAuthMethodNotSupported, "hanya simple bind")
+	}
+	name := packetString(request.Children[1])
+	password := packetString(request.Children[2])
+	if name == "" && password == "" {
+		return result(ldap.ApplicationBindResponse, ldap.LDAPResultSuccess, "")
+	}
+	entry := server.find(name)
+	if entry == nil || password == "" || !contains(entry.Attributes["userPassword"], password, false) {
+		return result(ldap.ApplicationBindResponse, ldap.LDAPResultInvalidCredentials, "")
+	}
+	return result(ldap.ApplicationBindResponse, ldap.LDAPResultSuccess, "")
+}
+
+func (server *Server) search(request *ber.Packet) []*ber.Packet {
+	if len(request.Children) < 8 {
+		return []*ber.Packet{result(ldap.ApplicationSearchResultDone, ldap.LDAPResultProtocolError, "search request tidak valid")}
+	}
+	baseDN := normalizeDN(packetString(request.Children[0]))
+	scope, _ := request.Children[1].Value.(int64)
+	sizeLimit, _ := request.Children[3].Value.(int64)
+	filter := request.Children[6]
+	requested := make([]string, 0, len(request.Children[7].Children))
+	for _, attribute := range request.Children[7].Children {
+		requested = append(requested, packetString(attribute))
+	}
+
+	responses := make([]*ber.Packet, 0)
+	for index := range server.entries {
+		entry := &server.entries[index]
+		if !inScope(normalizeDN(entry.DN), baseDN, scope) {
+			continue
+		}
+		matched, err := matches(entry, filter)
+		if err != nil {
+			return []*ber.Packet{result(ldap.ApplicationSearchResultDone, ldap.LDAPResultProtocolError, err.Error())}
+		}
+		if !matched {
+			continue
+		}
+		if sizeLimit > 0 && int64(len(responses)) >= sizeLimit {
+			return append(responses, result(ldap.ApplicationSearchResultDone, ldap.LDAPResultSizeLimitExceeded, ""))
+		}
+		responses = append(responses, searchEntry(entry, requested))
+	}
+	return append(responses, result(ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess, ""))
+}
+
+func (server *Server) find(dn string) *Entry {
+	normalized := normalizeDN(dn)
+	for index := range server.entries {
+		if normalizeDN(server.entries[index].DN) == normalized {
+			return &server.entries[index]
+		}
+	}
+	return nil
+}
+
+func inScope(dn string, baseDN string, scope int64) bool {
+	switch scope {
+	case ldap.ScopeBaseObject:
+		return dn == baseDN
+	case ldap.ScopeSingleLevel:
+		_, parent, _ := strings.Cut(dn, ",")
+		return parent == baseDN
+	default:
+		return baseDN == "" || dn == baseDN || strings.HasSuffix(dn, ","+baseDN)
+	}
+}
+
+func matches(entry *Entry, filter *ber.Packet) (bool, error) {
+	if filter.ClassType != ber.ClassContext {
+		return false, errors.New("filter tidak valid")
+	}
+	switch filter.Tag {
+	case ldap.FilterAnd:
+		for _, child := range filter.Children {
+			matched, err := matches(entry, child)
+			if err != nil || !matched {
+				return false, err
+			}
+		}
+		return true, nil
+	case ldap.FilterOr:
+		for _, child := range filter.Children {
+			matched, err := matches(entry, child)
+			if err != nil || matched {
+				return matched, err
+			}
+		}
+		return false, nil
+	case ldap.FilterNot:
+		if len(filter.Children) != 1 {
+			return false, errors.New("filter not tidak valid")
+		}
+		matched, err := matches(entry, filter.Children[0])
+		return !matched, err
+	case ldap.FilterEqualityMatch:
+		if len(filter.Children) != 2 {
+			return false, errors.New("filter equality tidak valid")
+		}
+		return contains(attributeValues(entry, packetString(filter.Children[0])), packetString(filter.Children[1]), true), nil
+	case ldap.FilterPresent:
+		return len(attributeValues(entry, packetString(filter))) > 0, nil
+	case ldap.FilterSubstrings:
+		if len(filter.Children) != 2 {
+			return false, errors.New("filter substring tidak valid")
+		}
+		for _, value := range attributeValues(entry, packetString(filter.Children[0])) {
+			if matchSubstrings(strings.ToLower(value), filter.Children[1].Children) {
+				return true, nil
+			}
+		}
+		return false, nil
+	default:
+		return false, errors.New("jenis filter tidak didukung")
+	}
+}
+
+func matchSubstrings(value string, parts []*ber.Packet) bool {
+	for _, part := range parts {
+		piece := strings.ToLower(packetString(part))
+		switch part.Tag {
+		case ldap.FilterSubstringsInitial:
+			if !strings.HasPrefix(value, piece) {
+				return false
+			}
+			value = value[len(piece):]
+		case ldap.FilterSubstringsAny:
+			index := strings.Index(value, piece)
+			if index < 0 {
+				return false
+			}
+			value = value[index+len(piece):]
+		case ldap.FilterSubstringsFinal:
+			if !strings.HasSuffix(value, piece) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// attributeValues mencari atribut tanpa membedakan huruf besar; objectClass
+// dianggap selalu ada agar filter umum "(objectClass=*)" bekerja.
+func attributeValues(entry *Entry, name string) []string {
+	for key, values := range entry.Attributes {
+		if strings.EqualFold(key, name) {
+			return values
+		}
+	}
+	if strings.EqualFold(name, "objectClass") {
+		return []string{"top"}
+	}
+	return nil
+}
+
+func searchEntry(entry *Entry, requested []string) *ber.Packet {
+	packet := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+	packet.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, entry.DN, "Object Name"))
+	attributes := ber.NewSequence("Attributes")
+	for name, values := range entry.Attributes {
+		if strings.EqualFold(name, "userPassword") || !wanted(name, requested) {
+			continue
+		}
+		attribute := ber.NewSequence("Attribute")
+		attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "Type"))
+		set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+		for _, value := range values {
+			set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "Value"))
+		}
+		attribute.AppendChild(set)
+		attributes.AppendChild(attribute)
+	}
+	packet.AppendChild(attributes)
+	return packet
+}
+
+func wanted(name string, requested []string) bool {
+	if len(requested) == 0 {
+		return true
+	}
+	for _, candidate := range requested {
+		if candidate == "*" || strings.EqualFold(candidate, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func result(tag ber.Tag, code uint16, message string) *ber.Packet {
+	packet := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, ldap.ApplicationMap[uint8(tag)])
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code), "Result Code"))
+	packet.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	packet.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, message, "Diagnostic Message"))
+	return packet
+}
+
+func contains(values []string, target string, ignoreCase bool) bool {
+	for _, value := range values {
+		if value == target || (ignoreCase && strings.EqualFold(value, target)) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeDN(dn string) string {
+	parts := strings.Split(dn, ",")
+	for index, part := range parts {
+		parts[index] = strings.ToLower(strings.TrimSpace(part))
+	}
+	return strings.Join(parts, ",")
+}
+
+func packetString(packet *ber.Packet) string {
+	if packet.Data == nil {
+		return ""
+	}
+	return packet.Data.String()
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+    "net/http"
+
+    "unila_helpdesk_backend/internal/service"
+
+    "github.com/gin-gonic/gin"
+)
+
+// AuditMiddleware menyimpan IP dan user agent ke context agar service dapat
+// menuliskannya ke audit log, lalu mencatat akses yang ditolak untuk user login.
+func AuditMiddleware(audit *service.AuditService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        c.Set(service.AuditContextKey, service.AuditRequestMeta{
+            IP:        c.ClientIP(),
+            UserAgent: c.Request.UserAgent(),
+        })
+        c.Next()
+
+        if c.Writer.Status() != http.StatusForbidden {
+            return
+        }
+        user, ok := GetUser(c)
+        if !ok {
+            return
+        }
+        audit.Record(c, service.AuditEntry{
+            Actor:      &user,
+            Action:     service.AuditActionAccessDenied,
+            EntityType: service.AuditEntityRoute,
+            After: map[string]any{
+                "method": c.Request.Method,
+                "route":  c.FullPath(),
+                "path":   c.Request.URL.Path,
+            },
+        })
+    }
+}
@@ -11,7 +11,10 @@ import (
     "github.com/gin-gonic/gin"
 )
 
-const ContextUserKey = "authUser"
+const (
+    ContextUserKey    = "authUser"
+    ContextSessionKey = "authSession"
+)
 
 func AuthMiddleware(auth *service.AuthService, users *repository.UserRepository, required bool) gin.HandlerFunc {
     return func(c *gin.Context) {
@@ -55,7 +58,25 @@ func AuthMiddleware(auth *service.AuthService, users *repository.UserRepository,
             return
         }
 
+        if err := service.CheckTokenVersion(claims, *user); err != nil {
+            if required {
+                c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+                return
+            }
+            c.Next()
+            return
+        }
+        if err := auth.CheckSession(claims); err != nil {
+            if required {
+                c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "token sudah dicabut"})
+                return
+            }
+            c.Next()
+            return
+        }
+
         c.Set(ContextUserKey, *user)
+        c.Set(ContextSessionKey, claims.SessionID)
         c.Next()
     }
 }
@@ -84,3 +105,8 @@ func GetUser(c *gin.Context) (domain.User, bool) {
     user, ok := raw.(domain.User)
     return user, ok
 }
+
+// GetSessionID mengembalikan ID sesi dari access token; kosong untuk token lama.
+func GetSessionID(c *gin.Context) string {
+    return c.GetString(ContextSessionKey)
+}
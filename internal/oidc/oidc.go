@@ -0,0 +1,359 @@
+// Package oidc adalah client OpenID Connect minimal untuk SSO kampus: discovery,
+// authorization code + PKCE (S256), dan verifikasi ID token dengan JWKS provider.
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+)
+
+// jwksRefreshInterval membatasi pengambilan ulang JWKS saat kid tidak dikenal.
+const jwksRefreshInterval = time.Minute
+
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+	UserInfoEndpoint      string `json:"userinfo_endpoint"`
+}
+
+// Provider melakukan discovery secara lazy agar API tetap bisa start saat SSO
+// sedang tidak tersedia.
+type Provider struct {
+	config     Config
+	httpClient *http.Client
+
+	mu          sync.Mutex
+	metadata    *discovery
+	keys        map[string]crypto.PublicKey
+	keysFetched time.Time
+}
+
+// Claims adalah klaim ID token (digabung dengan userinfo jika tersedia).
+type Claims map[string]any
+
+func NewProvider(config Config) *Provider {
+	config.Issuer = strings.TrimRight(strings.TrimSpace(config.Issuer), "/")
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{"openid", "profile", "email"}
+	}
+	return &Provider{
+		config:     config,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// AuthCodeURL membangun URL authorization dengan state, nonce, dan challenge PKCE.
+func (provider *Provider) AuthCodeURL(ctx context.Context, state string, nonce string, verifier string) (string, error) {
+	config, err := provider.oauthConfig(ctx)
+	if err != nil {
+		return "", err
+	}
+	return config.AuthCodeURL(
+		state,
+		oauth2.S256ChallengeOption(verifier),
+		oauth2.SetAuthURLParam("nonce", nonce),
+	), nil
+}
+
+// Exchange menukar authorization code, memverifikasi ID token, lalu melengkapi
+// klaim dari endpoint userinfo.
+func (provider *Provider) Exchange(ctx context.Context, code string, verifier string, nonce string) (Claims, error) {
+	config, err := provider.oauthConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, provider.httpClient)
+	token, err := config.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("token exchange gagal: %w", err)
+	}
+	rawIDToken, _ := token.Extra("id_token").(string)
+	if rawIDToken == "" {
+		return nil, errors.New("provider tidak mengembalikan id_token")
+	}
+	claims, err := provider.VerifyIDToken(ctx, rawIDToken, nonce)
+	if err != nil {
+		return nil, err
+	}
+	if err := provider.mergeUserInfo(ctx, token, claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// VerifyIDToken memeriksa tanda tangan, issuer, audience, masa berlaku, dan nonce.
+func (provider *Provider) VerifyIDToken(ctx context.Context, rawIDToken string, nonce string) (Claims, error) {
+	metadata, err := provider.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return provider.publicKey(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "EdDSA"}),
+		jwt.WithIssuer(metadata.Issuer),
+		jwt.WithAudience(provider.config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("id_token tidak valid: %w", err)
+	}
+	if value, _ := claims["nonce"].(string); value != nonce {
+		return nil, errors.New("nonce id_token tidak cocok")
+	}
+	return Claims(claims), nil
+}
+
+func (provider *Provider) mergeUserInfo(ctx context.Context, token *oauth2.Token, claims Claims) error {
+	metadata, err := provider.discover(ctx)
+	if err != nil || metadata.UserInfoEndpoint == "" {
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, metadata.UserInfoEndpoint, nil)
+	if err != nil {
+		return err
+	}
+	token.SetAuthHeader(request)
+	response, err := provider.httpClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("userinfo gagal: %w", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("userinfo gagal: status %d", response.StatusCode)
+	}
+	var info map[string]any
+	if err := json.NewDecoder(response.Body).Decode(&info); err != nil {
+		return fmt.Errorf("userinfo tidak valid: %w", err)
+	}
+	// Spesifikasi mewajibkan sub userinfo sama dengan sub ID token.
+	if sub, _ := info["sub"].(string); sub != claims.String("sub") {
+		return errors.New("sub userinfo tidak cocok dengan id_token")
+	}
+	for key, value := range info {
+		if _, exists := claims[key]; !exists {
+			claims[key] = value
+		}
+	}
+	return nil
+}
+
+func (provider *Provider) oauthConfig(ctx context.Context) (*oauth2.Config, error) {
+	metadata, err := provider.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &oauth2.Config{
+		ClientID:     provider.config.ClientID,
+		ClientSecret: provider.config.ClientSecret,
+		RedirectURL:  provider.config.RedirectURL,
+		Scopes:       provider.config.Scopes,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  metadata.AuthorizationEndpoint,
+			TokenURL: metadata.TokenEndpoint,
+		},
+	}, nil
+}
+
+func (provider *Provider) discover(ctx context.Context) (*discovery, error) {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+	if provider.metadata != nil {
+		return provider.metadata, nil
+	}
+	var metadata discovery
+	if err := provider.getJSON(ctx, provider.config.Issuer+"/.well-known/openid-configuration", &metadata); err != nil {
+		return nil, fmt.Errorf("discovery OIDC gagal: %w", err)
+	}
+	if strings.TrimRight(metadata.Issuer, "/") != provider.config.Issuer {
+		return nil, fmt.Errorf("issuer discovery %s tidak cocok dengan %s", metadata.Issuer, provider.config.Issuer)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, errors.New("discovery OIDC tidak lengkap")
+	}
+	provider.metadata = &metadata
+	return provider.metadata, nil
+}
+
+// publicKey mengambil kunci dari cache JWKS dan memuat ulang sekali jika kid belum
+// dikenal, mis. setelah provider merotasi kunci.
+func (provider *Provider) publicKey(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+	if key, ok := provider.lookupKey(kid); ok {
+		return key, nil
+	}
+	if time.Since(provider.keysFetched) < jwksRefreshInterval && provider.keys != nil {
+		return nil, fmt.Errorf("kid %q tidak dikenal", kid)
+	}
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := provider.getJSON(ctx, provider.metadata.JWKSURI, &set); err != nil {
+		return nil, fmt.Errorf("JWKS provider gagal: %w", err)
+	}
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, item := range set.Keys {
+		if item.Use != "" && item.Use != "sig" {
+			continue
+		}
+		if key, err := item.publicKey(); err == nil {
+			keys[item.KeyID] = key
+		}
+	}
+	provider.keys = keys
+	provider.keysFetched = time.Now()
+	if key, ok := provider.lookupKey(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("kid %q tidak dikenal", kid)
+}
+
+// lookupKey menerima token tanpa kid hanya jika provider memiliki satu kunci.
+func (provider *Provider) lookupKey(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(provider.keys) == 1 {
+		for _, key := range provider.keys {
+			return key, true
+		}
+	}
+	key, ok := provider.keys[kid]
+	return key, ok
+}
+
+func (provider *Provider) getJSON(ctx context.Context, url string, target any) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	response, err := provider.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: status %d", url, response.StatusCode)
+	}
+	return json.NewDecoder(response.Body).Decode(target)
+}
+
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+	Curve   string `json:"crv"`
+	X       string `json:"x"`
+	Y       string `json:"y"`
+}
+
+func (key jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	decode := base64.RawURLEncoding.DecodeString
+	switch key.KeyType {
+	case "RSA":
+		n, err := decode(key.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(key.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch key.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		default:
+			return nil, fmt.Errorf("kurva %s tidak didukung", key.Curve)
+		}
+		x, err := decode(key.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(key.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	case "OKP":
+		if key.Curve != "Ed25519" {
+			return nil, fmt.Errorf("kurva %s tidak didukung", key.Curve)
+		}
+		x, err := decode(key.X)
+		if err != nil {
+			return nil, err
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("kty %s tidak didukung", key.KeyType)
+}
+
+// String mengembalikan klaim string atau kosong.
+func (claims Claims) String(name string) string {
+	value, _ := claims[name].(string)
+	return strings.TrimSpace(value)
+}
+
+// Strings membaca klaim berupa string tunggal atau array string (mis. groups).
+func (claims Claims) Strings(name string) []string {
+	switch value := claims[name].(type) {
+	case string:
+		if strings.TrimSpace(value) == "" {
+			return nil
+		}
+		return []string{strings.TrimSpace(value)}
+	case []any:
+		result := make([]string, 0, len(value))
+		for _, item := range value {
+			if text, ok := item.(string); ok && strings.TrimSpace(text) != "" {
+				result = append(result, strings.TrimSpace(text))
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+// Bool membaca klaim boolean; ok false jika klaim tidak ada.
+func (claims Claims) Bool(name string) (value bool, ok bool) {
+	switch raw := claims[name].(type) {
+	case bool:
+		return raw, true
+	case string:
+		return strings.EqualFold(raw, "true"), true
+	}
+	return false, false
+}
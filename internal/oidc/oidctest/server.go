@@ -0,0 +1,291 @@
+// Package oidctest adalah provider OpenID Connect palsu untuk menguji login SSO
+// tanpa IdP kampus. Halaman /authorize menampilkan form identitas (email, nama,
+// entity, groups) lalu mengembalikan authorization code ke redirect_uri; parameter
+// login_hint melewati form. PKCE S256 wajib dan code hanya bisa ditukar sekali.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"html/template"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const keyID = "mock-1"
+
+type authCode struct {
+	ClientID    string
+	RedirectURI string
+	Challenge   string
+	Nonce       string
+	Claims      map[string]any
+	ExpiresAt   time.Time
+}
+
+// Config mengatur client yang diterima provider. Issuer kosong berarti issuer
+// diambil dari Host request, cocok untuk httptest.Server dengan port acak.
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+}
+
+type server struct {
+	issuer       string
+	clientID     string
+	clientSecret string
+	key          *rsa.PrivateKey
+
+	mu           sync.Mutex
+	codes        map[string]authCode
+	accessTokens map[string]map[string]any
+}
+
+var loginPage = template.Must(template.New("login").Parse(`<!doctype html>
+<html><body style="font-family:sans-serif;max-width:420px;margin:40px auto">
+<h2>Mock SSO Unila</h2>
+<form method="get" action="/authorize">
+{{range $key, $value := .Params}}<input type="hidden" name="{{$key}}" value="{{$value}}">
+{{end}}
+<p><label>Email<br><input name="login_hint" value="mhs01@unila.local" size="40"></label></p>
+<p><label>Nama<br><input name="name" value="Mahasiswa SSO" size="40"></label></p>
+<p><label>Entity<br><select name="entity">
+<option>mahasiswa</option><option>dosen</option><option>tendik</option></select></label></p>
+<p><label>Groups (pisahkan koma)<br><input name="groups" value="" size="40"></label></p>
+<button type="submit">Login</button>
+</form></body></html>`))
+
+// NewHandler membuat provider dengan kunci RSA baru. Endpoint: discovery, /jwks,
+// /authorize, /token, dan /userinfo.
+func NewHandler(config Config) (http.Handler, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	srv := &server{
+		issuer:       strings.TrimRight(config.Issuer, "/"),
+		clientID:     config.ClientID,
+		clientSecret: config.ClientSecret,
+		key:          key,
+		codes:        make(map[string]authCode),
+		accessTokens: make(map[string]map[string]any),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", srv.discovery)
+	mux.HandleFunc("GET /jwks", srv.jwks)
+	mux.HandleFunc("GET /authorize", srv.authorize)
+	mux.HandleFunc("POST /token", srv.token)
+	mux.HandleFunc("GET /userinfo", srv.userinfo)
+	return mux, nil
+}
+
+func (srv *server) issuerFor(r *http.Request) string {
+	if srv.issuer != "" {
+		return srv.issuer
+	}
+	return "http://" + r.Host
+}
+
+func (srv *server) discovery(w http.ResponseWriter, r *http.Request) {
+	issuer := srv.issuerFor(r)
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                issuer,
+		"authorization_endpoint":                issuer + "/authorize",
+		"token_endpoint":                        issuer + "/token",
+		"jwks_uri":                              issuer + "/jwks",
+		"userinfo_endpoint":                     issuer + "/userinfo",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (srv *server) jwks(w http.ResponseWriter, _ *http.Request) {
+	public := srv.key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]any{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": keyID,
+		"use": "sig",
+		"alg": "RS256",
+		"n":   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+	}}})
+}
+
+func (srv *server) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("response_type") != "code" || query.Get("client_id") != srv.clientID {
+		http.Error(w, "response_type atau client_id tidak valid", http.StatusBadRequest)
+		return
+	}
+	if query.Get("code_challenge") == "" || query.Get("code_challenge_method") != "S256" {
+		http.Error(w, "PKCE S256 wajib", http.StatusBadRequest)
+		return
+	}
+	redirectURI, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || redirectURI.Scheme == "" {
+		http.Error(w, "redirect_uri tidak valid", http.StatusBadRequest)
+		return
+	}
+
+	email := strings.ToLower(strings.TrimSpace(query.Get("login_hint")))
+	if email == "" {
+		params := map[string]string{}
+		for _, name := range []string{"response_type", "client_id", "redirect_uri", "scope", "state", "nonce", "code_challenge", "code_challenge_method"} {
+			params[name] = query.Get(name)
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = loginPage.Execute(w, map[string]any{"Params": params})
+		return
+	}
+
+	localPart, _, _ := strings.Cut(email, "@")
+	sum := sha256.Sum256([]byte(email))
+	claims := map[string]any{
+		"sub":                "mock-" + hex.EncodeToString(sum[:6]),
+		"email":              email,
+		"email_verified":     true,
+		"name":               valueOr(query.Get("name"), localPart),
+		"preferred_username": localPart,
+		"entity":             query.Get("entity"),
+		"groups":             splitGroups(query.Get("groups")),
+	}
+	code := randomString()
+	srv.mu.Lock()
+	srv.codes[code] = authCode{
+		ClientID:    srv.clientID,
+		RedirectURI: redirectURI.String(),
+		Challenge:   query.Get("code_challenge"),
+		Nonce:       query.Get("nonce"),
+		Claims:      claims,
+		ExpiresAt:   time.Now().Add(time.Minute),
+	}
+	srv.mu.Unlock()
+
+	values := redirectURI.Query()
+	values.Set("code", code)
+	values.Set("state", query.Get("state"))
+	redirectURI.RawQuery = values.Encode()
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+func (srv *server) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		tokenError(w, "invalid_request")
+		return
+	}
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID = r.PostForm.Get("client_id")
+		clientSecret = r.PostForm.Get("client_secret")
+	}
+	if clientID != srv.clientID || (srv.clientSecret != "" && clientSecret != srv.clientSecret) {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+	if r.PostForm.Get("grant_type") != "authorization_code" {
+		tokenError(w, "unsupported_grant_type")
+		return
+	}
+
+	srv.mu.Lock()
+	code, found := srv.codes[r.PostForm.Get("code")]
+	delete(srv.codes, r.PostForm.Get("code"))
+	srv.mu.Unlock()
+	if !found || time.Now().After(code.ExpiresAt) || code.RedirectURI != r.PostForm.Get("redirect_uri") {
+		tokenError(w, "invalid_grant")
+		return
+	}
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != code.Challenge {
+		tokenError(w, "invalid_grant")
+		return
+	}
+
+	now := time.Now()
+	idClaims := jwt.MapClaims{
+		"iss":   srv.issuerFor(r),
+		"aud":   clientID,
+		"iat":   now.Unix(),
+		"exp":   now.Add(5 * time.Minute).Unix(),
+		"nonce": code.Nonce,
+	}
+	for key, value := range code.Claims {
+		idClaims[key] = value
+	}
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, idClaims)
+	idToken.Header["kid"] = keyID
+	signed, err := idToken.SignedString(srv.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+	accessToken := randomString()
+	srv.mu.Lock()
+	srv.accessTokens[accessToken] = code.Claims
+	srv.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     signed,
+	})
+}
+
+func (srv *server) userinfo(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	srv.mu.Lock()
+	claims, found := srv.accessTokens[token]
+	srv.mu.Unlock()
+	if !found {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_token"})
+		return
+	}
+	writeJSON(w, http.StatusOK, claims)
+}
+
+func tokenError(w http.ResponseWriter, code string) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{"error": code})
+}
+
+func writeJSON(w http.ResponseWriter, status int, payload any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(payload)
+}
+
+func splitGroups(raw string) []string {
+	groups := make([]string, 0)
+	for _, group := range strings.Split(raw, ",") {
+		if group = strings.TrimSpace(group); group != "" {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+func randomString() string {
+	buffer := make([]byte, 24)
+	_, _ = rand.Read(buffer)
+	return base64.RawURLEncoding.EncodeToString(buffer)
+}
+
+func valueOr(value string, fallback string) string {
+	if strings.TrimSpace(value) == "" {
+		return fallback
+	}
+	return strings.TrimSpace(value)
+}
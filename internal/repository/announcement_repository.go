@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"encoding/json"
+	"time"
+
+	"unila_helpdesk_backend/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type AnnouncementRepository struct {
+	db *gorm.DB
+}
+
+type AnnouncementListFilter struct {
+	// ActiveAt membatasi pengumuman yang sedang berlaku pada waktu tersebut.
+	ActiveAt *time.Time
+	// CategoryIDs mencocokkan pengumuman untuk salah satu kategori atau pengumuman umum.
+	CategoryIDs []string
+}
+
+func NewAnnouncementRepository(db *gorm.DB) *AnnouncementRepository {
+	return &AnnouncementRepository{db: db}
+}
+
+func (repo *AnnouncementRepository) Create(announcement *domain.Announcement) error {
+	return repo.db.Create(announcement).Error
+}
+
+func (repo *AnnouncementRepository) Update(announcement *domain.Announcement) error {
+	return repo.db.Save(announcement).Error
+}
+
+func (repo *AnnouncementRepository) Delete(announcementID string) error {
+	result := repo.db.Delete(&domain.Announcement{}, "id = ?", announcementID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (repo *AnnouncementRepository) FindByID(announcementID string) (*domain.Announcement, error) {
+	var announcement domain.Announcement
+	if err := repo.db.First(&announcement, "id = ?", announcementID).Error; err != nil {
+		return nil, err
+	}
+	return &announcement, nil
+}
+
+func (repo *AnnouncementRepository) List(filter AnnouncementListFilter) ([]domain.Announcement, error) {
+	qb := repo.db.Model(&domain.Announcement{})
+	if filter.ActiveAt != nil {
+		qb = qb.Where("starts_at <= ? AND (ends_at IS NULL OR ends_at > ?)", *filter.ActiveAt, *filter.ActiveAt)
+	}
+	if len(filter.CategoryIDs) > 0 {
+		clauses := repo.db.Where("category_ids IS NULL OR jsonb_array_length(category_ids) = 0")
+		for _, categoryID := range filter.CategoryIDs {
+			payload, _ := json.Marshal([]string{categoryID})
+			clauses = clauses.Or("category_ids @> ?", string(payload))
+		}
+		qb = qb.Where(clauses)
+	}
+	announcements := make([]domain.Announcement, 0)
+	if err := qb.Order("starts_at desc").Find(&announcements).Error; err != nil {
+		return nil, err
+	}
+	return announcements, nil
+}
@@ -0,0 +1,289 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"unila_helpdesk_backend/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// articleSearchVector harus sama dengan ekspresi index idx_articles_search.
+const articleSearchVector = "to_tsvector('simple', coalesce(articles.title, '') || ' ' || " +
+	"coalesce(articles.summary, '') || ' ' || coalesce(articles.body, ''))"
+
+type ArticleRepository struct {
+	db *gorm.DB
+}
+
+type ArticleListFilter struct {
+	Query      string
+	CategoryID []string
+	Status     domain.ArticleStatus
+}
+
+type DeflectionCountRow struct {
+	CategoryID string
+	Total      int
+}
+
+type ArticleDeflectionRow struct {
+	ArticleID string
+	Total     int
+}
+
+type ArticleUsageRow struct {
+	CategoryID string
+	ArticleID  string
+	Total      int
+}
+
+func NewArticleRepository(db *gorm.DB) *ArticleRepository {
+	return &ArticleRepository{db: db}
+}
+
+func (repo *ArticleRepository) Create(article *domain.Article) error {
+	return repo.db.Create(article).Error
+}
+
+func (repo *ArticleRepository) Update(article *domain.Article) error {
+	return repo.db.Save(article).Error
+}
+
+func (repo *ArticleRepository) Delete(articleID string) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("article_id = ?", articleID).Delete(&domain.ArticleVote{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("article_id = ?", articleID).Delete(&domain.TicketCommentArticle{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&domain.Article{}, "id = ?", articleID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// FindByIDOrSlug menerima ID atau slug artikel.
+func (repo *ArticleRepository) FindByIDOrSlug(value string) (*domain.Article, error) {
+	var article domain.Article
+	if err := repo.db.Where("id = ? OR slug = ?", value, value).First(&article).Error; err != nil {
+		return nil, err
+	}
+	return &article, nil
+}
+
+func (repo *ArticleRepository) SlugExists(slug string, excludeID string) (bool, error) {
+	var total int64
+	qb := repo.db.Model(&domain.Article{}).Where("slug = ?", slug)
+	if excludeID != "" {
+		qb = qb.Where("id <> ?", excludeID)
+	}
+	if err := qb.Count(&total).Error; err != nil {
+		return false, err
+	}
+	return total > 0, nil
+}
+
+// List mencari artikel. Jika Query diisi, hasil diurutkan berdasarkan relevansi full-text
+// dengan cadangan ILIKE pada judul agar kata parsial tetap ditemukan.
+func (repo *ArticleRepository) List(filter ArticleListFilter, page int, limit int) ([]domain.Article, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+	qb := repo.db.Model(&domain.Article{})
+	if filter.Status != "" {
+		qb = qb.Where("articles.status = ?", filter.Status)
+	}
+	if len(filter.CategoryID) > 0 {
+		qb = qb.Where("articles.category_id IN ?", filter.CategoryID)
+	}
+	if filter.Query != "" {
+		qb = qb.Where(
+			articleSearchVector+" @@ plainto_tsquery('simple', ?) OR articles.title ILIKE ?",
+			filter.Query,
+			"%"+filter.Query+"%",
+		)
+	}
+
+	var total int64
+	if err := qb.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if filter.Query != "" {
+		qb = qb.Clauses(clause.OrderBy{
+			Expression: clause.Expr{
+				SQL:  "ts_rank(" + articleSearchVector + ", plainto_tsquery('simple', ?)) desc, articles.helpful_count desc",
+				Vars: []any{filter.Query},
+			},
+		})
+	} else {
+		qb = qb.Order("articles.updated_at desc")
+	}
+
+	var articles []domain.Article
+	if err := qb.Limit(limit).Offset((page - 1) * limit).Find(&articles).Error; err != nil {
+		return nil, 0, err
+	}
+	return articles, total, nil
+}
+
+func (repo *ArticleRepository) IncrementViews(articleID string) error {
+	return repo.db.Model(&domain.Article{}).
+		Where("id = ?", articleID).
+		UpdateColumn("view_count", gorm.Expr("view_count + 1")).Error
+}
+
+// Vote menyimpan atau mengganti suara pengunjung dan menyesuaikan penghitung artikel
+// dalam satu transaksi.
+func (repo *ArticleRepository) Vote(articleID string, voterKey string, helpful bool) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		var existing domain.ArticleVote
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&existing, "article_id = ? AND voter_key = ?", articleID, voterKey).Error
+		switch {
+		case err == nil:
+			if existing.Helpful == helpful {
+				return nil
+			}
+			if err := tx.Model(&existing).Update("helpful", helpful).Error; err != nil {
+				return err
+			}
+			return tx.Model(&domain.Article{}).Where("id = ?", articleID).UpdateColumns(map[string]any{
+				"helpful_count":     gorm.Expr("helpful_count + ?", voteDelta(helpful)),
+				"not_helpful_count": gorm.Expr("not_helpful_count + ?", voteDelta(!helpful)),
+			}).Error
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			if err := tx.Create(&domain.ArticleVote{
+				ArticleID: articleID,
+				VoterKey:  voterKey,
+				Helpful:   helpful,
+			}).Error; err != nil {
+				return err
+			}
+			column := "not_helpful_count"
+			if helpful {
+				column = "helpful_count"
+			}
+			return tx.Model(&domain.Article{}).Where("id = ?", articleID).
+				UpdateColumn(column, gorm.Expr(column+" + 1")).Error
+		default:
+			return err
+		}
+	})
+}
+
+func voteDelta(increase bool) int {
+	if increase {
+		return 1
+	}
+	return -1
+}
+
+func (repo *ArticleRepository) CreateDeflection(deflection *domain.ArticleDeflection) error {
+	return repo.db.Create(deflection).Error
+}
+
+func (repo *ArticleRepository) CountDeflectionsByCategory(start time.Time, end time.Time) ([]DeflectionCountRow, error) {
+	var rows []DeflectionCountRow
+	if err := repo.db.Model(&domain.ArticleDeflection{}).
+		Select("category_id, count(*) as total").
+		Where("created_at >= ? AND created_at < ?", start, end).
+		Group("category_id").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (repo *ArticleRepository) TopDeflectingArticles(start time.Time, end time.Time, limit int) ([]ArticleDeflectionRow, error) {
+	var rows []ArticleDeflectionRow
+	if err := repo.db.Model(&domain.ArticleDeflection{}).
+		Select("article_id, count(*) as total").
+		Where("created_at >= ? AND created_at < ?", start, end).
+		Group("article_id").
+		Order("total desc").
+		Limit(limit).
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (repo *ArticleRepository) FindByIDs(ids []string) ([]domain.Article, error) {
+	articles := make([]domain.Article, 0)
+	if len(ids) == 0 {
+		return articles, nil
+	}
+	if err := repo.db.Where("id IN ?", ids).Find(&articles).Error; err != nil {
+		return nil, err
+	}
+	return articles, nil
+}
+
+func (repo *ArticleRepository) FindBySourceTicket(ticketID string) (*domain.Article, error) {
+	var article domain.Article
+	if err := repo.db.Where("source_ticket_id = ?", ticketID).First(&article).Error; err != nil {
+		return nil, err
+	}
+	return &article, nil
+}
+
+func (repo *ArticleRepository) LinkToComment(commentID string, articleIDs []string, linkedBy string) error {
+	if len(articleIDs) == 0 {
+		return nil
+	}
+	links := make([]domain.TicketCommentArticle, 0, len(articleIDs))
+	for _, articleID := range articleIDs {
+		links = append(links, domain.TicketCommentArticle{
+			CommentID: commentID,
+			ArticleID: articleID,
+			LinkedBy:  linkedBy,
+		})
+	}
+	return repo.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&links).Error
+}
+
+// CountResolutionUsage menghitung berapa tiket per kategori yang penyelesaiannya
+// melampirkan tiap artikel.
+func (repo *ArticleRepository) CountResolutionUsage(start time.Time, end time.Time) ([]ArticleUsageRow, error) {
+	var rows []ArticleUsageRow
+	if err := repo.db.Table("ticket_comment_articles AS tca").
+		Select("tickets.category_id AS category_id, tca.article_id AS article_id, count(DISTINCT tickets.id) AS total").
+		Joins("JOIN ticket_comments ON ticket_comments.id = tca.comment_id").
+		Joins("JOIN tickets ON tickets.id = ticket_comments.ticket_id AND tickets.deleted_at IS NULL").
+		Where("tca.created_at >= ? AND tca.created_at < ?", start, end).
+		Group("tickets.category_id, tca.article_id").
+		Order("total desc").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// CountTicketsResolvedWithArticles menghitung tiket unik per kategori yang memiliki
+// minimal satu artikel pada komentar penyelesaiannya.
+func (repo *ArticleRepository) CountTicketsResolvedWithArticles(start time.Time, end time.Time) ([]CategoryCountRow, error) {
+	var rows []CategoryCountRow
+	if err := repo.db.Table("ticket_comment_articles AS tca").
+		Select("tickets.category_id AS category_id, count(DISTINCT tickets.id) AS total").
+		Joins("JOIN ticket_comments ON ticket_comments.id = tca.comment_id").
+		Joins("JOIN tickets ON tickets.id = ticket_comments.ticket_id AND tickets.deleted_at IS NULL").
+		Where("tca.created_at >= ? AND tca.created_at < ?", start, end).
+		Group("tickets.category_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
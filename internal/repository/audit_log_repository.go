@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"time"
+
+	"unila_helpdesk_backend/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// auditChainLockKey adalah kunci advisory lock Postgres agar penambahan
+// audit log berurutan dan rantai hash tidak bercabang.
+const auditChainLockKey = 7_100_031
+
+type AuditLogRepository struct {
+	db *gorm.DB
+}
+
+type AuditLogFilter struct {
+	ActorID    string
+	Action     string
+	EntityType string
+	EntityID   string
+	Start      *time.Time
+	End        *time.Time
+}
+
+func NewAuditLogRepository(db *gorm.DB) *AuditLogRepository {
+	return &AuditLogRepository{db: db}
+}
+
+// Append menyimpan entri baru setelah seal mengisi PrevHash dan Hash
+// berdasarkan hash entri terakhir.
+func (repo *AuditLogRepository) Append(entry *domain.AuditLog, seal func(entry *domain.AuditLog, prevHash string)) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", auditChainLockKey).Error; err != nil {
+			return err
+		}
+		var prevHash string
+		if err := tx.Model(&domain.AuditLog{}).
+			Select("hash").
+			Order("id desc").
+			Limit(1).
+			Scan(&prevHash).Error; err != nil {
+			return err
+		}
+		seal(entry, prevHash)
+		return tx.Create(entry).Error
+	})
+}
+
+func (repo *AuditLogRepository) List(filter AuditLogFilter, page int, limit int) ([]domain.AuditLog, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+
+	qb := repo.db.Model(&domain.AuditLog{})
+	if filter.ActorID != "" {
+		qb = qb.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.Action != "" {
+		qb = qb.Where("action = ?", filter.Action)
+	}
+	if filter.EntityType != "" {
+		qb = qb.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		qb = qb.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.Start != nil {
+		qb = qb.Where("created_at >= ?", *filter.Start)
+	}
+	if filter.End != nil {
+		qb = qb.Where("created_at < ?", *filter.End)
+	}
+
+	var total int64
+	if err := qb.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var logs []domain.AuditLog
+	if err := qb.Order("id desc").
+		Limit(limit).
+		Offset((page - 1) * limit).
+		Find(&logs).Error; err != nil {
+		return nil, 0, err
+	}
+	return logs, total, nil
+}
+
+// Walk membaca seluruh audit log berurutan id dalam batch dan berhenti
+// ketika visit mengembalikan false.
+func (repo *AuditLogRepository) Walk(batchSize int, visit func(entry domain.AuditLog) bool) error {
+	var lastID int64
+	for {
+		var batch []domain.AuditLog
+		if err := repo.db.Where("id > ?", lastID).
+			Order("id asc").
+			Limit(batchSize).
+			Find(&batch).Error; err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		for _, entry := range batch {
+			if !visit(entry) {
+				return nil
+			}
+			lastID = entry.ID
+		}
+	}
+}
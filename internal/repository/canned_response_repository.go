@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"unila_helpdesk_backend/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type CannedResponseRepository struct {
+	db *gorm.DB
+}
+
+func NewCannedResponseRepository(db *gorm.DB) *CannedResponseRepository {
+	return &CannedResponseRepository{db: db}
+}
+
+// List mengembalikan balasan untuk kategori tertentu beserta balasan umum.
+// categoryIDs kosong berarti seluruh balasan.
+func (repo *CannedResponseRepository) List(categoryIDs []string) ([]domain.CannedResponse, error) {
+	var items []domain.CannedResponse
+	qb := repo.db.Model(&domain.CannedResponse{})
+	if len(categoryIDs) > 0 {
+		qb = qb.Where("category_id = '' OR category_id IN ?", categoryIDs)
+	}
+	if err := qb.Order("category_id asc, title asc").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (repo *CannedResponseRepository) FindByID(id string) (*domain.CannedResponse, error) {
+	var item domain.CannedResponse
+	if err := repo.db.First(&item, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (repo *CannedResponseRepository) Create(item *domain.CannedResponse) error {
+	return repo.db.Create(item).Error
+}
+
+func (repo *CannedResponseRepository) Update(item *domain.CannedResponse) error {
+	return repo.db.Save(item).Error
+}
+
+// Delete juga melepas referensi macro agar macro tetap bisa dipakai untuk status/petugas.
+func (repo *CannedResponseRepository) Delete(id string) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.Macro{}).
+			Where("canned_response_id = ?", id).
+			Update("canned_response_id", "").Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&domain.CannedResponse{}, "id = ?", id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+func (repo *CannedResponseRepository) ListMacros(categoryIDs []string) ([]domain.Macro, error) {
+	var items []domain.Macro
+	qb := repo.db.Model(&domain.Macro{})
+	if len(categoryIDs) > 0 {
+		qb = qb.Where("category_id = '' OR category_id IN ?", categoryIDs)
+	}
+	if err := qb.Order("name asc").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (repo *CannedResponseRepository) FindMacroByID(id string) (*domain.Macro, error) {
+	var item domain.Macro
+	if err := repo.db.First(&item, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (repo *CannedResponseRepository) CreateMacro(item *domain.Macro) error {
+	return repo.db.Create(item).Error
+}
+
+func (repo *CannedResponseRepository) UpdateMacro(item *domain.Macro) error {
+	return repo.db.Save(item).Error
+}
+
+func (repo *CannedResponseRepository) DeleteMacro(id string) error {
+	result := repo.db.Delete(&domain.Macro{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
     "strings"
+    "time"
 
     "unila_helpdesk_backend/internal/domain"
 
@@ -16,17 +17,39 @@ func NewCategoryRepository(db *gorm.DB) *CategoryRepository {
     return &CategoryRepository{db: db}
 }
 
+// List mengembalikan seluruh kategori termasuk yang diarsipkan (untuk laporan dan admin).
 func (repo *CategoryRepository) List() ([]domain.ServiceCategory, error) {
     var categories []domain.ServiceCategory
-    if err := repo.db.Order("name asc").Find(&categories).Error; err != nil {
+    if err := repo.db.Preload("Fields", orderCategoryFields).
+        Order("sort_order asc, name asc").
+        Find(&categories).Error; err != nil {
         return nil, err
     }
     return categories, nil
 }
 
+// HasActiveChildren menandakan kategori bukan leaf sehingga tidak bisa dipakai untuk tiket baru.
+func (repo *CategoryRepository) HasActiveChildren(id string) (bool, error) {
+    var total int64
+    if err := repo.db.Model(&domain.ServiceCategory{}).
+        Where("parent_id = ? AND archived_at IS NULL", id).
+        Count(&total).Error; err != nil {
+        return false, err
+    }
+    return total > 0, nil
+}
+
+func (repo *CategoryRepository) Count() (int64, error) {
+    var total int64
+    if err := repo.db.Model(&domain.ServiceCategory{}).Count(&total).Error; err != nil {
+        return 0, err
+    }
+    return total, nil
+}
+
 func (repo *CategoryRepository) FindByID(id string) (*domain.ServiceCategory, error) {
     var category domain.ServiceCategory
-    if err := repo.db.First(&category, "id = ?", id).Error; err != nil {
+    if err := repo.db.Preload("Fields", orderCategoryFields).First(&category, "id = ?", id).Error; err != nil {
         return nil, err
     }
     return &category, nil
@@ -34,21 +57,138 @@ func (repo *CategoryRepository) FindByID(id string) (*domain.ServiceCategory, er
 
 func (repo *CategoryRepository) FindByName(name string) (*domain.ServiceCategory, error) {
     var category domain.ServiceCategory
-    if err := repo.db.Where("lower(name) = ?", strings.ToLower(name)).First(&category).Error; err != nil {
+    if err := repo.db.Preload("Fields", orderCategoryFields).
+        Where("lower(name) = ?", strings.ToLower(name)).
+        First(&category).Error; err != nil {
         return nil, err
     }
     return &category, nil
 }
 
-func (repo *CategoryRepository) Upsert(category domain.ServiceCategory) error {
-    var existing domain.ServiceCategory
-    if err := repo.db.First(&existing, "id = ?", category.ID).Error; err == nil {
-        return repo.db.Model(&existing).Updates(map[string]any{
-            "name":          category.Name,
-            "guest_allowed": category.GuestAllowed,
-        }).Error
+func (repo *CategoryRepository) Create(category *domain.ServiceCategory) error {
+    return repo.db.Omit("Fields").Create(category).Error
+}
+
+func (repo *CategoryRepository) Update(id string, updates map[string]any) error {
+    result := repo.db.Model(&domain.ServiceCategory{}).Where("id = ?", id).Updates(updates)
+    if result.Error != nil {
+        return result.Error
+    }
+    if result.RowsAffected == 0 {
+        return gorm.ErrRecordNotFound
     }
-    return repo.db.Create(&category).Error
+    return nil
+}
+
+func (repo *CategoryRepository) NextSortOrder() (int, error) {
+    var maxOrder *int
+    if err := repo.db.Model(&domain.ServiceCategory{}).
+        Select("MAX(sort_order)").
+        Scan(&maxOrder).Error; err != nil {
+        return 0, err
+    }
+    if maxOrder == nil {
+        return 0, nil
+    }
+    return *maxOrder + 1, nil
+}
+
+// Reorder menyimpan urutan tampilan sesuai posisi ID pada slice.
+func (repo *CategoryRepository) Reorder(ids []string) error {
+    return repo.db.Transaction(func(tx *gorm.DB) error {
+        for index, id := range ids {
+            if err := tx.Model(&domain.ServiceCategory{}).
+                Where("id = ?", id).
+                Update("sort_order", index).Error; err != nil {
+                return err
+            }
+        }
+        return nil
+    })
+}
+
+// Merge memindahkan tiket (termasuk yang ada di trash), template survey, filter saved view,
+// artikel, canned response, macro, insiden, daftar kategori pengumuman dan preferensi
+// web push, serta sub-kategori dari kategori sumber ke target, lalu menghapus kategori sumber.
+func (repo *CategoryRepository) Merge(sourceID string, targetID string) error {
+    return repo.db.Transaction(func(tx *gorm.DB) error {
+        var source domain.ServiceCategory
+        if err := tx.First(&source, "id = ?", sourceID).Error; err != nil {
+            return err
+        }
+        if err := tx.Unscoped().Model(&domain.Ticket{}).
+            Where("category_id = ?", sourceID).
+            Update("category_id", targetID).Error; err != nil {
+            return err
+        }
+        if err := tx.Model(&domain.SurveyTemplate{}).
+            Where("category_id = ?", sourceID).
+            Update("category_id", targetID).Error; err != nil {
+            return err
+        }
+        if source.SurveyTemplateID != "" {
+            if err := tx.Model(&domain.ServiceCategory{}).
+                Where("id = ? AND (survey_template_id IS NULL OR survey_template_id = '')", targetID).
+                Update("survey_template_id", source.SurveyTemplateID).Error; err != nil {
+                return err
+            }
+        }
+        if err := tx.Exec(
+            "UPDATE ticket_views SET filter = jsonb_set(filter, '{categoryId}', to_jsonb(?::text)) "+
+                "WHERE filter->>'categoryId' = ?",
+            targetID,
+            sourceID,
+        ).Error; err != nil {
+            return err
+        }
+        for _, model := range []any{
+            &domain.Article{},
+            &domain.ArticleDeflection{},
+            &domain.CannedResponse{},
+            &domain.Macro{},
+        } {
+            if err := tx.Model(model).
+                Where("category_id = ?", sourceID).
+                Update("category_id", targetID).Error; err != nil {
+                return err
+            }
+        }
+        // Target hanya boleh memiliki satu insiden terbuka; insiden sumber ditutup jika
+        // target sudah punya insiden berjalan.
+        if err := tx.Model(&domain.ServiceIncident{}).
+            Where("category_id = ? AND resolved_at IS NULL", sourceID).
+            Where("EXISTS (SELECT 1 FROM service_incidents running WHERE running.category_id = ? AND running.resolved_at IS NULL)", targetID).
+            Update("resolved_at", time.Now()).Error; err != nil {
+            return err
+        }
+        if err := tx.Model(&domain.ServiceIncident{}).
+            Where("category_id = ?", sourceID).
+            Update("category_id", targetID).Error; err != nil {
+            return err
+        }
+        for _, table := range []string{"announcements", "web_push_preferences"} {
+            if err := tx.Exec(
+                "UPDATE "+table+" SET category_ids = ("+
+                    "SELECT jsonb_agg(DISTINCT CASE WHEN value = ?::text THEN ?::text ELSE value END) "+
+                    "FROM jsonb_array_elements_text(category_ids) AS value"+
+                    ") WHERE category_ids @> jsonb_build_array(?::text)",
+                sourceID,
+                targetID,
+                sourceID,
+            ).Error; err != nil {
+                return err
+            }
+        }
+        if err := tx.Model(&domain.ServiceCategory{}).
+            Where("parent_id = ?", sourceID).
+            Update("parent_id", targetID).Error; err != nil {
+            return err
+        }
+        if err := tx.Where("category_id = ?", sourceID).Delete(&domain.CategoryField{}).Error; err != nil {
+            return err
+        }
+        return tx.Delete(&domain.ServiceCategory{}, "id = ?", sourceID).Error
+    })
 }
 
 func (repo *CategoryRepository) UpdateTemplate(categoryID string, templateID string) error {
@@ -56,3 +196,20 @@ func (repo *CategoryRepository) UpdateTemplate(categoryID string, templateID str
         Where("id = ?", categoryID).
         Update("survey_template_id", templateID).Error
 }
+
+// ReplaceFields mengganti seluruh definisi field kustom sebuah kategori.
+func (repo *CategoryRepository) ReplaceFields(categoryID string, fields []domain.CategoryField) error {
+    return repo.db.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Where("category_id = ?", categoryID).Delete(&domain.CategoryField{}).Error; err != nil {
+            return err
+        }
+        if len(fields) == 0 {
+            return nil
+        }
+        return tx.Create(&fields).Error
+    })
+}
+
+func orderCategoryFields(db *gorm.DB) *gorm.DB {
+    return db.Order("sort_order asc, created_at asc")
+}
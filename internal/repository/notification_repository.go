@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"unila_helpdesk_backend/internal/domain"
 
 	"gorm.io/gorm"
@@ -26,6 +28,13 @@ func (repo *NotificationRepository) Create(notification *domain.Notification) er
 	return repo.db.Create(notification).Error
 }
 
+func (repo *NotificationRepository) CreateBatch(notifications []domain.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	return repo.db.CreateInBatches(notifications, 500).Error
+}
+
 type FCMTokenRepository struct {
 	db *gorm.DB
 }
@@ -51,6 +60,48 @@ func (repo *FCMTokenRepository) ListTokens(userID string) ([]domain.FCMToken, er
 	return tokens, nil
 }
 
+func (repo *FCMTokenRepository) ListByTokens(tokens []string) ([]domain.FCMToken, error) {
+	items := make([]domain.FCMToken, 0)
+	if len(tokens) == 0 {
+		return items, nil
+	}
+	if err := repo.db.Where("token IN ?", tokens).Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (repo *FCMTokenRepository) ListByUsers(userIDs []string) ([]domain.FCMToken, error) {
+	tokens := make([]domain.FCMToken, 0)
+	if len(userIDs) == 0 {
+		return tokens, nil
+	}
+	if err := repo.db.Where("user_id IN ?", userIDs).Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// ListTopicPending mengembalikan token yang belum terdaftar di topic pengumuman,
+// urut ID setelah afterID agar bisa diproses per batch.
+func (repo *FCMTokenRepository) ListTopicPending(afterID string, limit int) ([]domain.FCMToken, error) {
+	tokens := make([]domain.FCMToken, 0)
+	if err := repo.db.Where("topic_subscribed_at IS NULL AND id > ?", afterID).
+		Order("id asc").
+		Limit(limit).
+		Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (repo *FCMTokenRepository) MarkTopicSubscribed(tokens []string, at time.Time) error {
+	if len(tokens) == 0 {
+		return nil
+	}
+	return repo.db.Model(&domain.FCMToken{}).Where("token IN ?", tokens).Update("topic_subscribed_at", at).Error
+}
+
 func (repo *FCMTokenRepository) DeleteByUserAndTokens(userID string, tokens []string) error {
 	if userID == "" || len(tokens) == 0 {
 		return nil
@@ -58,6 +109,13 @@ func (repo *FCMTokenRepository) DeleteByUserAndTokens(userID string, tokens []st
 	return repo.db.Where("user_id = ? AND token IN ?", userID, tokens).Delete(&domain.FCMToken{}).Error
 }
 
+func (repo *FCMTokenRepository) DeleteByTokens(tokens []string) error {
+	if len(tokens) == 0 {
+		return nil
+	}
+	return repo.db.Where("token IN ?", tokens).Delete(&domain.FCMToken{}).Error
+}
+
 func (repo *FCMTokenRepository) DeleteByUserAndToken(userID string, token string) error {
 	if userID == "" || token == "" {
 		return nil
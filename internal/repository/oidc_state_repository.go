@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"time"
+
+	"unila_helpdesk_backend/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OIDCStateRepository struct {
+	db *gorm.DB
+}
+
+func NewOIDCStateRepository(db *gorm.DB) *OIDCStateRepository {
+	return &OIDCStateRepository{db: db}
+}
+
+func (repo *OIDCStateRepository) Create(state *domain.OIDCLoginState) error {
+	return repo.db.Create(state).Error
+}
+
+// Consume mengambil lalu menghapus state sehingga satu state hanya bisa dipakai sekali.
+func (repo *OIDCStateRepository) Consume(value string) (*domain.OIDCLoginState, error) {
+	var state domain.OIDCLoginState
+	result := repo.db.Clauses(clause.Returning{}).Where("state = ?", value).Delete(&state)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &state, nil
+}
+
+func (repo *OIDCStateRepository) DeleteExpired(now time.Time) error {
+	return repo.db.Where("expires_at < ?", now).Delete(&domain.OIDCLoginState{}).Error
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"time"
+
+	"unila_helpdesk_backend/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type PushLogRepository struct {
+	db *gorm.DB
+}
+
+type PushLogFilter struct {
+	UserID string
+	Status string
+	Topic  string
+	Start  *time.Time
+	End    *time.Time
+}
+
+func NewPushLogRepository(db *gorm.DB) *PushLogRepository {
+	return &PushLogRepository{db: db}
+}
+
+func (repo *PushLogRepository) CreateBatch(logs []domain.PushLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	return repo.db.CreateInBatches(logs, 500).Error
+}
+
+// DeleteBefore menghapus log yang dibuat sebelum cutoff.
+func (repo *PushLogRepository) DeleteBefore(cutoff time.Time) (int64, error) {
+	result := repo.db.Where("created_at < ?", cutoff).Delete(&domain.PushLog{})
+	return result.RowsAffected, result.Error
+}
+
+func (repo *PushLogRepository) List(filter PushLogFilter, page int, limit int) ([]domain.PushLog, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+
+	qb := repo.db.Model(&domain.PushLog{})
+	if filter.UserID != "" {
+		qb = qb.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Status != "" {
+		qb = qb.Where("status = ?", filter.Status)
+	}
+	if filter.Topic != "" {
+		qb = qb.Where("topic = ?", filter.Topic)
+	}
+	if filter.Start != nil {
+		qb = qb.Where("created_at >= ?", *filter.Start)
+	}
+	if filter.End != nil {
+		qb = qb.Where("created_at < ?", *filter.End)
+	}
+
+	var total int64
+	if err := qb.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var logs []domain.PushLog
+	if err := qb.Order("created_at desc").
+		Limit(limit).
+		Offset((page - 1) * limit).
+		Find(&logs).Error; err != nil {
+		return nil, 0, err
+	}
+	return logs, total, nil
+}
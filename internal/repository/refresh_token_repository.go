@@ -1,6 +1,8 @@
 package repository
 
 import (
+    "time"
+
     "unila_helpdesk_backend/internal/domain"
 
     "gorm.io/gorm"
@@ -29,3 +31,29 @@ func (repo *RefreshTokenRepository) FindByHash(hash string) (*domain.RefreshToke
 func (repo *RefreshTokenRepository) DeleteByID(id string) error {
     return repo.db.Delete(&domain.RefreshToken{}, "id = ?", id).Error
 }
+
+func (repo *RefreshTokenRepository) DeleteByUserAndHash(userID string, hash string) error {
+    return repo.db.Delete(&domain.RefreshToken{}, "user_id = ? AND token_hash = ?", userID, hash).Error
+}
+
+// MarkUsed menandai token sudah dirotasi. Mengembalikan false jika token sudah
+// dipakai sebelumnya, mis. dua refresh bersamaan dengan token yang sama.
+func (repo *RefreshTokenRepository) MarkUsed(id string, at time.Time) (bool, error) {
+    result := repo.db.Model(&domain.RefreshToken{}).
+        Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", id).
+        Update("used_at", at)
+    return result.RowsAffected == 1, result.Error
+}
+
+func (repo *RefreshTokenRepository) RevokeFamily(familyID string, at time.Time) error {
+    return repo.db.Model(&domain.RefreshToken{}).
+        Where("family_id = ? AND revoked_at IS NULL", familyID).
+        Update("revoked_at", at).Error
+}
+
+// DeleteExpired menghapus token yang kadaluarsa sebelum cutoff, termasuk token
+// bekas rotasi dan token yang sudah dicabut.
+func (repo *RefreshTokenRepository) DeleteExpired(cutoff time.Time) (int64, error) {
+    result := repo.db.Where("expires_at < ?", cutoff).Delete(&domain.RefreshToken{})
+    return result.RowsAffected, result.Error
+}
@@ -144,7 +144,7 @@ func (repo *ReportRepository) FindTemplateWithOrderedQuestions(templateID string
 func (repo *ReportRepository) ListSurveyResponsesByTicketCategoryAndTemplate(
 	start time.Time,
 	end time.Time,
-	categoryID string,
+	categoryIDs []string,
 	templateID string,
 	orderAsc bool,
 ) ([]domain.SurveyResponse, error) {
@@ -152,8 +152,8 @@ func (repo *ReportRepository) ListSurveyResponsesByTicketCategoryAndTemplate(
 	query := repo.db.Model(&domain.SurveyResponse{}).
 		Joins("JOIN tickets t ON t.id = survey_responses.ticket_id").
 		Where("survey_responses.created_at >= ? AND survey_responses.created_at < ?", start, end)
-	if categoryID != "" {
-		query = query.Where("t.category_id = ?", categoryID)
+	if len(categoryIDs) > 0 {
+		query = query.Where("t.category_id IN ?", categoryIDs)
 	}
 	if templateID != "" {
 		query = query.Where("survey_responses.template_id = ?", templateID)
@@ -168,11 +168,11 @@ func (repo *ReportRepository) ListSurveyResponsesByTicketCategoryAndTemplate(
 	return responses, nil
 }
 
-func (repo *ReportRepository) ListUsedTemplateIDsByCategory(categoryID string) ([]string, error) {
+func (repo *ReportRepository) ListUsedTemplateIDsByCategory(categoryIDs []string) ([]string, error) {
 	usedIDs := make([]string, 0)
 	if err := repo.db.Model(&domain.SurveyResponse{}).
 		Joins("JOIN tickets t ON t.id = survey_responses.ticket_id").
-		Where("t.category_id = ? AND survey_responses.template_id <> ''", categoryID).
+		Where("t.category_id IN ? AND survey_responses.template_id <> ''", categoryIDs).
 		Distinct().
 		Pluck("survey_responses.template_id", &usedIDs).Error; err != nil {
 		return nil, err
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"time"
+
+	"unila_helpdesk_backend/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type ServiceIncidentRepository struct {
+	db *gorm.DB
+}
+
+func NewServiceIncidentRepository(db *gorm.DB) *ServiceIncidentRepository {
+	return &ServiceIncidentRepository{db: db}
+}
+
+func (repo *ServiceIncidentRepository) Create(incident *domain.ServiceIncident) error {
+	return repo.db.Create(incident).Error
+}
+
+// Transition menyimpan perubahan status layanan dalam satu transaksi: pengumuman
+// baru, penutupan insiden lama beserta akhir tayang pengumumannya, dan insiden baru.
+// resolved dan opened boleh nil.
+func (repo *ServiceIncidentRepository) Transition(
+	announcement *domain.Announcement,
+	resolved *domain.ServiceIncident,
+	opened *domain.ServiceIncident,
+) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(announcement).Error; err != nil {
+			return err
+		}
+		if resolved != nil {
+			if err := tx.Save(resolved).Error; err != nil {
+				return err
+			}
+			if resolved.AnnouncementID != "" && resolved.ResolvedAt != nil {
+				if err := tx.Model(&domain.Announcement{}).
+					Where("id = ? AND (ends_at IS NULL OR ends_at > ?)", resolved.AnnouncementID, *resolved.ResolvedAt).
+					Update("ends_at", *resolved.ResolvedAt).Error; err != nil {
+					return err
+				}
+			}
+		}
+		if opened != nil {
+			return tx.Create(opened).Error
+		}
+		return nil
+	})
+}
+
+func (repo *ServiceIncidentRepository) FindOpenByCategory(categoryID string) (*domain.ServiceIncident, error) {
+	var incident domain.ServiceIncident
+	if err := repo.db.Where("category_id = ? AND resolved_at IS NULL", categoryID).
+		Order("started_at desc").
+		First(&incident).Error; err != nil {
+		return nil, err
+	}
+	return &incident, nil
+}
+
+// ListSince mengembalikan insiden yang masih terbuka atau selesai setelah start.
+func (repo *ServiceIncidentRepository) ListSince(start time.Time) ([]domain.ServiceIncident, error) {
+	incidents := make([]domain.ServiceIncident, 0)
+	if err := repo.db.Where("resolved_at IS NULL OR resolved_at >= ?", start).
+		Order("started_at asc").
+		Find(&incidents).Error; err != nil {
+		return nil, err
+	}
+	return incidents, nil
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"time"
+
+	"unila_helpdesk_backend/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type SessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+func (repo *SessionRepository) Create(session *domain.Session) error {
+	return repo.db.Create(session).Error
+}
+
+func (repo *SessionRepository) FindByID(id string) (*domain.Session, error) {
+	var session domain.Session
+	if err := repo.db.First(&session, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// ListActiveByUser mengembalikan sesi yang belum dicabut dan belum kadaluarsa.
+func (repo *SessionRepository) ListActiveByUser(userID string, now time.Time) ([]domain.Session, error) {
+	sessions := make([]domain.Session, 0)
+	if err := repo.db.
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("last_used_at DESC").
+		Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (repo *SessionRepository) Touch(id string, ipAddress string, userAgent string, expiresAt time.Time, at time.Time) error {
+	updates := map[string]any{
+		"last_used_at": at,
+		"expires_at":   expiresAt,
+		"updated_at":   at,
+	}
+	if ipAddress != "" {
+		updates["ip_address"] = ipAddress
+	}
+	if userAgent != "" {
+		updates["user_agent"] = userAgent
+	}
+	return repo.db.Model(&domain.Session{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// AttachFCMToken memindahkan push token ke sesi ini. Sesi lain yang memegang
+// token yang sama (login ulang di perangkat yang sama) dilepas dari token tersebut.
+func (repo *SessionRepository) AttachFCMToken(id string, token string, at time.Time) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.Session{}).
+			Where("fcm_token = ? AND id <> ?", token, id).
+			Updates(map[string]any{"fcm_token": "", "updated_at": at}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&domain.Session{}).
+			Where("id = ?", id).
+			Updates(map[string]any{"fcm_token": token, "updated_at": at}).Error
+	})
+}
+
+func (repo *SessionRepository) DetachFCMToken(userID string, token string, at time.Time) error {
+	return repo.db.Model(&domain.Session{}).
+		Where("user_id = ? AND fcm_token = ?", userID, token).
+		Updates(map[string]any{"fcm_token": "", "updated_at": at}).Error
+}
+
+// Revoke menandai sesi dan seluruh refresh token miliknya dicabut. Baris token
+// disimpan sampai kadaluarsa agar pemakaian ulang tetap terdeteksi.
+func (repo *SessionRepository) Revoke(id string, at time.Time) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.Session{}).
+			Where("id = ? AND revoked_at IS NULL", id).
+			Updates(map[string]any{"revoked_at": at, "updated_at": at}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&domain.RefreshToken{}).
+			Where("session_id = ? AND revoked_at IS NULL", id).
+			Update("revoked_at", at).Error
+	})
+}
+
+// RevokeAllByUser mencabut seluruh sesi dan refresh token user, termasuk token
+// lama yang belum terikat sesi.
+func (repo *SessionRepository) RevokeAllByUser(userID string, at time.Time) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.Session{}).
+			Where("user_id = ? AND revoked_at IS NULL", userID).
+			Updates(map[string]any{"revoked_at": at, "updated_at": at}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&domain.RefreshToken{}).
+			Where("user_id = ? AND revoked_at IS NULL", userID).
+			Update("revoked_at", at).Error
+	})
+}
+
+// DeleteStale menghapus sesi yang sudah kadaluarsa atau dicabut sebelum cutoff.
+func (repo *SessionRepository) DeleteStale(cutoff time.Time) (int64, error) {
+	result := repo.db.Where("expires_at < ? OR revoked_at < ?", cutoff, cutoff).Delete(&domain.Session{})
+	return result.RowsAffected, result.Error
+}
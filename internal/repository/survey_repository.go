@@ -1,9 +1,11 @@
 package repository
 
 import (
+    "slices"
     "time"
 
     "unila_helpdesk_backend/internal/domain"
+    "unila_helpdesk_backend/internal/util"
 
     "gorm.io/gorm"
 )
@@ -47,17 +49,29 @@ func (repo *SurveyRepository) ListTemplates() ([]domain.SurveyTemplate, error) {
     return templates, nil
 }
 
+// FindByCategory mengembalikan template kategori, atau template parent terdekat
+// jika kategori tidak menetapkan template sendiri.
 func (repo *SurveyRepository) FindByCategory(categoryID string) (*domain.SurveyTemplate, error) {
-    var category domain.ServiceCategory
-    if err := repo.db.First(&category, "id = ?", categoryID).Error; err != nil {
-        return nil, err
+    templateID := ""
+    visited := make(map[string]bool)
+    for current := categoryID; current != "" && !visited[current]; {
+        visited[current] = true
+        var category domain.ServiceCategory
+        if err := repo.db.First(&category, "id = ?", current).Error; err != nil {
+            return nil, err
+        }
+        if category.SurveyTemplateID != "" {
+            templateID = category.SurveyTemplateID
+            break
+        }
+        current = category.ParentID
     }
-    if category.SurveyTemplateID == "" {
+    if templateID == "" {
         return nil, gorm.ErrRecordNotFound
     }
     var template domain.SurveyTemplate
     if err := repo.db.Preload("Questions").
-        First(&template, "id = ?", category.SurveyTemplateID).Error; err != nil {
+        First(&template, "id = ?", templateID).Error; err != nil {
         return nil, err
     }
     return &template, nil
@@ -142,6 +156,79 @@ func (repo *SurveyRepository) ListResponses(
         limit = 20
     }
 
+    base := repo.responsesQuery(filter)
+
+    var total int64
+    if err := base.Count(&total).Error; err != nil {
+        return nil, 0, err
+    }
+
+    var rows []SurveyResponseRow
+    if err := base.Select(surveyResponseColumns).
+        Order("sr.created_at desc, sr.id desc").
+        Limit(limit).
+        Offset((page - 1) * limit).
+        Scan(&rows).Error; err != nil {
+        return nil, 0, err
+    }
+    return rows, total, nil
+}
+
+// ListResponsesCursor mengambil respon survey terbaru dengan keyset pagination
+// pada (created_at, id) tanpa menghitung total.
+func (repo *SurveyRepository) ListResponsesCursor(
+    filter SurveyResponseFilter,
+    cursor *util.Cursor,
+    limit int,
+) ([]SurveyResponseRow, bool, error) {
+    if limit <= 0 {
+        limit = 20
+    }
+
+    base := repo.responsesQuery(filter)
+    direction := "desc"
+    if cursor != nil {
+        comparator := "<"
+        if cursor.Backward {
+            comparator = ">"
+            direction = "asc"
+        }
+        base = base.Where("(sr.created_at, sr.id) "+comparator+" (?, ?)", cursor.CreatedAt, cursor.ID)
+    }
+
+    var rows []SurveyResponseRow
+    if err := base.Select(surveyResponseColumns).
+        Order("sr.created_at " + direction + ", sr.id " + direction).
+        Limit(limit + 1).
+        Scan(&rows).Error; err != nil {
+        return nil, false, err
+    }
+    hasMore := len(rows) > limit
+    if hasMore {
+        rows = rows[:limit]
+    }
+    if cursor != nil && cursor.Backward {
+        slices.Reverse(rows)
+    }
+    return rows, hasMore, nil
+}
+
+const surveyResponseColumns = `
+    sr.id,
+    sr.ticket_id,
+    sr.user_id,
+    sr.template_id,
+    sr.score,
+    sr.created_at,
+    u.name as user_name,
+    u.email as user_email,
+    u.entity as user_entity,
+    t.category_id as category_id,
+    sc.name as category_name,
+    st.title as template_title
+`
+
+func (repo *SurveyRepository) responsesQuery(filter SurveyResponseFilter) *gorm.DB {
     base := repo.db.Table("survey_responses sr").
         Joins("JOIN users u ON u.id = sr.user_id").
         Joins("JOIN tickets t ON t.id = sr.ticket_id").
@@ -164,32 +251,5 @@ func (repo *SurveyRepository) ListResponses(
     if filter.End != nil {
         base = base.Where("sr.created_at < ?", *filter.End)
     }
-
-    var total int64
-    if err := base.Count(&total).Error; err != nil {
-        return nil, 0, err
-    }
-
-    var rows []SurveyResponseRow
-    if err := base.Select(`
-            sr.id,
-            sr.ticket_id,
-            sr.user_id,
-            sr.template_id,
-            sr.score,
-            sr.created_at,
-            u.name as user_name,
-            u.email as user_email,
-            u.entity as user_entity,
-            t.category_id as category_id,
-            sc.name as category_name,
-            st.title as template_title
-        `).
-        Order("sr.created_at desc").
-        Limit(limit).
-        Offset((page - 1) * limit).
-        Scan(&rows).Error; err != nil {
-        return nil, 0, err
-    }
-    return rows, total, nil
+    return base
 }
@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"unila_helpdesk_backend/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TagRepository struct {
+	db *gorm.DB
+}
+
+type TagUsageRow struct {
+	TagID string
+	Total int64
+}
+
+type TagCountRow struct {
+	TagID string
+	Name  string
+	Total int64
+}
+
+func NewTagRepository(db *gorm.DB) *TagRepository {
+	return &TagRepository{db: db}
+}
+
+func (repo *TagRepository) List() ([]domain.Tag, error) {
+	var tags []domain.Tag
+	if err := repo.db.Order("name asc").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (repo *TagRepository) FindByID(tagID string) (*domain.Tag, error) {
+	var tag domain.Tag
+	if err := repo.db.First(&tag, "id = ?", tagID).Error; err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (repo *TagRepository) FindByName(name string) (*domain.Tag, error) {
+	var tag domain.Tag
+	if err := repo.db.First(&tag, "name = ?", name).Error; err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (repo *TagRepository) FindByNames(names []string) ([]domain.Tag, error) {
+	tags := make([]domain.Tag, 0)
+	if len(names) == 0 {
+		return tags, nil
+	}
+	if err := repo.db.Where("name IN ?", names).Order("name asc").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (repo *TagRepository) Create(tag *domain.Tag) error {
+	return repo.db.Create(tag).Error
+}
+
+func (repo *TagRepository) Update(tag *domain.Tag) error {
+	return repo.db.Save(tag).Error
+}
+
+// Delete menghapus tag beserta seluruh relasinya dengan tiket.
+func (repo *TagRepository) Delete(tagID string) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tag_id = ?", tagID).Delete(&domain.TicketTag{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&domain.Tag{}, "id = ?", tagID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+func (repo *TagRepository) UsageCounts() (map[string]int64, error) {
+	var rows []TagUsageRow
+	if err := repo.db.Model(&domain.TicketTag{}).
+		Select("tag_id, count(*) as total").
+		Group("tag_id").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.TagID] = row.Total
+	}
+	return counts, nil
+}
+
+// ErrTicketTagLimit dikembalikan AddToTicket jika tag baru membuat jumlah tag tiket
+// melebihi batas.
+var ErrTicketTagLimit = errors.New("batas tag per tiket terlampaui")
+
+// AddToTicket menautkan tag ke tiket dan mengembalikan ID tag yang benar-benar baru;
+// tag yang sudah terpasang diabaikan. Baris tiket dikunci selama transaksi sehingga
+// penghitungan batas dan insert tidak dapat diselingi request lain.
+func (repo *TagRepository) AddToTicket(ticketID string, tagIDs []string, createdBy string, limit int) ([]string, error) {
+	added := make([]string, 0, len(tagIDs))
+	if len(tagIDs) == 0 {
+		return added, nil
+	}
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		var ticket domain.Ticket
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			First(&ticket, "id = ?", ticketID).Error; err != nil {
+			return err
+		}
+		var existing []string
+		if err := tx.Model(&domain.TicketTag{}).
+			Where("ticket_id = ?", ticketID).
+			Pluck("tag_id", &existing).Error; err != nil {
+			return err
+		}
+		for _, tagID := range tagIDs {
+			if !slices.Contains(existing, tagID) && !slices.Contains(added, tagID) {
+				added = append(added, tagID)
+			}
+		}
+		if len(existing)+len(added) > limit {
+			return ErrTicketTagLimit
+		}
+		if len(added) == 0 {
+			return nil
+		}
+		now := time.Now()
+		links := make([]domain.TicketTag, 0, len(added))
+		for _, tagID := range added {
+			links = append(links, domain.TicketTag{
+				TicketID:  ticketID,
+				TagID:     tagID,
+				CreatedBy: createdBy,
+				CreatedAt: now,
+			})
+		}
+		return tx.Create(&links).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return added, nil
+}
+
+func (repo *TagRepository) RemoveFromTicket(ticketID string, tagID string) (bool, error) {
+	result := repo.db.Where("ticket_id = ? AND tag_id = ?", ticketID, tagID).Delete(&domain.TicketTag{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (repo *TagRepository) ListForTicket(ticketID string) ([]domain.Tag, error) {
+	tags := make([]domain.Tag, 0)
+	if err := repo.db.Model(&domain.Tag{}).
+		Joins("JOIN ticket_tags tt ON tt.tag_id = tags.id").
+		Where("tt.ticket_id = ?", ticketID).
+		Order("tags.name asc").
+		Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// ListTicketTotalsByTag menghitung tiket per tag pada rentang waktu, opsional dibatasi kategori.
+func (repo *TagRepository) ListTicketTotalsByTag(start time.Time, end time.Time, categoryIDs []string) ([]TagCountRow, error) {
+	var rows []TagCountRow
+	query := repo.db.Table("ticket_tags tt").
+		Select("tg.id as tag_id, tg.name as name, count(*) as total").
+		Joins("JOIN tags tg ON tg.id = tt.tag_id").
+		Joins("JOIN tickets t ON t.id = tt.ticket_id AND t.deleted_at IS NULL").
+		Where("t.created_at >= ? AND t.created_at < ?", start, end)
+	if len(categoryIDs) > 0 {
+		query = query.Where("t.category_id IN ?", categoryIDs)
+	}
+	if err := query.Group("tg.id, tg.name").Order("total desc").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func orderTags(db *gorm.DB) *gorm.DB {
+	return db.Order("tags.name asc")
+}
@@ -2,9 +2,13 @@ package repository
 
 import (
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
 	"time"
 
 	"unila_helpdesk_backend/internal/domain"
+	"unila_helpdesk_backend/internal/util"
 
 	"gorm.io/gorm"
 )
@@ -13,14 +17,58 @@ type TicketRepository struct {
 	db *gorm.DB
 }
 
+// TicketListFilter juga disimpan sebagai JSON pada saved view, sehingga
+// tag json di bawah adalah format persistensi yang harus dijaga kompatibel.
 type TicketListFilter struct {
-	Query      string
-	Status     *domain.TicketStatus
-	CategoryID string
-	Start      *time.Time
-	End        *time.Time
-	ReporterID string
-	IsGuest    *bool
+	Query          string                  `json:"q,omitempty"`
+	Statuses       []domain.TicketStatus   `json:"statuses,omitempty"`
+	Priorities     []domain.TicketPriority `json:"priorities,omitempty"`
+	CategoryID     string                  `json:"categoryId,omitempty"`
+	Start          *time.Time              `json:"start,omitempty"`
+	End            *time.Time              `json:"end,omitempty"`
+	OlderThanHours int                     `json:"olderThanHours,omitempty"`
+	ReporterID     string                  `json:"reporterId,omitempty"`
+	ReporterEntity string                  `json:"entity,omitempty"`
+	Assignee       string                  `json:"assignee,omitempty"`
+	IsGuest        *bool                   `json:"guest,omitempty"`
+	HasSurvey      *bool                   `json:"hasSurvey,omitempty"`
+	SLABreached    *bool                   `json:"slaBreached,omitempty"`
+	CustomFields   map[string]string       `json:"fields,omitempty"`
+	Tags           []string                `json:"tags,omitempty"`
+}
+
+// IsEmpty bernilai true jika tidak ada kriteria yang diisi, yaitu filter cocok
+// dengan semua tiket.
+func (filter TicketListFilter) IsEmpty() bool {
+	return strings.TrimSpace(filter.Query) == "" &&
+		len(filter.Statuses) == 0 &&
+		len(filter.Priorities) == 0 &&
+		filter.CategoryID == "" &&
+		filter.Start == nil &&
+		filter.End == nil &&
+		filter.OlderThanHours <= 0 &&
+		filter.ReporterID == "" &&
+		filter.ReporterEntity == "" &&
+		filter.Assignee == "" &&
+		filter.IsGuest == nil &&
+		filter.HasSurvey == nil &&
+		filter.SLABreached == nil &&
+		len(filter.CustomFields) == 0 &&
+		len(filter.Tags) == 0
+}
+
+type TicketSortField string
+
+const (
+	TicketSortCreated  TicketSortField = "created"
+	TicketSortUpdated  TicketSortField = "updated"
+	TicketSortPriority TicketSortField = "priority"
+	TicketSortDue      TicketSortField = "due"
+)
+
+type TicketListSort struct {
+	Field TicketSortField `json:"field,omitempty"`
+	Asc   bool            `json:"asc,omitempty"`
 }
 
 func NewTicketRepository(db *gorm.DB) *TicketRepository {
@@ -31,21 +79,125 @@ func (repo *TicketRepository) Create(ticket *domain.Ticket) error {
 	return repo.db.Create(ticket).Error
 }
 
+// Update tidak menyentuh relasi tag; tag diubah lewat TagRepository agar perubahan
+// bersamaan tidak tertimpa oleh data tag yang sudah dimuat.
 func (repo *TicketRepository) Update(ticket *domain.Ticket) error {
-	return repo.db.Save(ticket).Error
+	return repo.db.Omit("Tags").Save(ticket).Error
 }
 
-func (repo *TicketRepository) SoftDelete(ticketID string) error {
-	return repo.db.Delete(&domain.Ticket{}, "id = ?", ticketID).Error
+// SoftDelete memindahkan tiket ke trash sambil mencatat siapa yang menghapus dan alasannya.
+func (repo *TicketRepository) SoftDelete(ticketID string, deletedBy string, deletedByName string, reason string) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.Ticket{}).Where("id = ?", ticketID).Updates(map[string]any{
+			"deleted_by":      deletedBy,
+			"deleted_by_name": deletedByName,
+			"delete_reason":   reason,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&domain.Ticket{}, "id = ?", ticketID).Error
+	})
+}
+
+func (repo *TicketRepository) FindDeletedByID(ticketID string) (*domain.Ticket, error) {
+	var ticket domain.Ticket
+	if err := repo.db.Unscoped().Preload("Category").Preload("Tags", orderTags).
+		Where("deleted_at IS NOT NULL").
+		First(&ticket, "id = ?", ticketID).Error; err != nil {
+		return nil, err
+	}
+	return &ticket, nil
+}
+
+func (repo *TicketRepository) ListDeleted(query string, page int, limit int) ([]domain.Ticket, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+	qb := repo.db.Unscoped().Model(&domain.Ticket{}).Where("deleted_at IS NOT NULL")
+	if query != "" {
+		like := "%" + query + "%"
+		qb = qb.Where("id ILIKE ? OR title ILIKE ?", like, like)
+	}
+
+	var total int64
+	if err := qb.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var tickets []domain.Ticket
+	if err := qb.Preload("Category").Preload("Tags", orderTags).
+		Order("deleted_at desc").
+		Limit(limit).
+		Offset((page - 1) * limit).
+		Find(&tickets).Error; err != nil {
+		return nil, 0, err
+	}
+	return tickets, total, nil
+}
+
+func (repo *TicketRepository) Restore(ticketID string) error {
+	result := repo.db.Unscoped().Model(&domain.Ticket{}).
+		Where("id = ? AND deleted_at IS NOT NULL", ticketID).
+		Updates(map[string]any{
+			"deleted_at":      nil,
+			"deleted_by":      "",
+			"deleted_by_name": "",
+			"delete_reason":   "",
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Purge menghapus permanen tiket yang sudah berada di trash beserta seluruh data turunannya.
+func (repo *TicketRepository) Purge(ticketID string) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Unscoped().Model(&domain.Ticket{}).
+			Where("id = ? AND deleted_at IS NOT NULL", ticketID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if err := tx.Where(
+			"comment_id IN (?)",
+			tx.Model(&domain.TicketComment{}).Select("id").Where("ticket_id = ?", ticketID),
+		).Delete(&domain.TicketCommentArticle{}).Error; err != nil {
+			return err
+		}
+		dependents := []any{
+			&domain.TicketTag{},
+			&domain.TicketHistory{},
+			&domain.TicketComment{},
+			&domain.Attachment{},
+			&domain.SurveyResponse{},
+			&domain.Notification{},
+		}
+		for _, model := range dependents {
+			if err := tx.Where("ticket_id = ?", ticketID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Unscoped().Delete(&domain.Ticket{}, "id = ?", ticketID).Error
+	})
 }
 
 func (repo *TicketRepository) FindByID(ticketID string) (*domain.Ticket, error) {
 	var ticket domain.Ticket
-	if err := repo.db.Preload("Category").Preload("History", func(db *gorm.DB) *gorm.DB {
+	if err := repo.db.Preload("Category").Preload("Tags", orderTags).Preload("History", func(db *gorm.DB) *gorm.DB {
 		return db.Order("timestamp desc")
 	}).Preload("Comments", func(db *gorm.DB) *gorm.DB {
 		return db.Order("timestamp asc")
-	}).First(&ticket, "id = ?", ticketID).Error; err != nil {
+	}).Preload("Comments.Articles").First(&ticket, "id = ?", ticketID).Error; err != nil {
 		return nil, err
 	}
 	return &ticket, nil
@@ -53,7 +205,7 @@ func (repo *TicketRepository) FindByID(ticketID string) (*domain.Ticket, error)
 
 func (repo *TicketRepository) ListByUser(userID string) ([]domain.Ticket, error) {
 	var tickets []domain.Ticket
-	if err := repo.db.Preload("Category").Where("reporter_id = ?", userID).Order("created_at desc").Find(&tickets).Error; err != nil {
+	if err := repo.db.Preload("Category").Preload("Tags", orderTags).Where("reporter_id = ?", userID).Order("created_at desc").Find(&tickets).Error; err != nil {
 		return nil, err
 	}
 	return tickets, nil
@@ -61,7 +213,7 @@ func (repo *TicketRepository) ListByUser(userID string) ([]domain.Ticket, error)
 
 func (repo *TicketRepository) ListAll() ([]domain.Ticket, error) {
 	var tickets []domain.Ticket
-	if err := repo.db.Preload("Category").Order("created_at desc").Find(&tickets).Error; err != nil {
+	if err := repo.db.Preload("Category").Preload("Tags", orderTags).Order("created_at desc").Find(&tickets).Error; err != nil {
 		return nil, err
 	}
 	return tickets, nil
@@ -69,7 +221,7 @@ func (repo *TicketRepository) ListAll() ([]domain.Ticket, error) {
 
 func (repo *TicketRepository) Search(query string, isGuest bool) ([]domain.Ticket, error) {
 	var tickets []domain.Ticket
-	qb := repo.db.Preload("Category").Order("created_at desc")
+	qb := repo.db.Preload("Category").Preload("Tags", orderTags).Order("created_at desc")
 	if query != "" {
 		like := "%" + query + "%"
 		qb = qb.Where("id ILIKE ? OR title ILIKE ?", like, like)
@@ -85,6 +237,7 @@ func (repo *TicketRepository) Search(query string, isGuest bool) ([]domain.Ticke
 
 func (repo *TicketRepository) ListFiltered(
 	filter TicketListFilter,
+	sort TicketListSort,
 	page int,
 	limit int,
 ) ([]domain.Ticket, int64, error) {
@@ -95,44 +248,177 @@ func (repo *TicketRepository) ListFiltered(
 		limit = 20
 	}
 
-	qb := repo.db.Model(&domain.Ticket{})
+	qb := applyTicketFilter(repo.db.Model(&domain.Ticket{}), filter)
+
+	var total int64
+	if err := qb.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var tickets []domain.Ticket
+	if err := qb.Preload("Category").Preload("Tags", orderTags).
+		Order(ticketOrderClause(sort)).
+		Limit(limit).
+		Offset((page - 1) * limit).
+		Find(&tickets).Error; err != nil {
+		return nil, 0, err
+	}
+	return tickets, total, nil
+}
+
+// ListFilteredCursor mengambil tiket berurutan created_at dengan keyset pagination.
+// Mengembalikan true jika masih ada data setelah halaman ini pada arah pengambilan.
+func (repo *TicketRepository) ListFilteredCursor(
+	filter TicketListFilter,
+	asc bool,
+	cursor *util.Cursor,
+	limit int,
+) ([]domain.Ticket, bool, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+
+	scanAsc := asc
+	if cursor != nil && cursor.Backward {
+		scanAsc = !asc
+	}
+	direction := "desc"
+	comparator := "<"
+	if scanAsc {
+		direction = "asc"
+		comparator = ">"
+	}
+
+	qb := applyTicketFilter(repo.db.Model(&domain.Ticket{}), filter)
+	if cursor != nil {
+		qb = qb.Where("(tickets.created_at, tickets.id) "+comparator+" (?, ?)", cursor.CreatedAt, cursor.ID)
+	}
+
+	var tickets []domain.Ticket
+	if err := qb.Preload("Category").Preload("Tags", orderTags).
+		Order("tickets.created_at " + direction + ", tickets.id " + direction).
+		Limit(limit + 1).
+		Find(&tickets).Error; err != nil {
+		return nil, false, err
+	}
+	hasMore := len(tickets) > limit
+	if hasMore {
+		tickets = tickets[:limit]
+	}
+	if cursor != nil && cursor.Backward {
+		slices.Reverse(tickets)
+	}
+	return tickets, hasMore, nil
+}
+
+func (repo *TicketRepository) CountFiltered(filter TicketListFilter) (int64, error) {
+	var total int64
+	if err := applyTicketFilter(repo.db.Model(&domain.Ticket{}), filter).Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// ListIDsFiltered mengembalikan ID tiket yang cocok dengan filter dan lebih besar
+// dari afterID (kosong berarti dari awal), urut ID, dibatasi limit.
+func (repo *TicketRepository) ListIDsFiltered(filter TicketListFilter, afterID string, limit int) ([]string, error) {
+	ids := make([]string, 0)
+	qb := applyTicketFilter(repo.db.Model(&domain.Ticket{}), filter)
+	if afterID != "" {
+		qb = qb.Where("tickets.id > ?", afterID)
+	}
+	if err := qb.
+		Order("tickets.id asc").
+		Limit(limit).
+		Pluck("tickets.id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func applyTicketFilter(qb *gorm.DB, filter TicketListFilter) *gorm.DB {
 	if filter.Query != "" {
 		like := "%" + filter.Query + "%"
-		qb = qb.Where("id ILIKE ? OR title ILIKE ?", like, like)
+		qb = qb.Where("tickets.id ILIKE ? OR tickets.title ILIKE ?", like, like)
 	}
-	if filter.Status != nil {
-		qb = qb.Where("status = ?", *filter.Status)
+	if len(filter.Statuses) > 0 {
+		qb = qb.Where("tickets.status IN ?", filter.Statuses)
+	}
+	if len(filter.Priorities) > 0 {
+		qb = qb.Where("tickets.priority IN ?", filter.Priorities)
 	}
 	if filter.CategoryID != "" {
-		qb = qb.Where("category_id = ?", filter.CategoryID)
+		qb = qb.Where("tickets.category_id = ?", filter.CategoryID)
 	}
 	if filter.ReporterID != "" {
-		qb = qb.Where("reporter_id = ?", filter.ReporterID)
+		qb = qb.Where("tickets.reporter_id = ?", filter.ReporterID)
+	}
+	if filter.ReporterEntity != "" {
+		qb = qb.Where(
+			"tickets.reporter_id IN (SELECT id FROM users WHERE lower(entity) = lower(?))",
+			filter.ReporterEntity,
+		)
+	}
+	if filter.Assignee != "" {
+		qb = qb.Where("lower(tickets.assignee) = lower(?)", filter.Assignee)
 	}
 	if filter.IsGuest != nil {
-		qb = qb.Where("is_guest = ?", *filter.IsGuest)
+		qb = qb.Where("tickets.is_guest = ?", *filter.IsGuest)
+	}
+	if filter.HasSurvey != nil {
+		exists := "EXISTS (SELECT 1 FROM survey_responses sr WHERE sr.ticket_id = tickets.id)"
+		if *filter.HasSurvey {
+			qb = qb.Where(exists)
+		} else {
+			qb = qb.Where("NOT " + exists)
+		}
+	}
+	if filter.SLABreached != nil {
+		breached := "tickets.status <> ? AND tickets.due_at IS NOT NULL AND tickets.due_at < NOW()"
+		if *filter.SLABreached {
+			qb = qb.Where(breached, domain.StatusResolved)
+		} else {
+			qb = qb.Not(breached, domain.StatusResolved)
+		}
 	}
 	if filter.Start != nil {
-		qb = qb.Where("created_at >= ?", *filter.Start)
+		qb = qb.Where("tickets.created_at >= ?", *filter.Start)
 	}
 	if filter.End != nil {
-		qb = qb.Where("created_at < ?", *filter.End)
+		qb = qb.Where("tickets.created_at < ?", *filter.End)
 	}
-
-	var total int64
-	if err := qb.Count(&total).Error; err != nil {
-		return nil, 0, err
+	if filter.OlderThanHours > 0 {
+		qb = qb.Where("tickets.created_at < NOW() - (? * interval '1 hour')", filter.OlderThanHours)
+	}
+	if len(filter.Tags) > 0 {
+		qb = qb.Where(
+			"EXISTS (SELECT 1 FROM ticket_tags tt JOIN tags tg ON tg.id = tt.tag_id "+
+				"WHERE tt.ticket_id = tickets.id AND tg.name IN ?)",
+			filter.Tags,
+		)
+	}
+	for _, key := range slices.Sorted(maps.Keys(filter.CustomFields)) {
+		qb = qb.Where("lower(tickets.custom_fields ->> ?) = lower(?)", key, filter.CustomFields[key])
 	}
+	return qb
+}
 
-	var tickets []domain.Ticket
-	if err := qb.Preload("Category").
-		Order("created_at desc").
-		Limit(limit).
-		Offset((page - 1) * limit).
-		Find(&tickets).Error; err != nil {
-		return nil, 0, err
+func ticketOrderClause(sort TicketListSort) string {
+	direction := "desc"
+	if sort.Asc {
+		direction = "asc"
+	}
+	switch sort.Field {
+	case TicketSortUpdated:
+		return "tickets.updated_at " + direction + ", tickets.id " + direction
+	case TicketSortPriority:
+		return "CASE tickets.priority WHEN 'high' THEN 3 WHEN 'medium' THEN 2 WHEN 'low' THEN 1 ELSE 0 END " +
+			direction + ", tickets.created_at " + direction + ", tickets.id " + direction
+	case TicketSortDue:
+		return "tickets.due_at " + direction + " NULLS LAST, tickets.id " + direction
+	default:
+		return "tickets.created_at " + direction + ", tickets.id " + direction
 	}
-	return tickets, total, nil
 }
 
 func (repo *TicketRepository) NextTicketSequence(year int) (int64, error) {
@@ -212,3 +498,42 @@ func (repo *TicketRepository) GetSurveyScores(ticketIDs []string) (map[string]fl
 	}
 	return scores, nil
 }
+
+// ListOpenReporterIDs mengembalikan pelapor terdaftar yang masih memiliki tiket
+// belum selesai pada kategori tertentu.
+func (repo *TicketRepository) ListOpenReporterIDs(categoryIDs []string) ([]string, error) {
+	ids := make([]string, 0)
+	if len(categoryIDs) == 0 {
+		return ids, nil
+	}
+	if err := repo.db.Model(&domain.Ticket{}).
+		Distinct("reporter_id").
+		Where("category_id IN ? AND status <> ? AND reporter_id <> '' AND is_guest = ?", categoryIDs, domain.StatusResolved, false).
+		Pluck("reporter_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// ListSLAWarningCandidates mengembalikan tiket belum selesai yang jatuh tempo
+// di antara since dan deadline dan belum pernah diperingatkan.
+func (repo *TicketRepository) ListSLAWarningCandidates(since time.Time, deadline time.Time, limit int) ([]domain.Ticket, error) {
+	tickets := make([]domain.Ticket, 0)
+	if err := repo.db.
+		Where("status <> ? AND due_at > ? AND due_at <= ? AND sla_warned_at IS NULL", domain.StatusResolved, since, deadline).
+		Order("due_at ASC").
+		Limit(limit).
+		Find(&tickets).Error; err != nil {
+		return nil, err
+	}
+	return tickets, nil
+}
+
+func (repo *TicketRepository) MarkSLAWarned(ticketIDs []string, at time.Time) error {
+	if len(ticketIDs) == 0 {
+		return nil
+	}
+	return repo.db.Model(&domain.Ticket{}).
+		Where("id IN ?", ticketIDs).
+		UpdateColumn("sla_warned_at", at).Error
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"unila_helpdesk_backend/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type TicketViewRepository struct {
+	db *gorm.DB
+}
+
+func NewTicketViewRepository(db *gorm.DB) *TicketViewRepository {
+	return &TicketViewRepository{db: db}
+}
+
+func (repo *TicketViewRepository) Create(view *domain.TicketView) error {
+	return repo.db.Create(view).Error
+}
+
+func (repo *TicketViewRepository) Update(view *domain.TicketView) error {
+	return repo.db.Save(view).Error
+}
+
+func (repo *TicketViewRepository) Delete(viewID string) error {
+	return repo.db.Delete(&domain.TicketView{}, "id = ?", viewID).Error
+}
+
+func (repo *TicketViewRepository) FindByID(viewID string) (*domain.TicketView, error) {
+	var view domain.TicketView
+	if err := repo.db.First(&view, "id = ?", viewID).Error; err != nil {
+		return nil, err
+	}
+	return &view, nil
+}
+
+// ListVisible mengembalikan view milik user dan view yang dibagikan ke semua staf.
+func (repo *TicketViewRepository) ListVisible(userID string) ([]domain.TicketView, error) {
+	var views []domain.TicketView
+	if err := repo.db.Where("owner_id = ? OR shared = ?", userID, true).
+		Order("name asc").
+		Find(&views).Error; err != nil {
+		return nil, err
+	}
+	return views, nil
+}
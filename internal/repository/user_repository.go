@@ -31,3 +31,79 @@ func (repo *UserRepository) FindByUsername(username string) (*domain.User, error
 	}
 	return &user, nil
 }
+
+func (repo *UserRepository) ListActiveByRole(role domain.UserRole) ([]domain.User, error) {
+	users := make([]domain.User, 0)
+	if err := repo.db.Where("role = ? AND is_active = ?", role, true).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// RevokeTokens menaikkan token_version sehingga seluruh access token user ditolak.
+func (repo *UserRepository) RevokeTokens(id string) error {
+	return repo.db.Model(&domain.User{}).Where("id = ?", id).
+		UpdateColumn("token_version", gorm.Expr("token_version + 1")).Error
+}
+
+// SetActive mengubah status akun sekaligus mencabut access token yang sudah terbit.
+func (repo *UserRepository) SetActive(id string, active bool) error {
+	return repo.db.Model(&domain.User{}).Where("id = ?", id).Updates(map[string]any{
+		"is_active":     active,
+		"token_version": gorm.Expr("token_version + 1"),
+	}).Error
+}
+
+func (repo *UserRepository) FindByEmail(email string) (*domain.User, error) {
+	var user domain.User
+	if err := repo.db.Where("email = ?", strings.ToLower(strings.TrimSpace(email))).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// FindByIdentity mencari akun yang tertaut dengan identitas provider eksternal.
+func (repo *UserRepository) FindByIdentity(provider string, subject string) (*domain.User, error) {
+	var user domain.User
+	if err := repo.db.
+		Joins("JOIN user_identities ON user_identities.user_id = users.id").
+		Where("user_identities.provider = ? AND user_identities.subject = ?", provider, subject).
+		First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (repo *UserRepository) FindIdentity(userID string, provider string) (*domain.UserIdentity, error) {
+	var identity domain.UserIdentity
+	if err := repo.db.Where("user_id = ? AND provider = ?", userID, provider).First(&identity).Error; err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (repo *UserRepository) CreateIdentity(identity *domain.UserIdentity) error {
+	return repo.db.Create(identity).Error
+}
+
+// CreateWithIdentity membuat akun baru beserta identitas eksternalnya.
+func (repo *UserRepository) CreateWithIdentity(user *domain.User, identity *domain.UserIdentity) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		return tx.Create(identity).Error
+	})
+}
+
+func (repo *UserRepository) UsernameExists(username string) (bool, error) {
+	var count int64
+	if err := repo.db.Unscoped().Model(&domain.User{}).Where("username = ?", strings.ToLower(username)).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (repo *UserRepository) Update(user *domain.User) error {
+	return repo.db.Save(user).Error
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"time"
+
+	"unila_helpdesk_backend/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WebPushRepository struct {
+	db *gorm.DB
+}
+
+func NewWebPushRepository(db *gorm.DB) *WebPushRepository {
+	return &WebPushRepository{db: db}
+}
+
+// UpsertSubscription mengganti subscription dengan endpoint yang sama sehingga
+// browser yang dipakai bergantian tidak mengirim notifikasi ke akun lama.
+func (repo *WebPushRepository) UpsertSubscription(subscription *domain.WebPushSubscription) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("endpoint = ?", subscription.Endpoint).Delete(&domain.WebPushSubscription{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(subscription).Error
+	})
+}
+
+func (repo *WebPushRepository) ListByUser(userID string) ([]domain.WebPushSubscription, error) {
+	items := make([]domain.WebPushSubscription, 0)
+	if err := repo.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (repo *WebPushRepository) ListByUsers(userIDs []string) ([]domain.WebPushSubscription, error) {
+	items := make([]domain.WebPushSubscription, 0)
+	if len(userIDs) == 0 {
+		return items, nil
+	}
+	if err := repo.db.Where("user_id IN ?", userIDs).Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (repo *WebPushRepository) DeleteByUserAndEndpoint(userID string, endpoint string) error {
+	if userID == "" || endpoint == "" {
+		return nil
+	}
+	return repo.db.Where("user_id = ? AND endpoint = ?", userID, endpoint).Delete(&domain.WebPushSubscription{}).Error
+}
+
+func (repo *WebPushRepository) DeleteByIDs(ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return repo.db.Where("id IN ?", ids).Delete(&domain.WebPushSubscription{}).Error
+}
+
+func (repo *WebPushRepository) TouchSubscriptions(ids []string, at time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return repo.db.Model(&domain.WebPushSubscription{}).Where("id IN ?", ids).Update("last_used_at", at).Error
+}
+
+func (repo *WebPushRepository) FindPreference(userID string) (*domain.WebPushPreference, error) {
+	var preference domain.WebPushPreference
+	if err := repo.db.First(&preference, "user_id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+	return &preference, nil
+}
+
+func (repo *WebPushRepository) ListPreferences(userIDs []string) ([]domain.WebPushPreference, error) {
+	items := make([]domain.WebPushPreference, 0)
+	if len(userIDs) == 0 {
+		return items, nil
+	}
+	if err := repo.db.Where("user_id IN ?", userIDs).Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (repo *WebPushRepository) SavePreference(preference *domain.WebPushPreference) error {
+	return repo.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"category_ids", "new_tickets", "sla_warnings", "updated_at"}),
+	}).Create(preference).Error
+}
@@ -0,0 +1,464 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"unila_helpdesk_backend/internal/domain"
+	"unila_helpdesk_backend/internal/fcm"
+	"unila_helpdesk_backend/internal/repository"
+	"unila_helpdesk_backend/internal/util"
+
+	"gorm.io/datatypes"
+)
+
+const (
+	// BroadcastAll mengirim ke topic global yang diikuti semua token terdaftar.
+	BroadcastAll = "all"
+	// BroadcastAffected mengirim ke pengguna dengan tiket terbuka di kategori terdampak.
+	BroadcastAffected = "affected"
+)
+
+type AnnouncementService struct {
+	announcements *repository.AnnouncementRepository
+	categories    *repository.CategoryRepository
+	tickets       *repository.TicketRepository
+	notifications *repository.NotificationRepository
+	tokens        *repository.FCMTokenRepository
+	fcmClient     *fcm.Client
+	audit         *AuditService
+	now           func() time.Time
+}
+
+type AnnouncementRequest struct {
+	Title       string                      `json:"title"`
+	Body        string                      `json:"body"`
+	Severity    domain.AnnouncementSeverity `json:"severity"`
+	CategoryIDs []string                    `json:"categoryIds"`
+	StartsAt    *time.Time                  `json:"startsAt"`
+	EndsAt      *time.Time                  `json:"endsAt"`
+	// Broadcast opsional saat membuat pengumuman: "all" atau "affected".
+	Broadcast string `json:"broadcast"`
+}
+
+type AnnouncementBroadcastRequest struct {
+	Audience string `json:"audience"`
+}
+
+func NewAnnouncementService(
+	announcements *repository.AnnouncementRepository,
+	categories *repository.CategoryRepository,
+	tickets *repository.TicketRepository,
+	notifications *repository.NotificationRepository,
+	tokens *repository.FCMTokenRepository,
+	fcmClient *fcm.Client,
+	audit *AuditService,
+) *AnnouncementService {
+	return &AnnouncementService{
+		announcements: announcements,
+		categories:    categories,
+		tickets:       tickets,
+		notifications: notifications,
+		tokens:        tokens,
+		fcmClient:     fcmClient,
+		audit:         audit,
+		now:           time.Now,
+	}
+}
+
+// ListActive dipakai endpoint publik. categoryID ikut mencocokkan pengumuman
+// yang ditujukan ke parent kategori tersebut.
+func (service *AnnouncementService) ListActive(categoryID string) ([]domain.AnnouncementDTO, error) {
+	now := service.now()
+	filter := repository.AnnouncementListFilter{ActiveAt: &now}
+	tree, names := service.loadCategories()
+	if categoryID = strings.TrimSpace(categoryID); categoryID != "" {
+		filter.CategoryIDs = tree.ancestors(categoryID)
+	}
+	items, err := service.announcements.List(filter)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]domain.AnnouncementDTO, 0, len(items))
+	for _, item := range items {
+		result = append(result, toAnnouncementDTO(item, names, now))
+	}
+	return result, nil
+}
+
+func (service *AnnouncementService) ListAll() ([]domain.AnnouncementDTO, error) {
+	items, err := service.announcements.List(repository.AnnouncementListFilter{})
+	if err != nil {
+		return nil, err
+	}
+	_, names := service.loadCategories()
+	now := service.now()
+	result := make([]domain.AnnouncementDTO, 0, len(items))
+	for _, item := range items {
+		result = append(result, toAnnouncementDTO(item, names, now))
+	}
+	return result, nil
+}
+
+func (service *AnnouncementService) Create(
+	ctx context.Context,
+	user domain.User,
+	req AnnouncementRequest,
+) (domain.AnnouncementDTO, error) {
+	announcement, audience, err := service.newAnnouncement(user, req)
+	if err != nil {
+		return domain.AnnouncementDTO{}, err
+	}
+	if err := service.announcements.Create(&announcement); err != nil {
+		return domain.AnnouncementDTO{}, err
+	}
+	return service.publish(ctx, user, &announcement, audience)
+}
+
+// newAnnouncement memvalidasi request dan menyusun pengumuman tanpa menyimpannya,
+// agar pemanggil dapat menyimpannya bersama perubahan lain dalam satu transaksi.
+func (service *AnnouncementService) newAnnouncement(user domain.User, req AnnouncementRequest) (domain.Announcement, string, error) {
+	audience, err := normalizeBroadcastAudience(req.Broadcast, true)
+	if err != nil {
+		return domain.Announcement{}, "", err
+	}
+	announcement := domain.Announcement{
+		ID:            util.NewUUID(),
+		CreatedBy:     user.ID,
+		CreatedByName: user.Name,
+	}
+	if err := service.applyAnnouncementRequest(&announcement, req); err != nil {
+		return domain.Announcement{}, "", err
+	}
+	return announcement, audience, nil
+}
+
+// publish mencatat audit pengumuman yang sudah tersimpan lalu mengirim broadcast
+// jika audience diminta.
+func (service *AnnouncementService) publish(
+	ctx context.Context,
+	user domain.User,
+	announcement *domain.Announcement,
+	audience string,
+) (domain.AnnouncementDTO, error) {
+	service.audit.Record(ctx, AuditEntry{
+		Actor:      &user,
+		Action:     AuditActionAnnouncementCreate,
+		EntityType: AuditEntityAnnouncement,
+		EntityID:   announcement.ID,
+		After:      announcementAuditSnapshot(*announcement),
+	})
+	if audience != "" {
+		return service.broadcast(ctx, user, announcement, audience)
+	}
+	return service.toDTO(*announcement), nil
+}
+
+func (service *AnnouncementService) Update(
+	ctx context.Context,
+	user domain.User,
+	announcementID string,
+	req AnnouncementRequest,
+) (domain.AnnouncementDTO, error) {
+	announcement, err := service.announcements.FindByID(announcementID)
+	if err != nil {
+		return domain.AnnouncementDTO{}, errors.New("pengumuman tidak ditemukan")
+	}
+	before := announcementAuditSnapshot(*announcement)
+	if err := service.applyAnnouncementRequest(announcement, req); err != nil {
+		return domain.AnnouncementDTO{}, err
+	}
+	if err := service.announcements.Update(announcement); err != nil {
+		return domain.AnnouncementDTO{}, err
+	}
+	service.audit.Record(ctx, AuditEntry{
+		Actor:      &user,
+		Action:     AuditActionAnnouncementUpdate,
+		EntityType: AuditEntityAnnouncement,
+		EntityID:   announcement.ID,
+		Before:     before,
+		After:      announcementAuditSnapshot(*announcement),
+	})
+	return service.toDTO(*announcement), nil
+}
+
+func (service *AnnouncementService) Delete(ctx context.Context, user domain.User, announcementID string) error {
+	announcement, err := service.announcements.FindByID(announcementID)
+	if err != nil {
+		return errors.New("pengumuman tidak ditemukan")
+	}
+	if err := service.announcements.Delete(announcement.ID); err != nil {
+		return err
+	}
+	service.audit.Record(ctx, AuditEntry{
+		Actor:      &user,
+		Action:     AuditActionAnnouncementDelete,
+		EntityType: AuditEntityAnnouncement,
+		EntityID:   announcement.ID,
+		Before:     announcementAuditSnapshot(*announcement),
+	})
+	return nil
+}
+
+func (service *AnnouncementService) Broadcast(
+	ctx context.Context,
+	user domain.User,
+	announcementID string,
+	req AnnouncementBroadcastRequest,
+) (domain.AnnouncementDTO, error) {
+	audience, err := normalizeBroadcastAudience(req.Audience, false)
+	if err != nil {
+		return domain.AnnouncementDTO{}, err
+	}
+	announcement, err := service.announcements.FindByID(announcementID)
+	if err != nil {
+		return domain.AnnouncementDTO{}, errors.New("pengumuman tidak ditemukan")
+	}
+	return service.broadcast(ctx, user, announcement, audience)
+}
+
+// broadcast mengirim push melalui topic FCM pengumuman. Untuk audience "affected",
+// pesan dikirim multicast ke token pengguna dengan tiket terbuka di kategori terdampak;
+// pengguna yang sama juga mendapat notifikasi in-app.
+func (service *AnnouncementService) broadcast(
+	ctx context.Context,
+	user domain.User,
+	announcement *domain.Announcement,
+	audience string,
+) (domain.AnnouncementDTO, error) {
+	topic := fcm.TopicAnnouncements
+	recipients := 0
+	data := map[string]string{
+		"announcement_id": announcement.ID,
+		"severity":        string(announcement.Severity),
+		"title":           announcement.Title,
+		"body":            announcement.Body,
+	}
+	if audience == BroadcastAffected {
+		categoryIDs := decodeStringList(announcement.CategoryIDs)
+		if len(categoryIDs) == 0 {
+			return domain.AnnouncementDTO{}, errors.New("pengumuman tanpa kategori hanya dapat dikirim ke semua pengguna")
+		}
+		tree, _ := service.loadCategories()
+		scope := make([]string, 0)
+		for _, categoryID := range categoryIDs {
+			for _, id := range tree.descendants(categoryID) {
+				if !slices.Contains(scope, id) {
+					scope = append(scope, id)
+				}
+			}
+		}
+		userIDs, err := service.tickets.ListOpenReporterIDs(scope)
+		if err != nil {
+			return domain.AnnouncementDTO{}, err
+		}
+		recipients = len(userIDs)
+		service.createInAppNotifications(*announcement, userIDs)
+
+		tokens, err := service.tokens.ListByUsers(userIDs)
+		if err != nil {
+			return domain.AnnouncementDTO{}, err
+		}
+		values := make([]string, 0, len(tokens))
+		for _, token := range tokens {
+			values = append(values, token.Token)
+		}
+		// Penerima terdampak dikirimi multicast langsung; topic khusus per pengumuman
+		// akan meninggalkan langganan yang tidak pernah dibersihkan.
+		topic = ""
+		if len(values) > 0 {
+			report, err := service.fcmClient.SendToTokens(ctx, values, announcement.Title, announcementPushBody(*announcement), data)
+			if invalid := report.InvalidTokens(); len(invalid) > 0 {
+				if err := service.tokens.DeleteByTokens(invalid); err != nil {
+					log.Printf("failed to delete invalid fcm tokens count=%d: %v", len(invalid), err)
+				}
+			}
+			if err != nil && report.Count(fcm.StatusSent) == 0 {
+				return domain.AnnouncementDTO{}, fmt.Errorf("gagal mengirim broadcast: %w", err)
+			}
+		}
+	} else if err := service.fcmClient.SendToTopic(ctx, topic, announcement.Title, announcementPushBody(*announcement), data); err != nil {
+		return domain.AnnouncementDTO{}, fmt.Errorf("gagal mengirim broadcast: %w", err)
+	}
+
+	now := service.now()
+	announcement.BroadcastAt = &now
+	announcement.BroadcastAudience = audience
+	announcement.BroadcastTopic = topic
+	if err := service.announcements.Update(announcement); err != nil {
+		return domain.AnnouncementDTO{}, err
+	}
+	service.audit.Record(ctx, AuditEntry{
+		Actor:      &user,
+		Action:     AuditActionAnnouncementBroadcast,
+		EntityType: AuditEntityAnnouncement,
+		EntityID:   announcement.ID,
+		After:      map[string]any{"audience": audience, "topic": topic, "recipients": recipients},
+	})
+	return service.toDTO(*announcement), nil
+}
+
+func (service *AnnouncementService) createInAppNotifications(announcement domain.Announcement, userIDs []string) {
+	items := make([]domain.Notification, 0, len(userIDs))
+	for _, userID := range userIDs {
+		items = append(items, domain.Notification{
+			ID:        util.NewUUID(),
+			UserID:    userID,
+			Title:     announcement.Title,
+			Message:   announcement.Body,
+			CreatedAt: service.now(),
+		})
+	}
+	if err := service.notifications.CreateBatch(items); err != nil {
+		log.Printf("failed to create announcement notifications id=%s: %v", announcement.ID, err)
+	}
+}
+
+func (service *AnnouncementService) applyAnnouncementRequest(announcement *domain.Announcement, req AnnouncementRequest) error {
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return errors.New("judul pengumuman wajib diisi")
+	}
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		return errors.New("isi pengumuman wajib diisi")
+	}
+	severity := req.Severity
+	if severity == "" {
+		severity = domain.SeverityInfo
+	}
+	switch severity {
+	case domain.SeverityInfo, domain.SeverityWarning, domain.SeverityCritical:
+	default:
+		return errors.New("severity tidak valid")
+	}
+
+	categoryIDs := make([]string, 0, len(req.CategoryIDs))
+	for _, categoryID := range req.CategoryIDs {
+		categoryID = strings.TrimSpace(categoryID)
+		if categoryID == "" || slices.Contains(categoryIDs, categoryID) {
+			continue
+		}
+		if _, err := service.categories.FindByID(categoryID); err != nil {
+			return fmt.Errorf("kategori %s tidak ditemukan", categoryID)
+		}
+		categoryIDs = append(categoryIDs, categoryID)
+	}
+	payload, err := json.Marshal(categoryIDs)
+	if err != nil {
+		return err
+	}
+
+	startsAt := announcement.StartsAt
+	if req.StartsAt != nil {
+		startsAt = *req.StartsAt
+	}
+	if startsAt.IsZero() {
+		startsAt = service.now()
+	}
+	if req.EndsAt != nil && !req.EndsAt.After(startsAt) {
+		return errors.New("waktu selesai harus setelah waktu mulai")
+	}
+
+	announcement.Title = title
+	announcement.Body = body
+	announcement.Severity = severity
+	announcement.CategoryIDs = datatypes.JSON(payload)
+	announcement.StartsAt = startsAt
+	announcement.EndsAt = req.EndsAt
+	return nil
+}
+
+func (service *AnnouncementService) loadCategories() (categoryTree, map[string]string) {
+	names := make(map[string]string)
+	items, err := service.categories.List()
+	if err != nil {
+		return newCategoryTree(nil), names
+	}
+	for _, item := range items {
+		names[item.ID] = item.Name
+	}
+	return newCategoryTree(items), names
+}
+
+func (service *AnnouncementService) toDTO(announcement domain.Announcement) domain.AnnouncementDTO {
+	_, names := service.loadCategories()
+	return toAnnouncementDTO(announcement, names, service.now())
+}
+
+func normalizeBroadcastAudience(value string, optional bool) (string, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch value {
+	case BroadcastAll, BroadcastAffected:
+		return value, nil
+	case "":
+		if optional {
+			return "", nil
+		}
+	}
+	return "", errors.New("audience broadcast harus all atau affected")
+}
+
+func announcementPushBody(announcement domain.Announcement) string {
+	body := announcement.Body
+	if runes := []rune(body); len(runes) > 180 {
+		body = string(runes[:180]) + "…"
+	}
+	return body
+}
+
+func decodeStringList(raw datatypes.JSON) []string {
+	values := make([]string, 0)
+	if len(raw) == 0 {
+		return values
+	}
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return []string{}
+	}
+	return values
+}
+
+func announcementAuditSnapshot(announcement domain.Announcement) map[string]any {
+	return map[string]any{
+		"title":       announcement.Title,
+		"severity":    announcement.Severity,
+		"categoryIds": decodeStringList(announcement.CategoryIDs),
+		"startsAt":    announcement.StartsAt,
+		"endsAt":      announcement.EndsAt,
+	}
+}
+
+func toAnnouncementDTO(announcement domain.Announcement, names map[string]string, now time.Time) domain.AnnouncementDTO {
+	categoryIDs := decodeStringList(announcement.CategoryIDs)
+	categories := make([]string, 0, len(categoryIDs))
+	for _, categoryID := range categoryIDs {
+		name := names[categoryID]
+		if name == "" {
+			name = categoryID
+		}
+		categories = append(categories, name)
+	}
+	active := !announcement.StartsAt.After(now) && (announcement.EndsAt == nil || announcement.EndsAt.After(now))
+	return domain.AnnouncementDTO{
+		ID:                announcement.ID,
+		Title:             announcement.Title,
+		Body:              announcement.Body,
+		Severity:          announcement.Severity,
+		CategoryIDs:       categoryIDs,
+		Categories:        categories,
+		StartsAt:          announcement.StartsAt,
+		EndsAt:            announcement.EndsAt,
+		Active:            active,
+		CreatedBy:         announcement.CreatedByName,
+		BroadcastAt:       announcement.BroadcastAt,
+		BroadcastAudience: announcement.BroadcastAudience,
+		CreatedAt:         announcement.CreatedAt,
+		UpdatedAt:         announcement.UpdatedAt,
+	}
+}
@@ -0,0 +1,322 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"reflect"
+	"strings"
+	"time"
+
+	"unila_helpdesk_backend/internal/domain"
+	"unila_helpdesk_backend/internal/repository"
+	"unila_helpdesk_backend/internal/util"
+)
+
+// AuditContextKey adalah key gin context tempat middleware menyimpan
+// metadata request (IP dan user agent) untuk audit log.
+const AuditContextKey = "auditRequestMeta"
+
+const (
+	AuditActionTicketCreate          = "ticket.create"
+	AuditActionTicketUpdate          = "ticket.update"
+	AuditActionTicketComment         = "ticket.comment"
+	AuditActionTicketDelete          = "ticket.delete"
+	AuditActionTicketRestore         = "ticket.restore"
+	AuditActionTicketPurge           = "ticket.purge"
+	AuditActionTicketTag             = "ticket.tag"
+	AuditActionTicketUntag           = "ticket.untag"
+	AuditActionTicketLinkArticles    = "ticket.link_articles"
+	AuditActionTagCreate             = "tag.create"
+	AuditActionTagUpdate             = "tag.update"
+	AuditActionTagDelete             = "tag.delete"
+	AuditActionCannedCreate          = "canned_response.create"
+	AuditActionCannedUpdate          = "canned_response.update"
+	AuditActionCannedDelete          = "canned_response.delete"
+	AuditActionMacroCreate           = "macro.create"
+	AuditActionMacroUpdate           = "macro.update"
+	AuditActionMacroDelete           = "macro.delete"
+	AuditActionArticleCreate         = "article.create"
+	AuditActionArticleUpdate         = "article.update"
+	AuditActionArticlePublish        = "article.publish"
+	AuditActionArticleUnpublish      = "article.unpublish"
+	AuditActionArticleDelete         = "article.delete"
+	AuditActionAnnouncementCreate    = "announcement.create"
+	AuditActionAnnouncementUpdate    = "announcement.update"
+	AuditActionAnnouncementDelete    = "announcement.delete"
+	AuditActionAnnouncementBroadcast = "announcement.broadcast"
+	AuditActionServiceState          = "service.state"
+	AuditActionSurveyTemplateCreate  = "survey_template.create"
+	AuditActionSurveyTemplateUpdate  = "survey_template.update"
+	AuditActionSurveyTemplateDelete  = "survey_template.delete"
+	AuditActionCategoryTemplate      = "category.assign_template"
+	AuditActionCategoryFields        = "category.update_fields"
+	AuditActionCategoryCreate        = "category.create"
+	AuditActionCategoryUpdate        = "category.update"
+	AuditActionCategoryArchive       = "category.archive"
+	AuditActionCategoryUnarchive     = "category.unarchive"
+	AuditActionCategoryReorder       = "category.reorder"
+	AuditActionCategoryMerge         = "category.merge"
+	AuditActionLogin                 = "auth.login"
+	AuditActionLoginFailed           = "auth.login_failed"
+	AuditActionRefresh               = "auth.refresh"
+	AuditActionLogout                = "auth.logout"
+	AuditActionRefreshReuse          = "auth.refresh_reuse"
+	AuditActionUserProvision         = "user.provision"
+	AuditActionUserLink              = "user.link"
+	AuditActionSessionRevoke         = "session.revoke"
+	AuditActionForceLogout           = "auth.force_logout"
+	AuditActionUserActivate          = "user.activate"
+	AuditActionUserDeactivate        = "user.deactivate"
+	AuditActionAccessDenied          = "access.denied"
+)
+
+const (
+	AuditEntityTicket         = "ticket"
+	AuditEntitySurveyTemplate = "survey_template"
+	AuditEntityCategory       = "category"
+	AuditEntityTag            = "tag"
+	AuditEntityCannedResponse = "canned_response"
+	AuditEntityMacro          = "macro"
+	AuditEntityArticle        = "article"
+	AuditEntityAnnouncement   = "announcement"
+	AuditEntityUser           = "user"
+	AuditEntitySession        = "session"
+	AuditEntityRoute          = "route"
+)
+
+type AuditRequestMeta struct {
+	IP        string
+	UserAgent string
+}
+
+type AuditEntry struct {
+	Actor      *domain.User
+	Action     string
+	EntityType string
+	EntityID   string
+	Before     any
+	After      any
+}
+
+type AuditService struct {
+	logs *repository.AuditLogRepository
+	now  func() time.Time
+}
+
+func NewAuditService(logs *repository.AuditLogRepository) *AuditService {
+	return &AuditService{logs: logs, now: time.Now}
+}
+
+// Record menambahkan entri audit ke rantai hash. Kegagalan hanya dicatat di log
+// agar aksi utama pengguna tidak ikut gagal.
+func (service *AuditService) Record(ctx context.Context, entry AuditEntry) {
+	if service == nil {
+		return
+	}
+	record := domain.AuditLog{
+		Action:     entry.Action,
+		EntityType: entry.EntityType,
+		EntityID:   entry.EntityID,
+		Before:     marshalAuditValue(entry.Before),
+		After:      marshalAuditValue(entry.After),
+		CreatedAt:  service.now().UTC().Truncate(time.Microsecond),
+	}
+	record.Changes = diffAuditValues(record.Before, record.After)
+	if entry.Actor != nil {
+		record.ActorID = entry.Actor.ID
+		record.ActorName = entry.Actor.Name
+		record.ActorRole = entry.Actor.Role
+	}
+	if ctx != nil {
+		if meta, ok := ctx.Value(AuditContextKey).(AuditRequestMeta); ok {
+			record.IP = meta.IP
+			record.UserAgent = meta.UserAgent
+		}
+	}
+	if err := service.logs.Append(&record, sealAuditLog); err != nil {
+		log.Printf("failed to write audit log action=%s entity=%s/%s: %v", entry.Action, entry.EntityType, entry.EntityID, err)
+	}
+}
+
+func (service *AuditService) List(filter repository.AuditLogFilter, page int, limit int) (domain.AuditLogPageDTO, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if page < 1 {
+		page = 1
+	}
+	logs, total, err := service.logs.List(filter, page, limit)
+	if err != nil {
+		return domain.AuditLogPageDTO{}, err
+	}
+	items := make([]domain.AuditLogDTO, 0, len(logs))
+	for _, item := range logs {
+		items = append(items, domain.AuditLogDTO{
+			ID:         item.ID,
+			ActorID:    item.ActorID,
+			ActorName:  item.ActorName,
+			ActorRole:  item.ActorRole,
+			Action:     item.Action,
+			EntityType: item.EntityType,
+			EntityID:   item.EntityID,
+			Before:     item.Before,
+			After:      item.After,
+			Changes:    item.Changes,
+			IP:         item.IP,
+			UserAgent:  item.UserAgent,
+			Hash:       item.Hash,
+			CreatedAt:  item.CreatedAt,
+		})
+	}
+	return domain.AuditLogPageDTO{
+		Items:      items,
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: util.CalcTotalPages(total, limit),
+	}, nil
+}
+
+// Verify menghitung ulang rantai hash dari awal dan melaporkan entri pertama
+// yang tidak cocok.
+func (service *AuditService) Verify() (domain.AuditVerifyDTO, error) {
+	result := domain.AuditVerifyDTO{Valid: true}
+	prevHash := ""
+	err := service.logs.Walk(500, func(entry domain.AuditLog) bool {
+		result.Checked++
+		if entry.PrevHash != prevHash || computeAuditHash(entry, prevHash) != entry.Hash {
+			result.Valid = false
+			result.BrokenAt = entry.ID
+			return false
+		}
+		prevHash = entry.Hash
+		return true
+	})
+	if err != nil {
+		return domain.AuditVerifyDTO{}, err
+	}
+	return result, nil
+}
+
+func sealAuditLog(entry *domain.AuditLog, prevHash string) {
+	entry.PrevHash = prevHash
+	entry.Hash = computeAuditHash(*entry, prevHash)
+}
+
+func computeAuditHash(entry domain.AuditLog, prevHash string) string {
+	parts := []string{
+		prevHash,
+		entry.ActorID,
+		entry.ActorName,
+		string(entry.ActorRole),
+		entry.Action,
+		entry.EntityType,
+		entry.EntityID,
+		canonicalAuditJSON(entry.Before),
+		canonicalAuditJSON(entry.After),
+		canonicalAuditJSON(entry.Changes),
+		entry.IP,
+		entry.UserAgent,
+		entry.CreatedAt.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano),
+	}
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// canonicalAuditJSON menormalkan JSON karena jsonb Postgres tidak menyimpan
+// urutan key maupun spasi aslinya.
+func canonicalAuditJSON(raw []byte) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	value, ok := decodeAuditJSON(raw)
+	if !ok {
+		return string(raw)
+	}
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return string(raw)
+	}
+	return string(payload)
+}
+
+func decodeAuditJSON(raw []byte) (any, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+func marshalAuditValue(value any) []byte {
+	if value == nil {
+		return nil
+	}
+	payload, err := json.Marshal(value)
+	if err != nil || string(payload) == "null" {
+		return nil
+	}
+	return payload
+}
+
+// diffAuditValues mengembalikan {field: {old, new}} untuk field yang berubah
+// jika before dan after sama-sama objek JSON.
+func diffAuditValues(before []byte, after []byte) []byte {
+	if len(before) == 0 || len(after) == 0 {
+		return nil
+	}
+	beforeValue, ok := decodeAuditJSON(before)
+	if !ok {
+		return nil
+	}
+	afterValue, ok := decodeAuditJSON(after)
+	if !ok {
+		return nil
+	}
+	beforeMap, ok := beforeValue.(map[string]any)
+	if !ok {
+		return nil
+	}
+	afterMap, ok := afterValue.(map[string]any)
+	if !ok {
+		return nil
+	}
+	changes := make(map[string]map[string]any)
+	for key, oldValue := range beforeMap {
+		newValue := afterMap[key]
+		if !reflect.DeepEqual(oldValue, newValue) {
+			changes[key] = map[string]any{"old": oldValue, "new": newValue}
+		}
+	}
+	for key, newValue := range afterMap {
+		if _, ok := beforeMap[key]; !ok {
+			changes[key] = map[string]any{"old": nil, "new": newValue}
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	return marshalAuditValue(changes)
+}
+
+func ticketAuditSnapshot(ticket domain.Ticket) map[string]any {
+	return map[string]any{
+		"title":        ticket.Title,
+		"description":  ticket.Description,
+		"categoryId":   ticket.CategoryID,
+		"priority":     ticket.Priority,
+		"status":       ticket.Status,
+		"assignee":     ticket.Assignee,
+		"dueAt":        ticket.DueAt,
+		"customFields": decodeCustomFields(ticket.CustomFields),
+	}
+}
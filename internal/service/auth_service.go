@@ -1,29 +1,39 @@
 package service
 
 import (
+    "context"
     "crypto/rand"
     "crypto/sha256"
     "encoding/base64"
     "encoding/hex"
     "errors"
+    "log"
     "strings"
     "time"
 
     "unila_helpdesk_backend/internal/config"
     "unila_helpdesk_backend/internal/domain"
+    "unila_helpdesk_backend/internal/jwtkeys"
     "unila_helpdesk_backend/internal/repository"
     "unila_helpdesk_backend/internal/util"
 
     "github.com/golang-jwt/jwt/v5"
-    "golang.org/x/crypto/bcrypt"
+    "gorm.io/gorm"
 )
 
 type AuthService struct {
-    cfg           config.Config
-    users         *repository.UserRepository
-    refreshTokens *repository.RefreshTokenRepository
-    jwtKey        []byte
-    now           func() time.Time
+    cfg            config.Config
+    users          *repository.UserRepository
+    refreshTokens  *repository.RefreshTokenRepository
+    sessions       *repository.SessionRepository
+    audit          *AuditService
+    jwtKey         []byte
+    // signingKeys berisi kunci RS256/EdDSA; nil berarti token ditandatangani HS256
+    // dengan JWT_SECRET seperti sebelumnya.
+    signingKeys    *jwtkeys.KeySet
+    // authenticators dicoba berurutan saat login password (AUTH_BACKENDS).
+    authenticators []Authenticator
+    now            func() time.Time
 }
 
 type AuthResult struct {
@@ -32,57 +42,80 @@ type AuthResult struct {
     RefreshToken     string         `json:"refreshToken"`
     RefreshExpiresAt time.Time      `json:"refreshExpiresAt"`
     User             domain.UserDTO `json:"user"`
+    SessionID        string         `json:"sessionId"`
+}
+
+// ClientInfo menjelaskan perangkat yang login; disimpan pada sesi.
+type ClientInfo struct {
+    Type       string
+    DeviceName string
+    Platform   string
+    IPAddress  string
+    UserAgent  string
 }
 
 func NewAuthService(
     cfg config.Config,
     users *repository.UserRepository,
     refreshTokens *repository.RefreshTokenRepository,
+    sessions *repository.SessionRepository,
+    signingKeys *jwtkeys.KeySet,
+    audit *AuditService,
 ) *AuthService {
-    return &AuthService{
+    service := &AuthService{
         cfg:           cfg,
         users:         users,
         refreshTokens: refreshTokens,
+        sessions:      sessions,
+        audit:         audit,
         jwtKey:        []byte(cfg.JWTSecret),
+        signingKeys:   signingKeys,
         now:           time.Now,
     }
+    service.authenticators = newAuthenticators(cfg, service)
+    return service
 }
 
 var ErrAdminWebOnly = errors.New("akun admin hanya bisa login via web")
 
+// ErrRefreshTokenReused dikembalikan saat refresh token bekas rotasi dipakai lagi.
+var ErrRefreshTokenReused = errors.New("refresh token sudah pernah dipakai, sesi dicabut demi keamanan")
+
 type Claims struct {
-    UserID string          `json:"uid"`
-    Role   domain.UserRole `json:"role"`
+    UserID    string          `json:"uid"`
+    Role      domain.UserRole `json:"role"`
+    SessionID string          `json:"sid,omitempty"`
+    // TokenVersion harus sama dengan User.TokenVersion; dicek di AuthMiddleware.
+    TokenVersion int          `json:"ver"`
     jwt.RegisteredClaims
 }
 
-func (service *AuthService) IssueToken(user domain.User) (AuthResult, error) {
+// IssueToken menerbitkan access token dan refresh token baru untuk sesi.
+// Masa berlaku sesi ikut diperpanjang sampai refresh token kadaluarsa.
+func (service *AuthService) IssueToken(user domain.User, session domain.Session) (AuthResult, error) {
     expiry := service.cfg.JWTExpiryUser
-    refreshExpiry := service.cfg.JWTRefreshExpiryUser
     if user.Role == domain.RoleAdmin {
         expiry = service.cfg.JWTExpiryAdmin
-        refreshExpiry = service.cfg.JWTRefreshExpiryAdmin
     }
     if expiry <= 0 {
         expiry = service.cfg.JWTExpiry
     }
-    if refreshExpiry <= 0 {
-        refreshExpiry = service.cfg.JWTRefreshExpiry
-    }
     expires := service.now().Add(expiry)
-    refreshExpires := service.now().Add(refreshExpiry)
+    refreshExpires := service.now().Add(service.refreshExpiry(user))
     claims := Claims{
-        UserID: user.ID,
-        Role:   user.Role,
+        UserID:       user.ID,
+        Role:         user.Role,
+        SessionID:    session.ID,
+        TokenVersion: user.TokenVersion,
         RegisteredClaims: jwt.RegisteredClaims{
             ExpiresAt: jwt.NewNumericDate(expires),
             IssuedAt:  jwt.NewNumericDate(service.now()),
             Subject:   user.ID,
+            Issuer:    service.cfg.JWTIssuer,
         },
     }
 
-    token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-    signed, err := token.SignedString(service.jwtKey)
+    signed, err := service.signToken(claims)
     if err != nil {
         return AuthResult{}, err
     }
@@ -95,6 +128,8 @@ func (service *AuthService) IssueToken(user domain.User) (AuthResult, error) {
     if err := service.refreshTokens.Create(&domain.RefreshToken{
         ID:        util.NewUUID(),
         UserID:    user.ID,
+        SessionID: session.ID,
+        FamilyID:  session.ID,
         TokenHash: tokenHash,
         ExpiresAt: refreshExpires,
         CreatedAt: service.now(),
@@ -108,47 +143,70 @@ func (service *AuthService) IssueToken(user domain.User) (AuthResult, error) {
         RefreshToken:     refreshToken,
         RefreshExpiresAt: refreshExpires,
         User:             domain.ToUserDTO(user),
+        SessionID:        session.ID,
     }, nil
 }
 
-func (service *AuthService) LoginWithPasswordClient(username string, password string, clientType string) (AuthResult, error) {
-    cleanedUser := strings.ToLower(strings.TrimSpace(username))
-    cleanedPass := strings.TrimSpace(password)
-    if cleanedUser == "" || cleanedPass == "" {
-        return AuthResult{}, errors.New("username dan password wajib diisi")
+func (service *AuthService) refreshExpiry(user domain.User) time.Duration {
+    refreshExpiry := service.cfg.JWTRefreshExpiryUser
+    if user.Role == domain.RoleAdmin {
+        refreshExpiry = service.cfg.JWTRefreshExpiryAdmin
     }
-
-    user, err := service.users.FindByUsername(cleanedUser)
-    if err != nil {
-        return AuthResult{}, errors.New("username atau password salah")
+    if refreshExpiry <= 0 {
+        refreshExpiry = service.cfg.JWTRefreshExpiry
     }
-    if !user.IsActive {
-        return AuthResult{}, errors.New("akun tidak aktif")
+    return refreshExpiry
+}
+
+func (service *AuthService) startSession(user domain.User, client ClientInfo) (domain.Session, error) {
+    now := service.now()
+    session := domain.Session{
+        ID:         util.NewUUID(),
+        UserID:     user.ID,
+        DeviceName: truncateString(strings.TrimSpace(client.DeviceName), 120),
+        Platform:   truncateString(strings.TrimSpace(client.Platform), 40),
+        ClientType: truncateString(strings.ToLower(strings.TrimSpace(client.Type)), 20),
+        IPAddress:  truncateString(client.IPAddress, 64),
+        UserAgent:  truncateString(client.UserAgent, 255),
+        LastUsedAt: now,
+        ExpiresAt:  now.Add(service.refreshExpiry(user)),
+        CreatedAt:  now,
+        UpdatedAt:  now,
     }
-    if user.PasswordHash == "" {
-        return AuthResult{}, errors.New("akun belum memiliki password")
+    if err := service.sessions.Create(&session); err != nil {
+        return domain.Session{}, err
     }
+    return session, nil
+}
 
-    if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(cleanedPass)) != nil {
-        return AuthResult{}, errors.New("username atau password salah")
+func (service *AuthService) LoginWithPasswordClient(ctx context.Context, username string, password string, client ClientInfo) (AuthResult, error) {
+    cleanedUser := strings.ToLower(strings.TrimSpace(username))
+    cleanedPass := strings.TrimSpace(password)
+    if cleanedUser == "" || cleanedPass == "" {
+        return AuthResult{}, errors.New("username dan password wajib diisi")
     }
 
-    if err := ensureAdminAllowed(*user, clientType); err != nil {
+    user, method, err := service.authenticate(ctx, cleanedUser, cleanedPass)
+    if err != nil {
         return AuthResult{}, err
     }
-    return service.IssueToken(*user)
+    return service.completeLogin(ctx, user, client, method)
 }
 
-func (service *AuthService) RefreshWithTokenClient(refreshToken string, clientType string) (AuthResult, error) {
+func (service *AuthService) RefreshWithTokenClient(ctx context.Context, refreshToken string, client ClientInfo) (AuthResult, error) {
     token := strings.TrimSpace(refreshToken)
     if token == "" {
         return AuthResult{}, errors.New("refresh token wajib diisi")
     }
     tokenHash := hashToken(token)
     stored, err := service.refreshTokens.FindByHash(tokenHash)
-    if err != nil {
+    if err != nil || stored.RevokedAt != nil {
         return AuthResult{}, errors.New("refresh token tidak valid")
     }
+    if stored.UsedAt != nil && !service.withinReuseGrace(stored) {
+        service.revokeReusedFamily(ctx, stored, client)
+        return AuthResult{}, ErrRefreshTokenReused
+    }
     if service.now().After(stored.ExpiresAt) {
         _ = service.refreshTokens.DeleteByID(stored.ID)
         return AuthResult{}, errors.New("refresh token kadaluarsa")
@@ -157,11 +215,195 @@ func (service *AuthService) RefreshWithTokenClient(refreshToken string, clientTy
     if err != nil {
         return AuthResult{}, errors.New("user tidak ditemukan")
     }
-    if err := ensureAdminAllowed(*user, clientType); err != nil {
+    if !user.IsActive {
+        _ = service.refreshTokens.DeleteByID(stored.ID)
+        return AuthResult{}, errors.New("akun tidak aktif")
+    }
+    if err := ensureAdminAllowed(*user, client.Type); err != nil {
+        return AuthResult{}, err
+    }
+    // Token ditandai terpakai sebelum token baru terbit; gagal berarti token yang
+    // sama baru saja dirotasi oleh request lain. Dalam masa grace, request tersebut
+    // dianggap refresh bersamaan dan ikut mendapat token baru pada sesi yang sama.
+    if stored.UsedAt == nil {
+        marked, err := service.refreshTokens.MarkUsed(stored.ID, service.now())
+        if err != nil {
+            return AuthResult{}, err
+        }
+        if !marked {
+            current, err := service.refreshTokens.FindByHash(tokenHash)
+            if err != nil || current.RevokedAt != nil {
+                return AuthResult{}, errors.New("refresh token tidak valid")
+            }
+            if !service.withinReuseGrace(current) {
+                service.revokeReusedFamily(ctx, current, client)
+                return AuthResult{}, ErrRefreshTokenReused
+            }
+        }
+    }
+    session, err := service.resumeSession(*user, stored, client)
+    if err != nil {
+        return AuthResult{}, err
+    }
+    result, err := service.IssueToken(*user, session)
+    if err != nil {
         return AuthResult{}, err
     }
-    _ = service.refreshTokens.DeleteByID(stored.ID)
-    return service.IssueToken(*user)
+    service.audit.Record(ctx, AuditEntry{
+        Actor:      user,
+        Action:     AuditActionRefresh,
+        EntityType: AuditEntityUser,
+        EntityID:   user.ID,
+        After:      map[string]any{"clientType": client.Type, "sessionId": session.ID},
+    })
+    return result, nil
+}
+
+// withinReuseGrace bernilai true jika token bekas rotasi dipakai lagi dalam
+// JWT_REFRESH_REUSE_GRACE sejak rotasinya.
+func (service *AuthService) withinReuseGrace(stored *domain.RefreshToken) bool {
+    if stored.UsedAt == nil || service.cfg.JWTRefreshReuseGrace <= 0 {
+        return false
+    }
+    return !service.now().After(stored.UsedAt.Add(service.cfg.JWTRefreshReuseGrace))
+}
+
+// completeLogin menerbitkan token untuk user yang sudah diautentikasi backend
+// password maupun penyedia eksternal (SSO). method dicatat di audit login.
+func (service *AuthService) completeLogin(ctx context.Context, user *domain.User, client ClientInfo, method string) (AuthResult, error) {
+    if !user.IsActive {
+        service.recordLoginFailure(ctx, user, user.Username, "akun tidak aktif")
+        return AuthResult{}, errors.New("akun tidak aktif")
+    }
+    if err := ensureAdminAllowed(*user, client.Type); err != nil {
+        service.recordLoginFailure(ctx, user, user.Username, err.Error())
+        return AuthResult{}, err
+    }
+    session, err := service.startSession(*user, client)
+    if err != nil {
+        return AuthResult{}, err
+    }
+    result, err := service.IssueToken(*user, session)
+    if err != nil {
+        return AuthResult{}, err
+    }
+    service.audit.Record(ctx, AuditEntry{
+        Actor:      user,
+        Action:     AuditActionLogin,
+        EntityType: AuditEntityUser,
+        EntityID:   user.ID,
+        After:      map[string]any{"clientType": client.Type, "sessionId": session.ID, "method": method},
+    })
+    return result, nil
+}
+
+// revokeReusedFamily mencabut seluruh token dalam family beserta sesinya lalu
+// mencatat security event di audit log.
+func (service *AuthService) revokeReusedFamily(ctx context.Context, stored *domain.RefreshToken, client ClientInfo) {
+    now := service.now()
+    familyID := stored.FamilyID
+    if familyID == "" {
+        familyID = stored.ID
+    }
+    if err := service.refreshTokens.RevokeFamily(familyID, now); err != nil {
+        log.Printf("failed to revoke refresh token family %s: %v", familyID, err)
+    }
+    if stored.SessionID != "" {
+        if err := service.sessions.Revoke(stored.SessionID, now); err != nil {
+            log.Printf("failed to revoke session %s: %v", stored.SessionID, err)
+        }
+    }
+
+    var actor *domain.User
+    if user, err := service.users.FindByID(stored.UserID); err == nil {
+        actor = user
+    }
+    log.Printf("security: refresh token reuse user=%s family=%s ip=%s", stored.UserID, familyID, client.IPAddress)
+    service.audit.Record(ctx, AuditEntry{
+        Actor:      actor,
+        Action:     AuditActionRefreshReuse,
+        EntityType: AuditEntitySession,
+        EntityID:   stored.SessionID,
+        After: map[string]any{
+            "userId":     stored.UserID,
+            "familyId":   familyID,
+            "tokenId":    stored.ID,
+            "usedAt":     stored.UsedAt,
+            "ipAddress":  client.IPAddress,
+            "userAgent":  client.UserAgent,
+            "clientType": client.Type,
+        },
+    })
+}
+
+// CleanupExpired menghapus refresh token yang kadaluarsa serta sesi yang sudah
+// berakhir lebih dari sessionRetention.
+func (service *AuthService) CleanupExpired(sessionRetention time.Duration) (int64, int64, error) {
+    now := service.now()
+    tokens, err := service.refreshTokens.DeleteExpired(now)
+    if err != nil {
+        return 0, 0, err
+    }
+    sessions, err := service.sessions.DeleteStale(now.Add(-sessionRetention))
+    if err != nil {
+        return tokens, 0, err
+    }
+    return tokens, sessions, nil
+}
+
+// RunCleanup menjalankan CleanupExpired secara berkala sampai ctx dibatalkan.
+func (service *AuthService) RunCleanup(ctx context.Context, interval time.Duration, sessionRetention time.Duration) {
+    if interval <= 0 {
+        interval = time.Hour
+    }
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+    for {
+        if tokens, sessions, err := service.CleanupExpired(sessionRetention); err != nil {
+            log.Printf("auth cleanup failed: %v", err)
+        } else if tokens > 0 || sessions > 0 {
+            log.Printf("auth cleanup removed refresh_tokens=%d sessions=%d", tokens, sessions)
+        }
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
+
+// resumeSession memperbarui sesi pemilik refresh token. Refresh token lama yang
+// dibuat sebelum ada sesi mendapat sesi baru.
+func (service *AuthService) resumeSession(user domain.User, stored *domain.RefreshToken, client ClientInfo) (domain.Session, error) {
+    if stored.SessionID == "" {
+        return service.startSession(user, client)
+    }
+    session, err := service.sessions.FindByID(stored.SessionID)
+    if err != nil || session.RevokedAt != nil {
+        _ = service.refreshTokens.DeleteByID(stored.ID)
+        return domain.Session{}, errors.New("sesi sudah berakhir, silakan login kembali")
+    }
+    now := service.now()
+    expiresAt := now.Add(service.refreshExpiry(user))
+    if err := service.sessions.Touch(session.ID, truncateString(client.IPAddress, 64), truncateString(client.UserAgent, 255), expiresAt, now); err != nil {
+        return domain.Session{}, err
+    }
+    session.LastUsedAt = now
+    session.ExpiresAt = expiresAt
+    return *session, nil
+}
+
+func (service *AuthService) recordLoginFailure(ctx context.Context, user *domain.User, username string, reason string) {
+    entry := AuditEntry{
+        Actor:      user,
+        Action:     AuditActionLoginFailed,
+        EntityType: AuditEntityUser,
+        After:      map[string]any{"username": username, "reason": reason},
+    }
+    if user != nil {
+        entry.EntityID = user.ID
+    }
+    service.audit.Record(ctx, entry)
 }
 
 func ensureAdminAllowed(user domain.User, clientType string) error {
@@ -174,10 +416,33 @@ func ensureAdminAllowed(user domain.User, clientType string) error {
     return ErrAdminWebOnly
 }
 
+func (service *AuthService) signToken(claims Claims) (string, error) {
+    if service.signingKeys != nil {
+        return service.signingKeys.Sign(claims)
+    }
+    return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(service.jwtKey)
+}
+
+// ParseToken hanya menerima algoritma kunci yang dikonfigurasi. HS256 tetap diterima
+// selama JWT_SECRET diisi agar token lama berlaku selama migrasi ke kunci asimetris.
 func (service *AuthService) ParseToken(tokenString string) (*Claims, error) {
+    methods := make([]string, 0, 3)
+    if service.signingKeys != nil {
+        methods = append(methods, service.signingKeys.Algorithms()...)
+    }
+    if len(service.jwtKey) > 0 {
+        methods = append(methods, jwt.SigningMethodHS256.Alg())
+    }
+    options := []jwt.ParserOption{jwt.WithValidMethods(methods)}
+    if service.cfg.JWTIssuer != "" {
+        options = append(options, jwt.WithIssuer(service.cfg.JWTIssuer))
+    }
     token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-        return service.jwtKey, nil
-    })
+        if token.Method.Alg() == jwt.SigningMethodHS256.Alg() {
+            return service.jwtKey, nil
+        }
+        return service.signingKeys.Lookup(token)
+    }, options...)
     if err != nil {
         return nil, err
     }
@@ -187,6 +452,37 @@ func (service *AuthService) ParseToken(tokenString string) (*Claims, error) {
     return nil, errors.New("token tidak valid")
 }
 
+// ErrTokenRevoked dikembalikan saat access token terbit sebelum logout paksa
+// atau penonaktifan akun.
+var ErrTokenRevoked = errors.New("token sudah dicabut")
+
+// CheckTokenVersion memastikan access token masih berlaku untuk kondisi user saat ini.
+func CheckTokenVersion(claims *Claims, user domain.User) error {
+    if !user.IsActive || claims.TokenVersion != user.TokenVersion {
+        return ErrTokenRevoked
+    }
+    return nil
+}
+
+// CheckSession menolak access token yang sesinya sudah dicabut (logout atau
+// pencabutan perangkat) atau sudah dihapus. Token lama tanpa klaim sesi dilewati.
+func (service *AuthService) CheckSession(claims *Claims) error {
+    if claims.SessionID == "" {
+        return nil
+    }
+    session, err := service.sessions.FindByID(claims.SessionID)
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+        return ErrTokenRevoked
+    }
+    if err != nil {
+        return err
+    }
+    if session.RevokedAt != nil || session.UserID != claims.UserID {
+        return ErrTokenRevoked
+    }
+    return nil
+}
+
 func generateRefreshToken() (string, error) {
     buffer := make([]byte, 32)
     if _, err := rand.Read(buffer); err != nil {
@@ -199,3 +495,11 @@ func hashToken(token string) string {
     sum := sha256.Sum256([]byte(token))
     return hex.EncodeToString(sum[:])
 }
+
+// JWKS mengembalikan kunci publik untuk verifikasi token oleh layanan lain.
+func (service *AuthService) JWKS() jwtkeys.JWKS {
+    if service.signingKeys == nil {
+        return jwtkeys.JWKS{Keys: []jwtkeys.JWK{}}
+    }
+    return service.signingKeys.JWKS()
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"unila_helpdesk_backend/internal/config"
+	"unila_helpdesk_backend/internal/domain"
+	"unila_helpdesk_backend/internal/repository"
+)
+
+func newRefreshTestService(t *testing.T) (*AuthService, *repository.SessionRepository, AuthResult, *time.Time) {
+	t.Helper()
+	db := newTestDB(t)
+	user := domain.User{ID: "u-1", Username: "budi", Name: "Budi", Role: domain.RoleRegistered, IsActive: true}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	sessions := repository.NewSessionRepository(db)
+	service := NewAuthService(
+		config.Config{
+			JWTSecret:            "test-secret",
+			JWTExpiry:            time.Minute,
+			JWTRefreshExpiry:     time.Hour,
+			JWTRefreshReuseGrace: 10 * time.Second,
+		},
+		repository.NewUserRepository(db),
+		repository.NewRefreshTokenRepository(db),
+		sessions,
+		nil,
+		nil,
+	)
+	now := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	login, err := service.completeLogin(context.Background(), &user, ClientInfo{Type: "mobile"}, authBackendLocal)
+	if err != nil {
+		t.Fatalf("completeLogin: %v", err)
+	}
+	return service, sessions, login, &now
+}
+
+func TestRefreshAllowsConcurrentReuseWithinGrace(t *testing.T) {
+	service, sessions, login, now := newRefreshTestService(t)
+	ctx := context.Background()
+
+	first, err := service.RefreshWithTokenClient(ctx, login.RefreshToken, ClientInfo{Type: "mobile"})
+	if err != nil {
+		t.Fatalf("refresh pertama: %v", err)
+	}
+	*now = now.Add(3 * time.Second)
+	second, err := service.RefreshWithTokenClient(ctx, login.RefreshToken, ClientInfo{Type: "mobile"})
+	if err != nil {
+		t.Fatalf("refresh kedua dalam grace: %v", err)
+	}
+	if second.SessionID != login.SessionID || first.RefreshToken == second.RefreshToken {
+		t.Fatalf("refresh bersamaan harus tetap di sesi %s dengan token berbeda", login.SessionID)
+	}
+	// Kedua penerus tetap berlaku sehingga request mana pun yang menang bisa lanjut.
+	if _, err := service.RefreshWithTokenClient(ctx, first.RefreshToken, ClientInfo{Type: "mobile"}); err != nil {
+		t.Fatalf("refresh dengan penerus pertama: %v", err)
+	}
+	session, err := sessions.FindByID(login.SessionID)
+	if err != nil || session.RevokedAt != nil {
+		t.Fatalf("sesi tidak boleh dicabut: %+v, %v", session, err)
+	}
+}
+
+func TestRefreshReuseAfterGraceRevokesSession(t *testing.T) {
+	service, sessions, login, now := newRefreshTestService(t)
+	ctx := context.Background()
+
+	successor, err := service.RefreshWithTokenClient(ctx, login.RefreshToken, ClientInfo{Type: "mobile"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	*now = now.Add(11 * time.Second)
+	if _, err := service.RefreshWithTokenClient(ctx, login.RefreshToken, ClientInfo{Type: "mobile"}); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("err = %v, want ErrRefreshTokenReused", err)
+	}
+	session, err := sessions.FindByID(login.SessionID)
+	if err != nil || session.RevokedAt == nil {
+		t.Fatal("sesi harus dicabut setelah token lama dipakai lewat masa grace")
+	}
+	if _, err := service.RefreshWithTokenClient(ctx, successor.RefreshToken, ClientInfo{Type: "mobile"}); err == nil {
+		t.Fatal("penerus dalam family yang dicabut tidak boleh berlaku")
+	}
+}
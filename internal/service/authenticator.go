@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+
+	"unila_helpdesk_backend/internal/config"
+	"unila_helpdesk_backend/internal/domain"
+	"unila_helpdesk_backend/internal/ldapauth"
+	"unila_helpdesk_backend/internal/repository"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	authBackendLocal = "local"
+	authBackendLDAP  = "ldap"
+)
+
+// Authenticator memverifikasi username dan password ke satu backend. User yang
+// dikembalikan sudah tersimpan (dan tersinkron) di tabel users.
+type Authenticator interface {
+	Name() string
+	Authenticate(ctx context.Context, username string, password string) (*domain.User, error)
+}
+
+// credentialError berarti backend menolak kredensial sehingga backend berikutnya
+// dicoba. User diisi jika akunnya dikenali, untuk audit login gagal.
+type credentialError struct {
+	user   *domain.User
+	reason string
+}
+
+func (err *credentialError) Error() string {
+	return err.reason
+}
+
+// newAuthenticators menyusun backend sesuai urutan AUTH_BACKENDS. Nama yang tidak
+// dikenal diabaikan (ditolak validateConfig); daftar kosong berarti local saja.
+func newAuthenticators(cfg config.Config, auth *AuthService) []Authenticator {
+	authenticators := make([]Authenticator, 0, 2)
+	for _, name := range splitList(strings.ToLower(cfg.AuthBackends)) {
+		switch name {
+		case authBackendLocal:
+			authenticators = append(authenticators, &localAuthenticator{users: auth.users})
+		case authBackendLDAP:
+			authenticators = append(authenticators, newLDAPAuthenticator(cfg, auth))
+		}
+	}
+	if len(authenticators) == 0 {
+		authenticators = append(authenticators, &localAuthenticator{users: auth.users})
+	}
+	return authenticators
+}
+
+// authenticate mencoba setiap backend berurutan. Kredensial yang ditolak maupun
+// backend yang tidak tersedia dilewati; alasan semua backend dicatat sekali di audit.
+func (service *AuthService) authenticate(ctx context.Context, username string, password string) (*domain.User, string, error) {
+	reasons := make([]string, 0, len(service.authenticators))
+	var knownUser *domain.User
+	for _, authenticator := range service.authenticators {
+		user, err := authenticator.Authenticate(ctx, username, password)
+		if err == nil {
+			return user, authenticator.Name(), nil
+		}
+		var rejected *credentialError
+		if errors.As(err, &rejected) {
+			if rejected.user != nil {
+				knownUser = rejected.user
+			}
+		} else {
+			log.Printf("auth backend %s failed: %v", authenticator.Name(), err)
+		}
+		reasons = append(reasons, authenticator.Name()+": "+err.Error())
+	}
+	service.recordLoginFailure(ctx, knownUser, username, strings.Join(reasons, "; "))
+	return nil, "", errors.New("username atau password salah")
+}
+
+type localAuthenticator struct {
+	users *repository.UserRepository
+}
+
+func (authenticator *localAuthenticator) Name() string {
+	return authBackendLocal
+}
+
+func (authenticator *localAuthenticator) Authenticate(_ context.Context, username string, password string) (*domain.User, error) {
+	user, err := authenticator.users.FindByUsername(username)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, &credentialError{reason: "user tidak ditemukan"}
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Akun hasil SSO/LDAP tidak memiliki password lokal.
+	if user.PasswordHash == "" {
+		return nil, &credentialError{user: user, reason: "akun belum memiliki password"}
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		return nil, &credentialError{user: user, reason: "password salah"}
+	}
+	return user, nil
+}
+
+// ldapAuthenticator melakukan bind ke LDAP kampus lalu menyinkronkan entry user
+// ke tabel users dengan aturan yang sama seperti login OIDC.
+type ldapAuthenticator struct {
+	client            *ldapauth.Client
+	auth              *AuthService
+	idAttribute       string
+	usernameAttribute string
+	emailAttribute    string
+	nameAttribute     string
+	entityAttribute   string
+	groupAttribute    string
+	adminGroups       []string
+	entityMap         map[string]string
+}
+
+func newLDAPAuthenticator(cfg config.Config, auth *AuthService) *ldapAuthenticator {
+	attributes := make([]string, 0, 6)
+	for _, attribute := range []string{
+		cfg.LDAPIDAttribute,
+		cfg.LDAPUsernameAttribute,
+		cfg.LDAPEmailAttribute,
+		cfg.LDAPNameAttribute,
+		cfg.LDAPEntityAttribute,
+		cfg.LDAPGroupAttribute,
+	} {
+		if attribute != "" {
+			attributes = append(attributes, attribute)
+		}
+	}
+	return &ldapAuthenticator{
+		client: ldapauth.NewClient(ldapauth.Config{
+			URL:                cfg.LDAPURL,
+			StartTLS:           cfg.LDAPStartTLS,
+			InsecureSkipVerify: cfg.LDAPInsecureSkipTLS,
+			BindDN:             cfg.LDAPBindDN,
+			BindPassword:       cfg.LDAPBindPassword,
+			BaseDN:             cfg.LDAPBaseDN,
+			UserFilter:         cfg.LDAPUserFilter,
+			Attributes:         attributes,
+			Timeout:            cfg.LDAPTimeout,
+		}),
+		auth:              auth,
+		idAttribute:       cfg.LDAPIDAttribute,
+		usernameAttribute: cfg.LDAPUsernameAttribute,
+		emailAttribute:    cfg.LDAPEmailAttribute,
+		nameAttribute:     cfg.LDAPNameAttribute,
+		entityAttribute:   cfg.LDAPEntityAttribute,
+		groupAttribute:    cfg.LDAPGroupAttribute,
+		adminGroups:       splitList(cfg.LDAPAdminGroups),
+		entityMap:         parseEntityMap(cfg.LDAPEntityMap),
+	}
+}
+
+func (authenticator *ldapAuthenticator) Name() string {
+	return authBackendLDAP
+}
+
+func (authenticator *ldapAuthenticator) Authenticate(ctx context.Context, username string, password string) (*domain.User, error) {
+	entry, err := authenticator.client.Authenticate(ctx, username, password)
+	if errors.Is(err, ldapauth.ErrInvalidCredentials) {
+		return nil, &credentialError{reason: err.Error()}
+	}
+	if err != nil {
+		return nil, err
+	}
+	ldapUsername := entry.First(authenticator.usernameAttribute)
+	if ldapUsername == "" {
+		ldapUsername = username
+	}
+	user, action, err := authenticator.auth.syncExternalUser(externalIdentity{
+		Provider: authBackendLDAP,
+		Subject:  entry.ID(authenticator.idAttribute),
+		Username: ldapUsername,
+		Email:    entry.First(authenticator.emailAttribute),
+		// Atribut email dikelola administrator directory, bukan oleh user sendiri.
+		EmailVerified: true,
+		Name:          entry.First(authenticator.nameAttribute),
+		Entity:        mapEntity(entry.Values(authenticator.entityAttribute), authenticator.entityMap),
+		Role:          mapRole(ldapauth.GroupNames(entry.Values(authenticator.groupAttribute)), authenticator.adminGroups),
+	})
+	if err != nil {
+		return nil, err
+	}
+	authenticator.auth.recordProvisioning(ctx, *user, authBackendLDAP, action)
+	return user, nil
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"unila_helpdesk_backend/internal/domain"
+	"unila_helpdesk_backend/internal/ldapauth"
+	"unila_helpdesk_backend/internal/ldapauth/ldaptest"
+)
+
+// stubAuthenticator menggantikan backend local yang membutuhkan database.
+type stubAuthenticator struct {
+	name  string
+	err   error
+	calls int
+}
+
+func (authenticator *stubAuthenticator) Name() string {
+	return authenticator.name
+}
+
+func (authenticator *stubAuthenticator) Authenticate(context.Context, string, string) (*domain.User, error) {
+	authenticator.calls++
+	return nil, authenticator.err
+}
+
+// directoryAuthenticator memverifikasi ke server LDAP sungguhan tanpa sinkronisasi
+// ke tabel users.
+type directoryAuthenticator struct {
+	client *ldapauth.Client
+}
+
+func (authenticator *directoryAuthenticator) Name() string {
+	return authBackendLDAP
+}
+
+func (authenticator *directoryAuthenticator) Authenticate(ctx context.Context, username string, password string) (*domain.User, error) {
+	entry, err := authenticator.client.Authenticate(ctx, username, password)
+	if errors.Is(err, ldapauth.ErrInvalidCredentials) {
+		return nil, &credentialError{reason: err.Error()}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &domain.User{ID: entry.ID("entryUUID"), Username: entry.First("uid"), Email: entry.First("mail")}, nil
+}
+
+func newDirectoryAuthenticator(t *testing.T) *directoryAuthenticator {
+	t.Helper()
+	server, err := ldaptest.NewServer([]ldaptest.Entry{{
+		DN: "uid=budi,ou=people,dc=unila,dc=local",
+		Attributes: map[string][]string{
+			"uid":          {"budi"},
+			"mail":         {"budi@unila.local"},
+			"entryUUID":    {"3f1c2a90-0001-4c1e-9a51-6d1f4b8e0001"},
+			"userPassword": {"rahasia"},
+		},
+	}})
+	if err != nil {
+		t.Fatalf("start ldap: %v", err)
+	}
+	t.Cleanup(func() { _ = server.Close() })
+	return &directoryAuthenticator{client: ldapauth.NewClient(ldapauth.Config{
+		URL:        server.URL,
+		BaseDN:     "dc=unila,dc=local",
+		Attributes: []string{"uid", "mail", "entryUUID"},
+		Timeout:    2 * time.Second,
+	})}
+}
+
+func TestAuthenticateFallsBackFromLocalToLDAP(t *testing.T) {
+	cases := []struct {
+		name     string
+		localErr error
+	}{
+		{name: "user lokal tidak ada", localErr: &credentialError{reason: "user tidak ditemukan"}},
+		{name: "password lokal salah", localErr: &credentialError{user: &domain.User{ID: "u-1"}, reason: "password salah"}},
+		{name: "backend lokal error", localErr: errors.New("database tidak tersedia")},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			local := &stubAuthenticator{name: authBackendLocal, err: tc.localErr}
+			service := &AuthService{authenticators: []Authenticator{local, newDirectoryAuthenticator(t)}}
+
+			user, backend, err := service.authenticate(context.Background(), "budi", "rahasia")
+			if err != nil {
+				t.Fatalf("authenticate: %v", err)
+			}
+			if backend != authBackendLDAP || user.Username != "budi" {
+				t.Fatalf("backend = %q, user = %+v", backend, user)
+			}
+			if local.calls != 1 {
+				t.Fatalf("backend local dipanggil %d kali", local.calls)
+			}
+		})
+	}
+}
+
+func TestAuthenticateFailsWhenEveryBackendRejects(t *testing.T) {
+	local := &stubAuthenticator{name: authBackendLocal, err: &credentialError{reason: "user tidak ditemukan"}}
+	service := &AuthService{authenticators: []Authenticator{local, newDirectoryAuthenticator(t)}}
+
+	for _, password := range []string{"salah", ""} {
+		if _, _, err := service.authenticate(context.Background(), "budi", password); err == nil {
+			t.Fatalf("password %q seharusnya ditolak", password)
+		}
+	}
+	if _, _, err := service.authenticate(context.Background(), "budi)(uid=*", "rahasia"); err == nil {
+		t.Fatal("username dengan filter injeksi seharusnya ditolak")
+	}
+}
@@ -0,0 +1,467 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"unila_helpdesk_backend/internal/domain"
+	"unila_helpdesk_backend/internal/repository"
+	"unila_helpdesk_backend/internal/util"
+)
+
+const generalCannedCategoryLabel = "Umum"
+
+type CannedResponseService struct {
+	canned     *repository.CannedResponseRepository
+	categories *repository.CategoryRepository
+	tickets    *repository.TicketRepository
+	ticketing  *TicketService
+	audit      *AuditService
+}
+
+type CannedResponseRequest struct {
+	Title      string `json:"title"`
+	Body       string `json:"body"`
+	CategoryID string `json:"categoryId"`
+}
+
+type MacroRequest struct {
+	Name             string               `json:"name"`
+	CategoryID       string               `json:"categoryId"`
+	CannedResponseID string               `json:"cannedResponseId"`
+	Status           *domain.TicketStatus `json:"status"`
+	Assignee         string               `json:"assignee"`
+}
+
+func NewCannedResponseService(
+	canned *repository.CannedResponseRepository,
+	categories *repository.CategoryRepository,
+	tickets *repository.TicketRepository,
+	ticketing *TicketService,
+	audit *AuditService,
+) *CannedResponseService {
+	return &CannedResponseService{
+		canned:     canned,
+		categories: categories,
+		tickets:    tickets,
+		ticketing:  ticketing,
+		audit:      audit,
+	}
+}
+
+// List mengelompokkan balasan per kategori. Jika categoryID diisi, balasan milik
+// parent kategori dan balasan umum ikut ditampilkan.
+func (service *CannedResponseService) List(categoryID string) ([]domain.CannedResponseGroupDTO, error) {
+	tree, err := service.loadTree()
+	if err != nil {
+		return nil, err
+	}
+	var scope []string
+	if categoryID != "" {
+		if _, ok := tree.byID[categoryID]; !ok {
+			return nil, errors.New("kategori tidak ditemukan")
+		}
+		scope = tree.ancestors(categoryID)
+	}
+	items, err := service.canned.List(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]domain.CannedResponseGroupDTO, 0)
+	index := make(map[string]int)
+	for _, item := range items {
+		position, ok := index[item.CategoryID]
+		if !ok {
+			label := generalCannedCategoryLabel
+			if item.CategoryID != "" {
+				label = tree.path(item.CategoryID)
+			}
+			groups = append(groups, domain.CannedResponseGroupDTO{
+				CategoryID: item.CategoryID,
+				Category:   label,
+				Items:      []domain.CannedResponseDTO{},
+			})
+			position = len(groups) - 1
+			index[item.CategoryID] = position
+		}
+		groups[position].Items = append(groups[position].Items, toCannedResponseDTO(item))
+	}
+	return groups, nil
+}
+
+func (service *CannedResponseService) Create(
+	ctx context.Context,
+	user domain.User,
+	req CannedResponseRequest,
+) (domain.CannedResponseDTO, error) {
+	item := domain.CannedResponse{
+		ID:        util.NewUUID(),
+		CreatedBy: user.ID,
+	}
+	if err := service.applyCannedRequest(&item, req); err != nil {
+		return domain.CannedResponseDTO{}, err
+	}
+	if err := service.canned.Create(&item); err != nil {
+		return domain.CannedResponseDTO{}, err
+	}
+	service.audit.Record(ctx, AuditEntry{
+		Actor:      &user,
+		Action:     AuditActionCannedCreate,
+		EntityType: AuditEntityCannedResponse,
+		EntityID:   item.ID,
+		After:      toCannedResponseDTO(item),
+	})
+	return toCannedResponseDTO(item), nil
+}
+
+func (service *CannedResponseService) Update(
+	ctx context.Context,
+	user domain.User,
+	id string,
+	req CannedResponseRequest,
+) (domain.CannedResponseDTO, error) {
+	item, err := service.canned.FindByID(id)
+	if err != nil {
+		return domain.CannedResponseDTO{}, errors.New("balasan tidak ditemukan")
+	}
+	before := toCannedResponseDTO(*item)
+	if err := service.applyCannedRequest(item, req); err != nil {
+		return domain.CannedResponseDTO{}, err
+	}
+	if err := service.canned.Update(item); err != nil {
+		return domain.CannedResponseDTO{}, err
+	}
+	service.audit.Record(ctx, AuditEntry{
+		Actor:      &user,
+		Action:     AuditActionCannedUpdate,
+		EntityType: AuditEntityCannedResponse,
+		EntityID:   item.ID,
+		Before:     before,
+		After:      toCannedResponseDTO(*item),
+	})
+	return toCannedResponseDTO(*item), nil
+}
+
+func (service *CannedResponseService) Delete(ctx context.Context, user domain.User, id string) error {
+	item, err := service.canned.FindByID(id)
+	if err != nil {
+		return errors.New("balasan tidak ditemukan")
+	}
+	if err := service.canned.Delete(item.ID); err != nil {
+		return err
+	}
+	service.audit.Record(ctx, AuditEntry{
+		Actor:      &user,
+		Action:     AuditActionCannedDelete,
+		EntityType: AuditEntityCannedResponse,
+		EntityID:   item.ID,
+		Before:     toCannedResponseDTO(*item),
+	})
+	return nil
+}
+
+// Preview merender placeholder balasan untuk tiket tertentu tanpa menyimpan komentar.
+func (service *CannedResponseService) Preview(user domain.User, id string, ticketID string) (domain.CannedResponsePreviewDTO, error) {
+	item, err := service.canned.FindByID(id)
+	if err != nil {
+		return domain.CannedResponsePreviewDTO{}, errors.New("balasan tidak ditemukan")
+	}
+	ticket, err := service.tickets.FindByID(ticketID)
+	if err != nil {
+		return domain.CannedResponsePreviewDTO{}, errors.New("tiket tidak ditemukan")
+	}
+	return domain.CannedResponsePreviewDTO{
+		ID:       item.ID,
+		TicketID: ticket.ID,
+		Body:     renderCannedText(item.Body, *ticket, user),
+	}, nil
+}
+
+func (service *CannedResponseService) ListMacros(categoryID string) ([]domain.MacroDTO, error) {
+	var scope []string
+	if categoryID != "" {
+		tree, err := service.loadTree()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := tree.byID[categoryID]; !ok {
+			return nil, errors.New("kategori tidak ditemukan")
+		}
+		scope = tree.ancestors(categoryID)
+	}
+	items, err := service.canned.ListMacros(scope)
+	if err != nil {
+		return nil, err
+	}
+	titles := make(map[string]string)
+	if responses, err := service.canned.List(nil); err == nil {
+		for _, response := range responses {
+			titles[response.ID] = response.Title
+		}
+	}
+	result := make([]domain.MacroDTO, 0, len(items))
+	for _, item := range items {
+		result = append(result, toMacroDTO(item, titles[item.CannedResponseID]))
+	}
+	return result, nil
+}
+
+func (service *CannedResponseService) CreateMacro(ctx context.Context, user domain.User, req MacroRequest) (domain.MacroDTO, error) {
+	item := domain.Macro{
+		ID:        util.NewUUID(),
+		CreatedBy: user.ID,
+	}
+	title, err := service.applyMacroRequest(&item, req)
+	if err != nil {
+		return domain.MacroDTO{}, err
+	}
+	if err := service.canned.CreateMacro(&item); err != nil {
+		return domain.MacroDTO{}, err
+	}
+	result := toMacroDTO(item, title)
+	service.audit.Record(ctx, AuditEntry{
+		Actor:      &user,
+		Action:     AuditActionMacroCreate,
+		EntityType: AuditEntityMacro,
+		EntityID:   item.ID,
+		After:      result,
+	})
+	return result, nil
+}
+
+func (service *CannedResponseService) UpdateMacro(
+	ctx context.Context,
+	user domain.User,
+	id string,
+	req MacroRequest,
+) (domain.MacroDTO, error) {
+	item, err := service.canned.FindMacroByID(id)
+	if err != nil {
+		return domain.MacroDTO{}, errors.New("macro tidak ditemukan")
+	}
+	before := toMacroDTO(*item, "")
+	title, err := service.applyMacroRequest(item, req)
+	if err != nil {
+		return domain.MacroDTO{}, err
+	}
+	if err := service.canned.UpdateMacro(item); err != nil {
+		return domain.MacroDTO{}, err
+	}
+	result := toMacroDTO(*item, title)
+	service.audit.Record(ctx, AuditEntry{
+		Actor:      &user,
+		Action:     AuditActionMacroUpdate,
+		EntityType: AuditEntityMacro,
+		EntityID:   item.ID,
+		Before:     before,
+		After:      result,
+	})
+	return result, nil
+}
+
+func (service *CannedResponseService) DeleteMacro(ctx context.Context, user domain.User, id string) error {
+	item, err := service.canned.FindMacroByID(id)
+	if err != nil {
+		return errors.New("macro tidak ditemukan")
+	}
+	if err := service.canned.DeleteMacro(item.ID); err != nil {
+		return err
+	}
+	service.audit.Record(ctx, AuditEntry{
+		Actor:      &user,
+		Action:     AuditActionMacroDelete,
+		EntityType: AuditEntityMacro,
+		EntityID:   item.ID,
+		Before:     toMacroDTO(*item, ""),
+	})
+	return nil
+}
+
+// ApplyMacro mengubah status dan petugas lalu menambahkan komentar dari balasan
+// template melalui UpdateTicket dan AddComment, sehingga notifikasi, riwayat, dan
+// audit sama persis dengan aksi manual.
+func (service *CannedResponseService) ApplyMacro(
+	ctx context.Context,
+	user domain.User,
+	ticketID string,
+	macroID string,
+) (domain.TicketDTO, error) {
+	macro, err := service.canned.FindMacroByID(macroID)
+	if err != nil {
+		return domain.TicketDTO{}, errors.New("macro tidak ditemukan")
+	}
+	ticket, err := service.tickets.FindByID(ticketID)
+	if err != nil {
+		return domain.TicketDTO{}, errors.New("tiket tidak ditemukan")
+	}
+	if macro.CategoryID != "" {
+		tree, err := service.loadTree()
+		if err != nil {
+			return domain.TicketDTO{}, err
+		}
+		if !slices.Contains(tree.ancestors(ticket.CategoryID), macro.CategoryID) {
+			return domain.TicketDTO{}, errors.New("macro tidak berlaku untuk kategori tiket ini")
+		}
+	}
+
+	// Balasan divalidasi sebelum tiket diubah, lalu perubahan status dan petugas
+	// (yang paling mungkin ditolak) dijalankan lebih dulu agar komentar tidak
+	// tersimpan untuk macro yang gagal diterapkan.
+	var response *domain.CannedResponse
+	if macro.CannedResponseID != "" {
+		response, err = service.canned.FindByID(macro.CannedResponseID)
+		if err != nil {
+			return domain.TicketDTO{}, errors.New("balasan macro tidak ditemukan")
+		}
+		if strings.TrimSpace(renderCannedText(response.Body, *ticket, user)) == "" {
+			return domain.TicketDTO{}, errors.New("balasan macro kosong")
+		}
+	}
+
+	result, err := service.ticketing.GetTicket(&user, ticket.ID)
+	if err != nil {
+		return domain.TicketDTO{}, err
+	}
+	update := TicketUpdateRequest{}
+	if macro.Status != "" {
+		status := macro.Status
+		update.Status = &status
+	}
+	if macro.Assignee != "" {
+		assignee := renderCannedText(macro.Assignee, *ticket, user)
+		update.Assignee = &assignee
+	}
+	if update.Status != nil || update.Assignee != nil {
+		result, err = service.ticketing.UpdateTicket(ctx, user, ticket.ID, update)
+		if err != nil {
+			return domain.TicketDTO{}, err
+		}
+	}
+	if response != nil {
+		// Dirender ulang dari tiket terbaru agar placeholder seperti {{status}}
+		// mencerminkan hasil macro, bukan nilai sebelum diubah.
+		updated, err := service.tickets.FindByID(ticket.ID)
+		if err != nil {
+			return domain.TicketDTO{}, errors.New("tiket tidak ditemukan")
+		}
+		comment := renderCannedText(response.Body, *updated, user)
+		if strings.TrimSpace(comment) == "" {
+			return domain.TicketDTO{}, errors.New("balasan macro kosong")
+		}
+		result, err = service.ticketing.AddComment(ctx, user, ticket.ID, comment)
+		if err != nil {
+			return domain.TicketDTO{}, err
+		}
+	}
+	return result, nil
+}
+
+func (service *CannedResponseService) applyCannedRequest(item *domain.CannedResponse, req CannedResponseRequest) error {
+	title := strings.TrimSpace(req.Title)
+	body := strings.TrimSpace(req.Body)
+	if title == "" {
+		return errors.New("judul balasan wajib diisi")
+	}
+	if body == "" {
+		return errors.New("isi balasan wajib diisi")
+	}
+	categoryID := strings.TrimSpace(req.CategoryID)
+	if categoryID != "" {
+		if _, err := service.categories.FindByID(categoryID); err != nil {
+			return errors.New("kategori tidak ditemukan")
+		}
+	}
+	item.Title = title
+	item.Body = body
+	item.CategoryID = categoryID
+	return nil
+}
+
+func (service *CannedResponseService) applyMacroRequest(item *domain.Macro, req MacroRequest) (string, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return "", errors.New("nama macro wajib diisi")
+	}
+	categoryID := strings.TrimSpace(req.CategoryID)
+	if categoryID != "" {
+		if _, err := service.categories.FindByID(categoryID); err != nil {
+			return "", errors.New("kategori tidak ditemukan")
+		}
+	}
+	var status domain.TicketStatus
+	if req.Status != nil {
+		switch *req.Status {
+		case domain.StatusWaiting, domain.StatusInProgress, domain.StatusResolved:
+			status = *req.Status
+		case "":
+		default:
+			return "", errors.New("status tiket tidak valid")
+		}
+	}
+	cannedTitle := ""
+	cannedID := strings.TrimSpace(req.CannedResponseID)
+	if cannedID != "" {
+		response, err := service.canned.FindByID(cannedID)
+		if err != nil {
+			return "", errors.New("balasan tidak ditemukan")
+		}
+		cannedTitle = response.Title
+	}
+	assignee := strings.TrimSpace(req.Assignee)
+	if cannedID == "" && status == "" && assignee == "" {
+		return "", errors.New("macro harus berisi balasan, status, atau petugas")
+	}
+
+	item.Name = name
+	item.CategoryID = categoryID
+	item.CannedResponseID = cannedID
+	item.Status = status
+	item.Assignee = assignee
+	return cannedTitle, nil
+}
+
+func (service *CannedResponseService) loadTree() (categoryTree, error) {
+	items, err := service.categories.List()
+	if err != nil {
+		return categoryTree{}, err
+	}
+	return newCategoryTree(items), nil
+}
+
+// renderCannedText mengganti placeholder yang didukung; placeholder lain dibiarkan apa adanya.
+func renderCannedText(text string, ticket domain.Ticket, agent domain.User) string {
+	replacer := strings.NewReplacer(
+		"{{reporter_name}}", ticket.ReporterName,
+		"{{ticket_id}}", ticket.ID,
+		"{{ticket_title}}", ticket.Title,
+		"{{category}}", ticket.Category.Name,
+		"{{status}}", statusLabel(ticket.Status),
+		"{{agent_name}}", agent.Name,
+	)
+	return replacer.Replace(text)
+}
+
+func toCannedResponseDTO(item domain.CannedResponse) domain.CannedResponseDTO {
+	return domain.CannedResponseDTO{
+		ID:         item.ID,
+		Title:      item.Title,
+		Body:       item.Body,
+		CategoryID: item.CategoryID,
+		UpdatedAt:  item.UpdatedAt,
+	}
+}
+
+func toMacroDTO(item domain.Macro, cannedTitle string) domain.MacroDTO {
+	return domain.MacroDTO{
+		ID:               item.ID,
+		Name:             item.Name,
+		CategoryID:       item.CategoryID,
+		CannedResponseID: item.CannedResponseID,
+		CannedResponse:   cannedTitle,
+		Status:           item.Status,
+		Assignee:         item.Assignee,
+	}
+}
@@ -0,0 +1,296 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"unila_helpdesk_backend/internal/domain"
+	"unila_helpdesk_backend/internal/util"
+
+	"gorm.io/datatypes"
+)
+
+const (
+	maxCategoryFields     = 30
+	customFieldDateLayout = "2006-01-02"
+)
+
+var categoryFieldKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,39}$`)
+
+type CategoryFieldRequest struct {
+	Key      string                   `json:"key"`
+	Label    string                   `json:"label"`
+	Type     domain.CategoryFieldType `json:"type"`
+	Required bool                     `json:"required"`
+	Options  []string                 `json:"options"`
+}
+
+func (service *CategoryService) ListFields(categoryID string) ([]domain.CategoryFieldDTO, error) {
+	category, err := service.categories.FindByID(categoryID)
+	if err != nil {
+		return nil, errors.New("kategori tidak ditemukan")
+	}
+	return toCategoryFieldDTOs(category.Fields), nil
+}
+
+// ReplaceFields mengganti seluruh definisi field kustom kategori. Nilai yang sudah
+// tersimpan di tiket lama tidak diubah.
+func (service *CategoryService) ReplaceFields(
+	ctx context.Context,
+	user domain.User,
+	categoryID string,
+	req []CategoryFieldRequest,
+) ([]domain.CategoryFieldDTO, error) {
+	category, err := service.categories.FindByID(categoryID)
+	if err != nil {
+		return nil, errors.New("kategori tidak ditemukan")
+	}
+	if len(req) > maxCategoryFields {
+		return nil, fmt.Errorf("maksimal %d field per kategori", maxCategoryFields)
+	}
+
+	fields := make([]domain.CategoryField, 0, len(req))
+	seen := make(map[string]bool, len(req))
+	for index, item := range req {
+		field, err := buildCategoryField(category.ID, index, item)
+		if err != nil {
+			return nil, err
+		}
+		if seen[field.Key] {
+			return nil, fmt.Errorf("key field %s duplikat", field.Key)
+		}
+		seen[field.Key] = true
+		fields = append(fields, field)
+	}
+
+	if err := service.categories.ReplaceFields(category.ID, fields); err != nil {
+		return nil, err
+	}
+	result := toCategoryFieldDTOs(fields)
+	service.audit.Record(ctx, AuditEntry{
+		Actor:      &user,
+		Action:     AuditActionCategoryFields,
+		EntityType: AuditEntityCategory,
+		EntityID:   category.ID,
+		Before:     map[string]any{"fields": toCategoryFieldDTOs(category.Fields)},
+		After:      map[string]any{"fields": result},
+	})
+	return result, nil
+}
+
+// resolveCustomFields memvalidasi ulang nilai field kustom tiket. Jika values nil
+// (mis. hanya kategori yang berubah), nilai lama yang masih dikenal kategori baru dipertahankan.
+func (service *TicketService) resolveCustomFields(ticket *domain.Ticket, values map[string]any) (datatypes.JSON, error) {
+	category, err := service.categories.FindByID(ticket.CategoryID)
+	if err != nil {
+		return nil, errors.New("kategori tidak ditemukan")
+	}
+	if values == nil {
+		values = map[string]any{}
+		for key, value := range decodeCustomFields(ticket.CustomFields) {
+			if slices.ContainsFunc(category.Fields, func(field domain.CategoryField) bool { return field.Key == key }) {
+				values[key] = value
+			}
+		}
+	}
+	normalized, err := normalizeCustomFields(category.Fields, values)
+	if err != nil {
+		return nil, err
+	}
+	return marshalCustomFields(normalized), nil
+}
+
+func buildCategoryField(categoryID string, index int, req CategoryFieldRequest) (domain.CategoryField, error) {
+	key := strings.TrimSpace(req.Key)
+	if !categoryFieldKeyPattern.MatchString(key) {
+		return domain.CategoryField{}, fmt.Errorf("key field %q tidak valid", req.Key)
+	}
+	label := strings.TrimSpace(req.Label)
+	if label == "" {
+		return domain.CategoryField{}, fmt.Errorf("label field %s wajib diisi", key)
+	}
+
+	field := domain.CategoryField{
+		ID:         util.NewUUID(),
+		CategoryID: categoryID,
+		Key:        key,
+		Label:      label,
+		Type:       req.Type,
+		Required:   req.Required,
+		SortOrder:  index,
+	}
+	switch req.Type {
+	case domain.FieldText, domain.FieldNumber, domain.FieldDate, domain.FieldBoolean:
+	case domain.FieldSelect:
+		options := make([]string, 0, len(req.Options))
+		for _, option := range req.Options {
+			option = strings.TrimSpace(option)
+			if option != "" && !slices.Contains(options, option) {
+				options = append(options, option)
+			}
+		}
+		if len(options) == 0 {
+			return domain.CategoryField{}, fmt.Errorf("field %s membutuhkan pilihan", key)
+		}
+		payload, err := json.Marshal(options)
+		if err != nil {
+			return domain.CategoryField{}, err
+		}
+		field.Options = datatypes.JSON(payload)
+	default:
+		return domain.CategoryField{}, fmt.Errorf("tipe field %s tidak valid", key)
+	}
+	return field, nil
+}
+
+// normalizeCustomFields memvalidasi nilai field kustom terhadap definisi kategori
+// dan mengembalikan nilai yang sudah dikonversi ke tipe masing-masing.
+func normalizeCustomFields(fields []domain.CategoryField, values map[string]any) (map[string]any, error) {
+	definitions := make(map[string]domain.CategoryField, len(fields))
+	for _, field := range fields {
+		definitions[field.Key] = field
+	}
+	for key := range values {
+		if _, ok := definitions[key]; !ok {
+			return nil, fmt.Errorf("field %s tidak dikenal untuk kategori ini", key)
+		}
+	}
+
+	result := make(map[string]any, len(fields))
+	for _, field := range fields {
+		raw, ok := values[field.Key]
+		if !ok || isEmptyCustomValue(raw) {
+			if field.Required {
+				return nil, fmt.Errorf("%s wajib diisi", field.Label)
+			}
+			continue
+		}
+		value, err := convertCustomValue(field, raw)
+		if err != nil {
+			return nil, err
+		}
+		result[field.Key] = value
+	}
+	return result, nil
+}
+
+func convertCustomValue(field domain.CategoryField, raw any) (any, error) {
+	invalid := fmt.Errorf("nilai %s tidak valid", field.Label)
+	switch field.Type {
+	case domain.FieldText:
+		text, ok := raw.(string)
+		if !ok {
+			return nil, invalid
+		}
+		return strings.TrimSpace(text), nil
+	case domain.FieldNumber:
+		switch value := raw.(type) {
+		case float64:
+			if math.IsNaN(value) || math.IsInf(value, 0) {
+				return nil, invalid
+			}
+			return value, nil
+		case string:
+			number, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+				return nil, invalid
+			}
+			return number, nil
+		}
+		return nil, invalid
+	case domain.FieldSelect:
+		text, ok := raw.(string)
+		if !ok {
+			return nil, invalid
+		}
+		var options []string
+		_ = json.Unmarshal(field.Options, &options)
+		text = strings.TrimSpace(text)
+		if !slices.Contains(options, text) {
+			return nil, invalid
+		}
+		return text, nil
+	case domain.FieldDate:
+		text, ok := raw.(string)
+		if !ok {
+			return nil, invalid
+		}
+		date, err := time.Parse(customFieldDateLayout, strings.TrimSpace(text))
+		if err != nil {
+			return nil, fmt.Errorf("%s harus berformat YYYY-MM-DD", field.Label)
+		}
+		return date.Format(customFieldDateLayout), nil
+	case domain.FieldBoolean:
+		switch value := raw.(type) {
+		case bool:
+			return value, nil
+		case string:
+			parsed, err := strconv.ParseBool(strings.TrimSpace(value))
+			if err != nil {
+				return nil, invalid
+			}
+			return parsed, nil
+		}
+		return nil, invalid
+	}
+	return nil, invalid
+}
+
+func isEmptyCustomValue(raw any) bool {
+	if raw == nil {
+		return true
+	}
+	if text, ok := raw.(string); ok {
+		return strings.TrimSpace(text) == ""
+	}
+	return false
+}
+
+func marshalCustomFields(values map[string]any) datatypes.JSON {
+	if len(values) == 0 {
+		return nil
+	}
+	payload, err := json.Marshal(values)
+	if err != nil {
+		return nil
+	}
+	return datatypes.JSON(payload)
+}
+
+func decodeCustomFields(payload datatypes.JSON) map[string]any {
+	if len(payload) == 0 {
+		return nil
+	}
+	values := map[string]any{}
+	if err := json.Unmarshal(payload, &values); err != nil || len(values) == 0 {
+		return nil
+	}
+	return values
+}
+
+func toCategoryFieldDTOs(fields []domain.CategoryField) []domain.CategoryFieldDTO {
+	result := make([]domain.CategoryFieldDTO, 0, len(fields))
+	for _, field := range fields {
+		var options []string
+		if len(field.Options) > 0 {
+			_ = json.Unmarshal(field.Options, &options)
+		}
+		result = append(result, domain.CategoryFieldDTO{
+			ID:       field.ID,
+			Key:      field.Key,
+			Label:    field.Label,
+			Type:     field.Type,
+			Required: field.Required,
+			Options:  options,
+		})
+	}
+	return result
+}
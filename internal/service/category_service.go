@@ -1,58 +1,440 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
 
 	"unila_helpdesk_backend/internal/domain"
 	"unila_helpdesk_backend/internal/repository"
 )
 
+const maxCategoryIDLength = 60
+
+var categoryIDPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+type CategoryCreateRequest struct {
+	ID           string `json:"id"`
+	ParentID     string `json:"parentId"`
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	Icon         string `json:"icon"`
+	GuestAllowed bool   `json:"guestAllowed"`
+}
+
+type CategoryUpdateRequest struct {
+	// ParentID kosong ("") memindahkan kategori menjadi root.
+	ParentID     *string `json:"parentId"`
+	Name         *string `json:"name"`
+	Description  *string `json:"description"`
+	Icon         *string `json:"icon"`
+	GuestAllowed *bool   `json:"guestAllowed"`
+}
+
 type CategoryService struct {
 	categories *repository.CategoryRepository
+	audit      *AuditService
 }
 
-func NewCategoryService(categories *repository.CategoryRepository) *CategoryService {
-	return &CategoryService{categories: categories}
+func NewCategoryService(categories *repository.CategoryRepository, audit *AuditService) *CategoryService {
+	return &CategoryService{categories: categories, audit: audit}
 }
 
 func (service *CategoryService) ListAll() ([]domain.ServiceCategoryDTO, error) {
-	items, err := service.categories.List()
-	if err != nil {
-		return nil, err
-	}
-	return toCategoryDTOs(items), nil
+	return service.listFiltered(func(item domain.ServiceCategory) bool {
+		return item.ArchivedAt == nil
+	})
+}
+
+// ListAdmin menyertakan kategori yang diarsipkan.
+func (service *CategoryService) ListAdmin() ([]domain.ServiceCategoryDTO, error) {
+	return service.listFiltered(func(domain.ServiceCategory) bool { return true })
 }
 
 func (service *CategoryService) ListGuest() ([]domain.ServiceCategoryDTO, error) {
+	return service.listFiltered(func(item domain.ServiceCategory) bool {
+		return item.ArchivedAt == nil && item.GuestAllowed
+	})
+}
+
+// listFiltered selalu memuat seluruh kategori agar path dan pewarisan template
+// tetap benar walaupun parent tidak ikut ditampilkan.
+func (service *CategoryService) listFiltered(keep func(domain.ServiceCategory) bool) ([]domain.ServiceCategoryDTO, error) {
 	items, err := service.categories.List()
 	if err != nil {
 		return nil, err
 	}
-	filtered := make([]domain.ServiceCategory, 0)
+	tree := newCategoryTree(items)
+	result := make([]domain.ServiceCategoryDTO, 0, len(items))
 	for _, item := range items {
-		if item.GuestAllowed {
-			filtered = append(filtered, item)
+		if keep(item) {
+			result = append(result, toCategoryDTO(item, tree))
 		}
 	}
-	return toCategoryDTOs(filtered), nil
+	return result, nil
 }
 
-func (service *CategoryService) AssignTemplate(categoryID string, templateID string) error {
+func (service *CategoryService) loadTree() (categoryTree, error) {
+	items, err := service.categories.List()
+	if err != nil {
+		return categoryTree{}, err
+	}
+	return newCategoryTree(items), nil
+}
+
+func (service *CategoryService) categoryDTO(categoryID string) (domain.ServiceCategoryDTO, error) {
+	tree, err := service.loadTree()
+	if err != nil {
+		return domain.ServiceCategoryDTO{}, err
+	}
+	item, ok := tree.byID[categoryID]
+	if !ok {
+		return domain.ServiceCategoryDTO{}, errors.New("kategori tidak ditemukan")
+	}
+	return toCategoryDTO(item, tree), nil
+}
+
+func (service *CategoryService) AssignTemplate(ctx context.Context, user domain.User, categoryID string, templateID string) error {
 	if categoryID == "" {
 		return errors.New("kategori tidak ditemukan")
 	}
-	return service.categories.UpdateTemplate(categoryID, templateID)
+	category, err := service.categories.FindByID(categoryID)
+	if err != nil {
+		return errors.New("kategori tidak ditemukan")
+	}
+	if err := service.categories.UpdateTemplate(categoryID, templateID); err != nil {
+		return err
+	}
+	service.audit.Record(ctx, AuditEntry{
+		Actor:      &user,
+		Action:     AuditActionCategoryTemplate,
+		EntityType: AuditEntityCategory,
+		EntityID:   categoryID,
+		Before:     map[string]any{"templateId": category.SurveyTemplateID},
+		After:      map[string]any{"templateId": templateID},
+	})
+	return nil
 }
 
-func toCategoryDTOs(items []domain.ServiceCategory) []domain.ServiceCategoryDTO {
-	result := make([]domain.ServiceCategoryDTO, 0, len(items))
+func (service *CategoryService) Create(ctx context.Context, user domain.User, req CategoryCreateRequest) (domain.ServiceCategoryDTO, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return domain.ServiceCategoryDTO{}, errors.New("nama kategori wajib diisi")
+	}
+	id := strings.TrimSpace(req.ID)
+	if id == "" {
+		id = slugify(name, maxCategoryIDLength)
+	}
+	if !categoryIDPattern.MatchString(id) {
+		return domain.ServiceCategoryDTO{}, errors.New("ID kategori hanya boleh huruf kecil, angka, dan tanda hubung")
+	}
+	if _, err := service.categories.FindByID(id); err == nil {
+		return domain.ServiceCategoryDTO{}, errors.New("ID kategori sudah digunakan")
+	}
+	if _, err := service.categories.FindByName(name); err == nil {
+		return domain.ServiceCategoryDTO{}, errors.New("nama kategori sudah digunakan")
+	}
+	parentID := strings.TrimSpace(req.ParentID)
+	if parentID != "" {
+		tree, err := service.loadTree()
+		if err != nil {
+			return domain.ServiceCategoryDTO{}, err
+		}
+		if err := validateCategoryParent(tree, id, parentID); err != nil {
+			return domain.ServiceCategoryDTO{}, err
+		}
+	}
+	sortOrder, err := service.categories.NextSortOrder()
+	if err != nil {
+		return domain.ServiceCategoryDTO{}, err
+	}
+
+	category := domain.ServiceCategory{
+		ID:           id,
+		ParentID:     parentID,
+		Name:         name,
+		Description:  strings.TrimSpace(req.Description),
+		Icon:         strings.TrimSpace(req.Icon),
+		GuestAllowed: req.GuestAllowed,
+		SortOrder:    sortOrder,
+	}
+	if err := service.categories.Create(&category); err != nil {
+		return domain.ServiceCategoryDTO{}, err
+	}
+	service.audit.Record(ctx, AuditEntry{
+		Actor:      &user,
+		Action:     AuditActionCategoryCreate,
+		EntityType: AuditEntityCategory,
+		EntityID:   category.ID,
+		After:      categoryAuditSnapshot(category),
+	})
+	return service.categoryDTO(category.ID)
+}
+
+func (service *CategoryService) Update(
+	ctx context.Context,
+	user domain.User,
+	categoryID string,
+	req CategoryUpdateRequest,
+) (domain.ServiceCategoryDTO, error) {
+	category, err := service.categories.FindByID(categoryID)
+	if err != nil {
+		return domain.ServiceCategoryDTO{}, errors.New("kategori tidak ditemukan")
+	}
+	before := categoryAuditSnapshot(*category)
+
+	updates := map[string]any{}
+	if req.ParentID != nil {
+		parentID := strings.TrimSpace(*req.ParentID)
+		if parentID != category.ParentID {
+			tree, err := service.loadTree()
+			if err != nil {
+				return domain.ServiceCategoryDTO{}, err
+			}
+			if err := validateCategoryParent(tree, category.ID, parentID); err != nil {
+				return domain.ServiceCategoryDTO{}, err
+			}
+			category.ParentID = parentID
+			updates["parent_id"] = parentID
+		}
+	}
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return domain.ServiceCategoryDTO{}, errors.New("nama kategori wajib diisi")
+		}
+		if existing, err := service.categories.FindByName(name); err == nil && existing.ID != category.ID {
+			return domain.ServiceCategoryDTO{}, errors.New("nama kategori sudah digunakan")
+		}
+		category.Name = name
+		updates["name"] = name
+	}
+	if req.Description != nil {
+		category.Description = strings.TrimSpace(*req.Description)
+		updates["description"] = category.Description
+	}
+	if req.Icon != nil {
+		category.Icon = strings.TrimSpace(*req.Icon)
+		updates["icon"] = category.Icon
+	}
+	if req.GuestAllowed != nil {
+		category.GuestAllowed = *req.GuestAllowed
+		updates["guest_allowed"] = category.GuestAllowed
+	}
+	if len(updates) == 0 {
+		return service.categoryDTO(category.ID)
+	}
+	if err := service.categories.Update(category.ID, updates); err != nil {
+		return domain.ServiceCategoryDTO{}, err
+	}
+	service.audit.Record(ctx, AuditEntry{
+		Actor:      &user,
+		Action:     AuditActionCategoryUpdate,
+		EntityType: AuditEntityCategory,
+		EntityID:   category.ID,
+		Before:     before,
+		After:      categoryAuditSnapshot(*category),
+	})
+	return service.categoryDTO(category.ID)
+}
+
+// SetArchived menyembunyikan kategori dari form tiket tanpa menghapus tiket lama.
+func (service *CategoryService) SetArchived(
+	ctx context.Context,
+	user domain.User,
+	categoryID string,
+	archived bool,
+) (domain.ServiceCategoryDTO, error) {
+	category, err := service.categories.FindByID(categoryID)
+	if err != nil {
+		return domain.ServiceCategoryDTO{}, errors.New("kategori tidak ditemukan")
+	}
+	if (category.ArchivedAt != nil) == archived {
+		return service.categoryDTO(category.ID)
+	}
+	tree, err := service.loadTree()
+	if err != nil {
+		return domain.ServiceCategoryDTO{}, err
+	}
+	if archived && !tree.isLeaf(category.ID) {
+		return domain.ServiceCategoryDTO{}, errors.New("arsipkan sub-kategori terlebih dahulu")
+	}
+	if parent, ok := tree.byID[category.ParentID]; !archived && ok && parent.ArchivedAt != nil {
+		return domain.ServiceCategoryDTO{}, errors.New("parent kategori masih diarsipkan")
+	}
+
+	action := AuditActionCategoryUnarchive
+	var archivedAt *time.Time
+	if archived {
+		now := time.Now()
+		archivedAt = &now
+		action = AuditActionCategoryArchive
+	}
+	if err := service.categories.Update(category.ID, map[string]any{"archived_at": archivedAt}); err != nil {
+		return domain.ServiceCategoryDTO{}, err
+	}
+	service.audit.Record(ctx, AuditEntry{
+		Actor:      &user,
+		Action:     action,
+		EntityType: AuditEntityCategory,
+		EntityID:   category.ID,
+	})
+	return service.categoryDTO(category.ID)
+}
+
+// Reorder menerima daftar ID lengkap sesuai urutan tampilan yang diinginkan.
+func (service *CategoryService) Reorder(ctx context.Context, user domain.User, ids []string) ([]domain.ServiceCategoryDTO, error) {
+	items, err := service.categories.List()
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(items))
 	for _, item := range items {
-		result = append(result, domain.ServiceCategoryDTO{
-			ID:           item.ID,
-			Name:         item.Name,
-			GuestAllowed: item.GuestAllowed,
-			TemplateID:   item.SurveyTemplateID,
-		})
-	}
-	return result
+		known[item.ID] = true
+	}
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if !known[id] {
+			return nil, fmt.Errorf("kategori %s tidak ditemukan", id)
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("kategori %s duplikat", id)
+		}
+		seen[id] = true
+	}
+	if len(ids) != len(items) {
+		return nil, errors.New("urutan harus memuat seluruh kategori")
+	}
+	if err := service.categories.Reorder(ids); err != nil {
+		return nil, err
+	}
+	service.audit.Record(ctx, AuditEntry{
+		Actor:      &user,
+		Action:     AuditActionCategoryReorder,
+		EntityType: AuditEntityCategory,
+		After:      map[string]any{"order": ids},
+	})
+	return service.ListAdmin()
+}
+
+// Merge menggabungkan kategori sumber ke target. Tiket, template survey, saved view, dan
+// sub-kategori dipindahkan ke target, lalu kategori sumber dihapus.
+func (service *CategoryService) Merge(
+	ctx context.Context,
+	user domain.User,
+	sourceID string,
+	targetID string,
+) (domain.ServiceCategoryDTO, error) {
+	if sourceID == targetID {
+		return domain.ServiceCategoryDTO{}, errors.New("kategori sumber dan target tidak boleh sama")
+	}
+	source, err := service.categories.FindByID(sourceID)
+	if err != nil {
+		return domain.ServiceCategoryDTO{}, errors.New("kategori sumber tidak ditemukan")
+	}
+	target, err := service.categories.FindByID(targetID)
+	if err != nil {
+		return domain.ServiceCategoryDTO{}, errors.New("kategori target tidak ditemukan")
+	}
+	if target.ArchivedAt != nil {
+		return domain.ServiceCategoryDTO{}, errors.New("kategori target sudah diarsipkan")
+	}
+	tree, err := service.loadTree()
+	if err != nil {
+		return domain.ServiceCategoryDTO{}, err
+	}
+	if slices.Contains(tree.descendants(source.ID), target.ID) {
+		return domain.ServiceCategoryDTO{}, errors.New("kategori target tidak boleh berada di bawah kategori sumber")
+	}
+	if tree.depth(target.ID)+tree.subtreeHeight(source.ID) > maxCategoryDepth {
+		return domain.ServiceCategoryDTO{}, fmt.Errorf("kedalaman kategori maksimal %d level", maxCategoryDepth)
+	}
+	if err := service.categories.Merge(source.ID, target.ID); err != nil {
+		return domain.ServiceCategoryDTO{}, err
+	}
+	service.audit.Record(ctx, AuditEntry{
+		Actor:      &user,
+		Action:     AuditActionCategoryMerge,
+		EntityType: AuditEntityCategory,
+		EntityID:   source.ID,
+		Before:     categoryAuditSnapshot(*source),
+		After:      map[string]any{"mergedInto": target.ID},
+	})
+	return service.categoryDTO(target.ID)
+}
+
+// slugify mengubah teks bebas menjadi slug huruf kecil dengan tanda hubung.
+func slugify(value string, maxLength int) string {
+	var builder strings.Builder
+	lastDash := true
+	for _, r := range strings.ToLower(value) {
+		switch {
+		case (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9'):
+			builder.WriteRune(r)
+			lastDash = false
+		case !lastDash:
+			builder.WriteByte('-')
+			lastDash = true
+		}
+	}
+	slug := strings.Trim(builder.String(), "-")
+	if len(slug) > maxLength {
+		slug = strings.Trim(slug[:maxLength], "-")
+	}
+	return slug
+}
+
+// validateCategoryParent mencegah siklus dan pohon yang melebihi maxCategoryDepth.
+func validateCategoryParent(tree categoryTree, categoryID string, parentID string) error {
+	if parentID == "" {
+		return nil
+	}
+	parent, ok := tree.byID[parentID]
+	if !ok {
+		return errors.New("parent kategori tidak ditemukan")
+	}
+	if parent.ArchivedAt != nil {
+		return errors.New("parent kategori sudah diarsipkan")
+	}
+	if slices.Contains(tree.ancestors(parentID), categoryID) {
+		return errors.New("parent kategori tidak boleh sub-kategori dirinya sendiri")
+	}
+	if tree.depth(parentID)+1+tree.subtreeHeight(categoryID) > maxCategoryDepth {
+		return fmt.Errorf("kedalaman kategori maksimal %d level", maxCategoryDepth)
+	}
+	return nil
+}
+
+func categoryAuditSnapshot(category domain.ServiceCategory) map[string]any {
+	return map[string]any{
+		"parentId":     category.ParentID,
+		"name":         category.Name,
+		"description":  category.Description,
+		"icon":         category.Icon,
+		"guestAllowed": category.GuestAllowed,
+		"templateId":   category.SurveyTemplateID,
+	}
+}
+
+func toCategoryDTO(item domain.ServiceCategory, tree categoryTree) domain.ServiceCategoryDTO {
+	return domain.ServiceCategoryDTO{
+		ID:                  item.ID,
+		ParentID:            item.ParentID,
+		Name:                item.Name,
+		Path:                tree.path(item.ID),
+		IsLeaf:              tree.isLeaf(item.ID),
+		Description:         item.Description,
+		Icon:                item.Icon,
+		GuestAllowed:        item.GuestAllowed,
+		TemplateID:          item.SurveyTemplateID,
+		EffectiveTemplateID: tree.effectiveTemplateID(item.ID),
+		SortOrder:           item.SortOrder,
+		Archived:            item.ArchivedAt != nil,
+		ArchivedAt:          item.ArchivedAt,
+		Fields:              toCategoryFieldDTOs(item.Fields),
+	}
 }
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"slices"
+	"strings"
+
+	"unila_helpdesk_backend/internal/domain"
+)
+
+// maxCategoryDepth membatasi kedalaman pohon, mis. Sistem Informasi → SIAKAD → KRS.
+const maxCategoryDepth = 4
+
+// categoryTree adalah tampilan pohon kategori di memori. Jumlah kategori kecil sehingga
+// seluruh baris dimuat sekali lalu ditelusuri tanpa query rekursif.
+type categoryTree struct {
+	byID     map[string]domain.ServiceCategory
+	children map[string][]string
+}
+
+func newCategoryTree(items []domain.ServiceCategory) categoryTree {
+	tree := categoryTree{
+		byID:     make(map[string]domain.ServiceCategory, len(items)),
+		children: make(map[string][]string),
+	}
+	for _, item := range items {
+		tree.byID[item.ID] = item
+	}
+	for _, item := range items {
+		if item.ParentID != "" {
+			tree.children[item.ParentID] = append(tree.children[item.ParentID], item.ID)
+		}
+	}
+	return tree
+}
+
+// ancestors mengembalikan ID dari kategori itu sendiri sampai root.
+func (tree categoryTree) ancestors(id string) []string {
+	chain := make([]string, 0, maxCategoryDepth)
+	for current := id; current != "" && len(chain) <= maxCategoryDepth; {
+		if slices.Contains(chain, current) {
+			break
+		}
+		chain = append(chain, current)
+		item, ok := tree.byID[current]
+		if !ok {
+			break
+		}
+		current = item.ParentID
+	}
+	return chain
+}
+
+// descendants mengembalikan ID kategori beserta seluruh turunannya.
+func (tree categoryTree) descendants(id string) []string {
+	result := []string{id}
+	for index := 0; index < len(result); index++ {
+		for _, child := range tree.children[result[index]] {
+			if !slices.Contains(result, child) {
+				result = append(result, child)
+			}
+		}
+	}
+	return result
+}
+
+func (tree categoryTree) depth(id string) int {
+	return len(tree.ancestors(id))
+}
+
+// subtreeHeight menghitung jumlah level di bawah id (0 untuk leaf).
+func (tree categoryTree) subtreeHeight(id string) int {
+	height := 0
+	for _, child := range tree.children[id] {
+		if childHeight := tree.subtreeHeight(child) + 1; childHeight > height {
+			height = childHeight
+		}
+	}
+	return height
+}
+
+func (tree categoryTree) isLeaf(id string) bool {
+	for _, child := range tree.children[id] {
+		if tree.byID[child].ArchivedAt == nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (tree categoryTree) path(id string) string {
+	chain := tree.ancestors(id)
+	names := make([]string, 0, len(chain))
+	for index := len(chain) - 1; index >= 0; index-- {
+		name := tree.byID[chain[index]].Name
+		if name == "" {
+			name = chain[index]
+		}
+		names = append(names, name)
+	}
+	return strings.Join(names, " › ")
+}
+
+// effectiveTemplateID mewariskan template survey dari parent terdekat
+// kecuali kategori tersebut menetapkan template sendiri.
+func (tree categoryTree) effectiveTemplateID(id string) string {
+	for _, ancestor := range tree.ancestors(id) {
+		if templateID := tree.byID[ancestor].SurveyTemplateID; templateID != "" {
+			return templateID
+		}
+	}
+	return ""
+}
+
+// rollupTarget memetakan kategori tiket ke anak langsung dari parentID (root jika kosong).
+// Mengembalikan false jika kategori tidak berada di bawah parentID.
+func (tree categoryTree) rollupTarget(categoryID string, parentID string) (string, bool) {
+	chain := tree.ancestors(categoryID)
+	for index, id := range chain {
+		parent := ""
+		if index+1 < len(chain) {
+			parent = chain[index+1]
+		} else if item, ok := tree.byID[id]; ok {
+			parent = item.ParentID
+		}
+		if parent == parentID {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+func (tree categoryTree) name(id string) string {
+	if item, ok := tree.byID[id]; ok && item.Name != "" {
+		return item.Name
+	}
+	return id
+}
@@ -0,0 +1,39 @@
+package service
+
+import "unila_helpdesk_backend/internal/util"
+
+// cursorLinks menghitung nextCursor/prevCursor untuk halaman keyset.
+// first dan last adalah posisi item pertama dan terakhir sesuai urutan tampilan.
+func cursorLinks(current *util.Cursor, hasMore bool, count int, first util.Cursor, last util.Cursor) (string, string) {
+	backward := current != nil && current.Backward
+	if count == 0 {
+		if current == nil {
+			return "", ""
+		}
+		reverse := *current
+		reverse.Backward = !current.Backward
+		if backward {
+			return util.EncodeCursor(reverse), ""
+		}
+		return "", util.EncodeCursor(reverse)
+	}
+
+	first.Backward = true
+	last.Backward = false
+	next := ""
+	prev := ""
+	if backward {
+		next = util.EncodeCursor(last)
+		if hasMore {
+			prev = util.EncodeCursor(first)
+		}
+		return next, prev
+	}
+	if hasMore {
+		next = util.EncodeCursor(last)
+	}
+	if current != nil {
+		prev = util.EncodeCursor(first)
+	}
+	return next, prev
+}
@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"unila_helpdesk_backend/internal/domain"
+	"unila_helpdesk_backend/internal/util"
+
+	"gorm.io/gorm"
+)
+
+// externalIdentity adalah profil user dari penyedia identitas eksternal (SSO/LDAP).
+type externalIdentity struct {
+	Provider string
+	Subject  string
+	Username string
+	Email    string
+	// EmailVerified wajib true agar identitas boleh ditautkan ke akun yang sudah
+	// ada berdasarkan email.
+	EmailVerified bool
+	Name          string
+	Entity        string
+	// Role kosong berarti role akun yang sudah ada dipertahankan.
+	Role domain.UserRole
+}
+
+const (
+	externalUserCreated = "created"
+	externalUserLinked  = "linked"
+	externalUserUpdated = "updated"
+)
+
+var usernameUnsafe = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// syncExternalUser mencari akun berdasarkan identitas eksternal, lalu berdasarkan
+// email terverifikasi untuk akun yang sudah ada, dan membuat akun baru jika keduanya
+// tidak ditemukan. Profil (nama, entity, role) disinkronkan setiap login.
+func (service *AuthService) syncExternalUser(identity externalIdentity) (*domain.User, string, error) {
+	if identity.Subject == "" {
+		return nil, "", errors.New("identitas eksternal tidak memiliki subject")
+	}
+	email := strings.ToLower(strings.TrimSpace(identity.Email))
+
+	user, err := service.users.FindByIdentity(identity.Provider, identity.Subject)
+	action := externalUserUpdated
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if email == "" {
+			return nil, "", errors.New("akun SSO tidak memiliki email")
+		}
+		user, err = service.users.FindByEmail(email)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return service.createExternalUser(identity, email)
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		if err := service.linkExternalIdentity(user, identity); err != nil {
+			return nil, "", err
+		}
+		action = externalUserLinked
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	if name := strings.TrimSpace(identity.Name); name != "" {
+		user.Name = truncateString(name, 120)
+	}
+	if identity.Entity != "" {
+		user.Entity = identity.Entity
+	}
+	if identity.Role != "" {
+		user.Role = identity.Role
+	}
+	if err := service.users.Update(user); err != nil {
+		return nil, "", err
+	}
+	return user, action, nil
+}
+
+// linkExternalIdentity menautkan identitas ke akun yang emailnya sama. Tanpa email
+// terverifikasi siapa pun yang dapat membuat akun di provider dengan email tersebut
+// bisa mengambil alih akun lokal, sehingga penautan ditolak.
+func (service *AuthService) linkExternalIdentity(user *domain.User, identity externalIdentity) error {
+	if !identity.EmailVerified {
+		return errors.New("email akun SSO belum terverifikasi, tidak dapat ditautkan ke akun yang sudah ada")
+	}
+	existing, err := service.users.FindIdentity(user.ID, identity.Provider)
+	if err == nil && existing.Subject != identity.Subject {
+		return errors.New("email sudah terhubung dengan akun lain")
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return service.users.CreateIdentity(&domain.UserIdentity{
+		ID:       util.NewUUID(),
+		UserID:   user.ID,
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+	})
+}
+
+func (service *AuthService) createExternalUser(identity externalIdentity, email string) (*domain.User, string, error) {
+	username, err := service.availableUsername(identity.Username, email, identity.Subject)
+	if err != nil {
+		return nil, "", err
+	}
+	role := identity.Role
+	if role == "" {
+		role = domain.RoleRegistered
+	}
+	name := strings.TrimSpace(identity.Name)
+	if name == "" {
+		name = username
+	}
+	user := domain.User{
+		ID:       util.NewUUID(),
+		Username: username,
+		Name:     truncateString(name, 120),
+		Email:    email,
+		Role:     role,
+		Entity:   identity.Entity,
+		IsActive: true,
+	}
+	if err := service.users.CreateWithIdentity(&user, &domain.UserIdentity{
+		ID:       util.NewUUID(),
+		UserID:   user.ID,
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+	}); err != nil {
+		return nil, "", err
+	}
+	return &user, externalUserCreated, nil
+}
+
+// recordProvisioning mencatat akun yang dibuat atau ditautkan oleh login eksternal.
+func (service *AuthService) recordProvisioning(ctx context.Context, user domain.User, provider string, action string) {
+	auditAction := ""
+	switch action {
+	case externalUserCreated:
+		auditAction = AuditActionUserProvision
+	case externalUserLinked:
+		auditAction = AuditActionUserLink
+	default:
+		return
+	}
+	service.audit.Record(ctx, AuditEntry{
+		Actor:      &user,
+		Action:     auditAction,
+		EntityType: AuditEntityUser,
+		EntityID:   user.ID,
+		After: map[string]any{
+			"provider": provider,
+			"username": user.Username,
+			"email":    user.Email,
+			"role":     user.Role,
+			"entity":   user.Entity,
+		},
+	})
+}
+
+// availableUsername memakai preferred username atau bagian lokal email, diberi
+// akhiran angka jika sudah dipakai akun lain.
+func (service *AuthService) availableUsername(preferred string, email string, subject string) (string, error) {
+	base := strings.ToLower(strings.TrimSpace(preferred))
+	if base == "" {
+		base, _, _ = strings.Cut(email, "@")
+	}
+	base = strings.Trim(usernameUnsafe.ReplaceAllString(strings.ToLower(base), "-"), "-.")
+	if base == "" {
+		base = "sso-" + strings.ToLower(usernameUnsafe.ReplaceAllString(subject, ""))
+	}
+	base = truncateString(base, 50)
+	for attempt := 1; attempt <= 20; attempt++ {
+		candidate := base
+		if attempt > 1 {
+			candidate = fmt.Sprintf("%s-%d", base, attempt)
+		}
+		exists, err := service.users.UsernameExists(candidate)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return candidate, nil
+		}
+	}
+	return "", errors.New("gagal membuat username unik")
+}
+
+// mapEntity memetakan nilai klaim/atribut (mis. "student") ke Entity helpdesk
+// (Mahasiswa/Dosen/Tendik). Nilai pertama yang dikenal dipakai.
+func mapEntity(values []string, table map[string]string) string {
+	for _, value := range values {
+		if entity, ok := table[strings.ToLower(strings.TrimSpace(value))]; ok {
+			return entity
+		}
+	}
+	return ""
+}
+
+// mapRole mengembalikan admin jika salah satu grup termasuk adminGroups. Tanpa
+// adminGroups role tidak disinkronkan.
+func mapRole(groups []string, adminGroups []string) domain.UserRole {
+	if len(adminGroups) == 0 {
+		return ""
+	}
+	for _, group := range groups {
+		for _, adminGroup := range adminGroups {
+			if strings.EqualFold(strings.TrimSpace(group), adminGroup) {
+				return domain.RoleAdmin
+			}
+		}
+	}
+	return domain.RoleRegistered
+}
+
+// defaultEntityMap dipakai jika OIDC_ENTITY_MAP tidak mengubah pemetaan.
+func defaultEntityMap() map[string]string {
+	return map[string]string{
+		"mahasiswa": "Mahasiswa",
+		"student":   "Mahasiswa",
+		"dosen":     "Dosen",
+		"lecturer":  "Dosen",
+		"faculty":   "Dosen",
+		"tendik":    "Tendik",
+		"staff":     "Tendik",
+		"employee":  "Tendik",
+	}
+}
+
+// parseEntityMap membaca format "student=Mahasiswa,staff=Tendik" di atas pemetaan default.
+func parseEntityMap(raw string) map[string]string {
+	table := defaultEntityMap()
+	for _, pair := range strings.Split(raw, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		if ok && key != "" && value != "" {
+			table[key] = value
+		}
+	}
+	return table
+}
+
+func splitList(raw string) []string {
+	result := make([]string, 0)
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
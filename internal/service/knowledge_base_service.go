@@ -0,0 +1,614 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"unila_helpdesk_backend/internal/domain"
+	"unila_helpdesk_backend/internal/repository"
+	"unila_helpdesk_backend/internal/util"
+)
+
+const (
+	maxArticleSlugLength = 72
+	maxArticleSuggest    = 5
+	// DeflectionSourceGuestForm menandai deflection dari form tiket guest.
+	DeflectionSourceGuestForm = "guest_form"
+)
+
+type KnowledgeBaseService struct {
+	articles   *repository.ArticleRepository
+	categories *repository.CategoryRepository
+	tickets    *repository.TicketRepository
+	ticketing  *TicketService
+	audit      *AuditService
+	now        func() time.Time
+}
+
+type ArticleRequest struct {
+	Title      string `json:"title"`
+	Slug       string `json:"slug"`
+	Summary    string `json:"summary"`
+	Body       string `json:"body"`
+	CategoryID string `json:"categoryId"`
+}
+
+// TicketResolutionRequest adalah komentar penyelesaian beserta artikel KB yang dipakai.
+type TicketResolutionRequest struct {
+	Message    string   `json:"message"`
+	ArticleIDs []string `json:"articleIds"`
+}
+
+type PromoteResolutionRequest struct {
+	// CommentID memilih komentar penyelesaian; kosong berarti komentar staf terakhir.
+	CommentID string `json:"commentId"`
+	Title     string `json:"title"`
+}
+
+type ArticleDeflectionRequest struct {
+	ArticleID  string `json:"articleId"`
+	CategoryID string `json:"categoryId"`
+	Query      string `json:"query"`
+	Source     string `json:"source"`
+}
+
+func NewKnowledgeBaseService(
+	articles *repository.ArticleRepository,
+	categories *repository.CategoryRepository,
+	tickets *repository.TicketRepository,
+	ticketing *TicketService,
+	audit *AuditService,
+) *KnowledgeBaseService {
+	return &KnowledgeBaseService{
+		articles:   articles,
+		categories: categories,
+		tickets:    tickets,
+		ticketing:  ticketing,
+		audit:      audit,
+		now:        time.Now,
+	}
+}
+
+// ListPublished dipakai halaman publik; filter kategori ikut mencakup sub-kategori.
+func (service *KnowledgeBaseService) ListPublished(query string, categoryID string, page int, limit int) (domain.ArticlePageDTO, error) {
+	return service.list(repository.ArticleListFilter{
+		Query:      strings.TrimSpace(query),
+		CategoryID: service.categoryScope(categoryID, false),
+		Status:     domain.ArticlePublished,
+	}, page, limit)
+}
+
+func (service *KnowledgeBaseService) ListAdmin(
+	query string,
+	categoryID string,
+	status domain.ArticleStatus,
+	page int,
+	limit int,
+) (domain.ArticlePageDTO, error) {
+	return service.list(repository.ArticleListFilter{
+		Query:      strings.TrimSpace(query),
+		CategoryID: service.categoryScope(categoryID, false),
+		Status:     status,
+	}, page, limit)
+}
+
+func (service *KnowledgeBaseService) list(filter repository.ArticleListFilter, page int, limit int) (domain.ArticlePageDTO, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 50 {
+		limit = 50
+	}
+	articles, total, err := service.articles.List(filter, page, limit)
+	if err != nil {
+		return domain.ArticlePageDTO{}, err
+	}
+	names := service.categoryNames()
+	items := make([]domain.ArticleDTO, 0, len(articles))
+	for _, article := range articles {
+		items = append(items, toArticleDTO(article, names[article.CategoryID], false))
+	}
+	return domain.ArticlePageDTO{
+		Items:      items,
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: util.CalcTotalPages(total, limit),
+	}, nil
+}
+
+// Suggest mengembalikan artikel published yang relevan untuk form tiket guest sebelum
+// tiket dikirim. Artikel dari kategori parent dan sub-kategori ikut dipertimbangkan.
+func (service *KnowledgeBaseService) Suggest(query string, categoryID string) ([]domain.ArticleDTO, error) {
+	query = strings.TrimSpace(query)
+	if query == "" && categoryID == "" {
+		return []domain.ArticleDTO{}, nil
+	}
+	filter := repository.ArticleListFilter{
+		Query:      query,
+		CategoryID: service.categoryScope(categoryID, true),
+		Status:     domain.ArticlePublished,
+	}
+	articles, _, err := service.articles.List(filter, 1, maxArticleSuggest)
+	if err != nil {
+		return nil, err
+	}
+	names := service.categoryNames()
+	items := make([]domain.ArticleDTO, 0, len(articles))
+	for _, article := range articles {
+		items = append(items, toArticleDTO(article, names[article.CategoryID], false))
+	}
+	return items, nil
+}
+
+// GetPublished menampilkan artikel publik dan menambah jumlah dilihat.
+func (service *KnowledgeBaseService) GetPublished(idOrSlug string) (domain.ArticleDTO, error) {
+	article, err := service.articles.FindByIDOrSlug(idOrSlug)
+	if err != nil || article.Status != domain.ArticlePublished {
+		return domain.ArticleDTO{}, errors.New("artikel tidak ditemukan")
+	}
+	if err := service.articles.IncrementViews(article.ID); err == nil {
+		article.ViewCount++
+	}
+	return toArticleDTO(*article, service.categoryNames()[article.CategoryID], true), nil
+}
+
+func (service *KnowledgeBaseService) GetAdmin(idOrSlug string) (domain.ArticleDTO, error) {
+	article, err := service.articles.FindByIDOrSlug(idOrSlug)
+	if err != nil {
+		return domain.ArticleDTO{}, errors.New("artikel tidak ditemukan")
+	}
+	return toArticleDTO(*article, service.categoryNames()[article.CategoryID], true), nil
+}
+
+func (service *KnowledgeBaseService) Create(ctx context.Context, user domain.User, req ArticleRequest) (domain.ArticleDTO, error) {
+	article := domain.Article{
+		ID:         util.NewUUID(),
+		Status:     domain.ArticleDraft,
+		AuthorID:   user.ID,
+		AuthorName: user.Name,
+	}
+	if err := service.applyArticleRequest(&article, req); err != nil {
+		return domain.ArticleDTO{}, err
+	}
+	if err := service.articles.Create(&article); err != nil {
+		return domain.ArticleDTO{}, err
+	}
+	service.audit.Record(ctx, AuditEntry{
+		Actor:      &user,
+		Action:     AuditActionArticleCreate,
+		EntityType: AuditEntityArticle,
+		EntityID:   article.ID,
+		After:      articleAuditSnapshot(article),
+	})
+	return toArticleDTO(article, service.categoryNames()[article.CategoryID], true), nil
+}
+
+func (service *KnowledgeBaseService) Update(
+	ctx context.Context,
+	user domain.User,
+	articleID string,
+	req ArticleRequest,
+) (domain.ArticleDTO, error) {
+	article, err := service.articles.FindByIDOrSlug(articleID)
+	if err != nil {
+		return domain.ArticleDTO{}, errors.New("artikel tidak ditemukan")
+	}
+	before := articleAuditSnapshot(*article)
+	if err := service.applyArticleRequest(article, req); err != nil {
+		return domain.ArticleDTO{}, err
+	}
+	if err := service.articles.Update(article); err != nil {
+		return domain.ArticleDTO{}, err
+	}
+	service.audit.Record(ctx, AuditEntry{
+		Actor:      &user,
+		Action:     AuditActionArticleUpdate,
+		EntityType: AuditEntityArticle,
+		EntityID:   article.ID,
+		Before:     before,
+		After:      articleAuditSnapshot(*article),
+	})
+	return toArticleDTO(*article, service.categoryNames()[article.CategoryID], true), nil
+}
+
+func (service *KnowledgeBaseService) SetPublished(
+	ctx context.Context,
+	user domain.User,
+	articleID string,
+	published bool,
+) (domain.ArticleDTO, error) {
+	article, err := service.articles.FindByIDOrSlug(articleID)
+	if err != nil {
+		return domain.ArticleDTO{}, errors.New("artikel tidak ditemukan")
+	}
+	before := articleAuditSnapshot(*article)
+	action := AuditActionArticleUnpublish
+	if published {
+		action = AuditActionArticlePublish
+		article.Status = domain.ArticlePublished
+		if article.PublishedAt == nil {
+			now := service.now()
+			article.PublishedAt = &now
+		}
+	} else {
+		article.Status = domain.ArticleDraft
+	}
+	if err := service.articles.Update(article); err != nil {
+		return domain.ArticleDTO{}, err
+	}
+	service.audit.Record(ctx, AuditEntry{
+		Actor:      &user,
+		Action:     action,
+		EntityType: AuditEntityArticle,
+		EntityID:   article.ID,
+		Before:     before,
+		After:      articleAuditSnapshot(*article),
+	})
+	return toArticleDTO(*article, service.categoryNames()[article.CategoryID], true), nil
+}
+
+func (service *KnowledgeBaseService) Delete(ctx context.Context, user domain.User, articleID string) error {
+	article, err := service.articles.FindByIDOrSlug(articleID)
+	if err != nil {
+		return errors.New("artikel tidak ditemukan")
+	}
+	if err := service.articles.Delete(article.ID); err != nil {
+		return err
+	}
+	service.audit.Record(ctx, AuditEntry{
+		Actor:      &user,
+		Action:     AuditActionArticleDelete,
+		EntityType: AuditEntityArticle,
+		EntityID:   article.ID,
+		Before:     articleAuditSnapshot(*article),
+	})
+	return nil
+}
+
+// Vote mencatat "apakah artikel ini membantu". Satu pengunjung hanya memiliki satu suara
+// per artikel; suara berikutnya mengganti suara sebelumnya.
+func (service *KnowledgeBaseService) Vote(idOrSlug string, voterKey string, helpful bool) (domain.ArticleDTO, error) {
+	article, err := service.articles.FindByIDOrSlug(idOrSlug)
+	if err != nil || article.Status != domain.ArticlePublished {
+		return domain.ArticleDTO{}, errors.New("artikel tidak ditemukan")
+	}
+	if err := service.articles.Vote(article.ID, voterKey, helpful); err != nil {
+		return domain.ArticleDTO{}, err
+	}
+	updated, err := service.articles.FindByIDOrSlug(article.ID)
+	if err != nil {
+		return domain.ArticleDTO{}, err
+	}
+	return toArticleDTO(*updated, service.categoryNames()[updated.CategoryID], false), nil
+}
+
+// RecordDeflection dipanggil saat pengunjung menyatakan artikel yang disarankan
+// menyelesaikan masalahnya dan batal membuat tiket.
+func (service *KnowledgeBaseService) RecordDeflection(req ArticleDeflectionRequest) error {
+	article, err := service.articles.FindByIDOrSlug(strings.TrimSpace(req.ArticleID))
+	if err != nil || article.Status != domain.ArticlePublished {
+		return errors.New("artikel tidak ditemukan")
+	}
+	categoryID := strings.TrimSpace(req.CategoryID)
+	if categoryID == "" {
+		categoryID = article.CategoryID
+	} else if _, err := service.categories.FindByID(categoryID); err != nil {
+		return errors.New("kategori tidak ditemukan")
+	}
+	source := strings.TrimSpace(req.Source)
+	if source == "" {
+		source = DeflectionSourceGuestForm
+	}
+	query := truncateString(strings.TrimSpace(req.Query), 200)
+	return service.articles.CreateDeflection(&domain.ArticleDeflection{
+		ID:         util.NewUUID(),
+		ArticleID:  article.ID,
+		CategoryID: categoryID,
+		Query:      query,
+		Source:     truncateString(source, 40),
+		CreatedAt:  service.now(),
+	})
+}
+
+// ResolveTicket menandai tiket selesai lalu menambahkan komentar penyelesaian dengan
+// artikel KB terlampir. Status diubah lebih dulu agar komentar dan tautan artikel
+// tidak tersimpan jika perubahan status ditolak.
+func (service *KnowledgeBaseService) ResolveTicket(
+	ctx context.Context,
+	user domain.User,
+	ticketID string,
+	req TicketResolutionRequest,
+) (domain.TicketDTO, error) {
+	articleIDs, err := service.resolveLinkedArticles(req.ArticleIDs)
+	if err != nil {
+		return domain.TicketDTO{}, err
+	}
+	if strings.TrimSpace(req.Message) == "" {
+		return domain.TicketDTO{}, errors.New("komentar tidak boleh kosong")
+	}
+	ticket, err := service.tickets.FindByID(ticketID)
+	if err != nil {
+		return domain.TicketDTO{}, errors.New("tiket tidak ditemukan")
+	}
+	if ticket.Status != domain.StatusResolved {
+		status := domain.StatusResolved
+		if _, err := service.ticketing.UpdateTicket(ctx, user, ticket.ID, TicketUpdateRequest{Status: &status}); err != nil {
+			return domain.TicketDTO{}, err
+		}
+	}
+
+	_, comment, err := service.ticketing.addComment(ctx, user, ticket.ID, req.Message)
+	if err != nil {
+		return domain.TicketDTO{}, err
+	}
+	if err := service.articles.LinkToComment(comment.ID, articleIDs, user.ID); err != nil {
+		return domain.TicketDTO{}, err
+	}
+	if len(articleIDs) > 0 {
+		service.audit.Record(ctx, AuditEntry{
+			Actor:      &user,
+			Action:     AuditActionTicketLinkArticles,
+			EntityType: AuditEntityTicket,
+			EntityID:   ticket.ID,
+			After:      map[string]any{"commentId": comment.ID, "articleIds": articleIDs},
+		})
+	}
+	return service.ticketing.GetTicket(&user, ticket.ID)
+}
+
+// PromoteResolution membuat draft artikel dari masalah dan penyelesaian tiket.
+// Satu tiket hanya dapat dijadikan satu artikel.
+func (service *KnowledgeBaseService) PromoteResolution(
+	ctx context.Context,
+	user domain.User,
+	ticketID string,
+	req PromoteResolutionRequest,
+) (domain.ArticleDTO, error) {
+	ticket, err := service.tickets.FindByID(ticketID)
+	if err != nil {
+		return domain.ArticleDTO{}, errors.New("tiket tidak ditemukan")
+	}
+	if existing, err := service.articles.FindBySourceTicket(ticket.ID); err == nil {
+		return domain.ArticleDTO{}, fmt.Errorf("tiket sudah dijadikan artikel %s", existing.Slug)
+	}
+	resolution := resolutionComment(ticket.Comments, strings.TrimSpace(req.CommentID))
+	if resolution == nil {
+		return domain.ArticleDTO{}, errors.New("tiket belum memiliki komentar penyelesaian")
+	}
+
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		title = ticket.Title
+	}
+	body := fmt.Sprintf("## Masalah\n\n%s\n\n## Penyelesaian\n\n%s", strings.TrimSpace(ticket.Description), resolution.Message)
+	if len(resolution.Articles) > 0 {
+		lines := make([]string, 0, len(resolution.Articles))
+		for _, article := range resolution.Articles {
+			lines = append(lines, fmt.Sprintf("- %s (/kb/articles/%s)", article.Title, article.Slug))
+		}
+		body += "\n\n## Artikel Terkait\n\n" + strings.Join(lines, "\n")
+	}
+
+	article := domain.Article{
+		ID:             util.NewUUID(),
+		Status:         domain.ArticleDraft,
+		AuthorID:       user.ID,
+		AuthorName:     user.Name,
+		SourceTicketID: ticket.ID,
+	}
+	if err := service.applyArticleRequest(&article, ArticleRequest{
+		Title:      title,
+		Summary:    truncateString(strings.TrimSpace(ticket.Description), 200),
+		Body:       body,
+		CategoryID: ticket.CategoryID,
+	}); err != nil {
+		return domain.ArticleDTO{}, err
+	}
+	if err := service.articles.Create(&article); err != nil {
+		// Promosi bersamaan untuk tiket yang sama lolos pengecekan di atas dan
+		// baru ditolak oleh unique index.
+		if isDuplicateSourceTicketError(err) {
+			if existing, findErr := service.articles.FindBySourceTicket(ticket.ID); findErr == nil {
+				return domain.ArticleDTO{}, fmt.Errorf("tiket sudah dijadikan artikel %s", existing.Slug)
+			}
+		}
+		return domain.ArticleDTO{}, err
+	}
+	service.audit.Record(ctx, AuditEntry{
+		Actor:      &user,
+		Action:     AuditActionArticleCreate,
+		EntityType: AuditEntityArticle,
+		EntityID:   article.ID,
+		After:      articleAuditSnapshot(article),
+	})
+	return toArticleDTO(article, service.categoryNames()[article.CategoryID], true), nil
+}
+
+func isDuplicateSourceTicketError(err error) bool {
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "duplicate key value") &&
+		strings.Contains(message, "idx_articles_source_ticket")
+}
+
+// resolveLinkedArticles memastikan artikel yang dilampirkan ada dan sudah published,
+// menerima ID maupun slug, dan membuang duplikat.
+func (service *KnowledgeBaseService) resolveLinkedArticles(values []string) ([]string, error) {
+	ids := make([]string, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		article, err := service.articles.FindByIDOrSlug(value)
+		if err != nil || article.Status != domain.ArticlePublished {
+			return nil, fmt.Errorf("artikel %s tidak ditemukan", value)
+		}
+		if !slices.Contains(ids, article.ID) {
+			ids = append(ids, article.ID)
+		}
+	}
+	return ids, nil
+}
+
+func resolutionComment(comments []domain.TicketComment, commentID string) *domain.TicketComment {
+	for index := len(comments) - 1; index >= 0; index-- {
+		comment := comments[index]
+		if commentID != "" {
+			if comment.ID == commentID {
+				return &comments[index]
+			}
+			continue
+		}
+		if comment.IsStaff {
+			return &comments[index]
+		}
+	}
+	return nil
+}
+
+func (service *KnowledgeBaseService) applyArticleRequest(article *domain.Article, req ArticleRequest) error {
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return errors.New("judul artikel wajib diisi")
+	}
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		return errors.New("isi artikel wajib diisi")
+	}
+	categoryID := strings.TrimSpace(req.CategoryID)
+	if categoryID != "" {
+		if _, err := service.categories.FindByID(categoryID); err != nil {
+			return errors.New("kategori tidak ditemukan")
+		}
+	}
+
+	slug := slugify(req.Slug, maxArticleSlugLength)
+	if slug == "" {
+		slug = article.Slug
+	}
+	if slug == "" {
+		slug = slugify(title, maxArticleSlugLength)
+	}
+	if slug == "" {
+		return errors.New("slug artikel tidak valid")
+	}
+	unique, err := service.uniqueSlug(slug, article.ID)
+	if err != nil {
+		return err
+	}
+
+	article.Title = title
+	article.Slug = unique
+	article.Summary = strings.TrimSpace(req.Summary)
+	article.Body = body
+	article.CategoryID = categoryID
+	return nil
+}
+
+func (service *KnowledgeBaseService) uniqueSlug(base string, articleID string) (string, error) {
+	candidate := base
+	for attempt := 2; attempt < 100; attempt++ {
+		exists, err := service.articles.SlugExists(candidate, articleID)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s-%d", base, attempt)
+	}
+	return "", errors.New("gagal membuat slug artikel unik")
+}
+
+// categoryScope mengembalikan kategori beserta sub-kategorinya; withAncestors juga
+// menyertakan parent agar artikel umum di level layanan ikut disarankan.
+func (service *KnowledgeBaseService) categoryScope(categoryID string, withAncestors bool) []string {
+	categoryID = strings.TrimSpace(categoryID)
+	if categoryID == "" {
+		return nil
+	}
+	items, err := service.categories.List()
+	if err != nil {
+		return []string{categoryID}
+	}
+	tree := newCategoryTree(items)
+	scope := tree.descendants(categoryID)
+	if withAncestors {
+		scope = append(scope, tree.ancestors(categoryID)[1:]...)
+	}
+	return scope
+}
+
+func (service *KnowledgeBaseService) categoryNames() map[string]string {
+	names := make(map[string]string)
+	items, err := service.categories.List()
+	if err != nil {
+		return names
+	}
+	for _, item := range items {
+		names[item.ID] = item.Name
+	}
+	return names
+}
+
+// ArticleVoterKey menurunkan kunci pemilih dari user login atau, untuk pengunjung
+// anonim, hash IP dan user agent sehingga data mentahnya tidak disimpan.
+func ArticleVoterKey(user *domain.User, ip string, userAgent string) string {
+	if user != nil && user.ID != "" {
+		return "user:" + user.ID
+	}
+	sum := sha256.Sum256([]byte(ip + "|" + userAgent))
+	return "anon:" + hex.EncodeToString(sum[:])[:40]
+}
+
+// truncateString memotong value menjadi paling banyak max karakter. Pemotongan per
+// rune agar karakter multi-byte tidak terbelah; batas varchar Postgres juga per karakter.
+func truncateString(value string, max int) string {
+	if utf8.RuneCountInString(value) <= max {
+		return value
+	}
+	return string([]rune(value)[:max])
+}
+
+func articleAuditSnapshot(article domain.Article) map[string]any {
+	return map[string]any{
+		"title":      article.Title,
+		"slug":       article.Slug,
+		"categoryId": article.CategoryID,
+		"status":     article.Status,
+	}
+}
+
+func toArticleDTO(article domain.Article, categoryName string, withBody bool) domain.ArticleDTO {
+	dto := domain.ArticleDTO{
+		ID:              article.ID,
+		Slug:            article.Slug,
+		Title:           article.Title,
+		Summary:         article.Summary,
+		CategoryID:      article.CategoryID,
+		Category:        categoryName,
+		Status:          article.Status,
+		Author:          article.AuthorName,
+		ViewCount:       article.ViewCount,
+		HelpfulCount:    article.HelpfulCount,
+		NotHelpfulCount: article.NotHelpfulCount,
+		PublishedAt:     article.PublishedAt,
+		SourceTicketID:  article.SourceTicketID,
+		UpdatedAt:       article.UpdatedAt,
+	}
+	if withBody {
+		dto.Body = article.Body
+	}
+	return dto
+}
@@ -1,17 +1,25 @@
 package service
 
 import (
+	"context"
+	"log"
 	"strings"
 	"time"
 
 	"unila_helpdesk_backend/internal/domain"
+	"unila_helpdesk_backend/internal/fcm"
 	"unila_helpdesk_backend/internal/repository"
 	"unila_helpdesk_backend/internal/util"
 )
 
+// topicBackfillBatchSize mengikuti batas token per panggilan subscribe FCM.
+const topicBackfillBatchSize = 1000
+
 type NotificationService struct {
 	notifications *repository.NotificationRepository
 	tokens        *repository.FCMTokenRepository
+	sessions      *repository.SessionRepository
+	fcmClient     *fcm.Client
 	now           func() time.Time
 }
 
@@ -24,10 +32,17 @@ type FCMUnregisterRequest struct {
 	Token string `json:"token"`
 }
 
-func NewNotificationService(notifications *repository.NotificationRepository, tokens *repository.FCMTokenRepository) *NotificationService {
+func NewNotificationService(
+	notifications *repository.NotificationRepository,
+	tokens *repository.FCMTokenRepository,
+	sessions *repository.SessionRepository,
+	fcmClient *fcm.Client,
+) *NotificationService {
 	return &NotificationService{
 		notifications: notifications,
 		tokens:        tokens,
+		sessions:      sessions,
+		fcmClient:     fcmClient,
 		now:           time.Now,
 	}
 }
@@ -51,7 +66,9 @@ func (service *NotificationService) List(user domain.User) ([]domain.Notificatio
 	return result, nil
 }
 
-func (service *NotificationService) RegisterToken(user domain.User, req FCMRegisterRequest) error {
+// RegisterToken menyimpan push token dan mengaitkannya dengan sesi login agar
+// ikut dicabut saat sesi logout.
+func (service *NotificationService) RegisterToken(user domain.User, sessionID string, req FCMRegisterRequest) error {
 	if user.Role == domain.RoleGuest {
 		return nil
 	}
@@ -67,7 +84,70 @@ func (service *NotificationService) RegisterToken(user domain.User, req FCMRegis
 		CreatedAt: service.now(),
 		UpdatedAt: service.now(),
 	}
-	return service.tokens.Upsert(&token)
+	if err := service.tokens.Upsert(&token); err != nil {
+		return err
+	}
+	if sessionID != "" {
+		if err := service.sessions.AttachFCMToken(sessionID, tokenValue, service.now()); err != nil {
+			log.Printf("failed to attach fcm token to session %s: %v", sessionID, err)
+		}
+	}
+	// Token ikut topic pengumuman agar broadcast tidak perlu mengirim per token.
+	if _, err := service.subscribeAnnouncements(context.Background(), []string{tokenValue}); err != nil {
+		log.Printf("failed to subscribe fcm token to %s: %v", fcm.TopicAnnouncements, err)
+	}
+	return nil
+}
+
+// SubscribePendingTokens mendaftarkan token yang belum mengikuti topic pengumuman
+// (mis. terdaftar sebelum topic dipakai atau gagal saat register) per 1000 token.
+// Dipanggil sekali saat API start.
+func (service *NotificationService) SubscribePendingTokens(ctx context.Context) (int, error) {
+	if !service.fcmClient.Enabled() {
+		return 0, nil
+	}
+	subscribed := 0
+	afterID := ""
+	for {
+		tokens, err := service.tokens.ListTopicPending(afterID, topicBackfillBatchSize)
+		if err != nil {
+			return subscribed, err
+		}
+		if len(tokens) == 0 {
+			return subscribed, nil
+		}
+		values := make([]string, 0, len(tokens))
+		for _, token := range tokens {
+			values = append(values, token.Token)
+		}
+		count, err := service.subscribeAnnouncements(ctx, values)
+		if err != nil {
+			log.Printf("failed to subscribe fcm token batch to %s: %v", fcm.TopicAnnouncements, err)
+		}
+		subscribed += count
+		afterID = tokens[len(tokens)-1].ID
+	}
+}
+
+// subscribeAnnouncements mendaftarkan token ke topic pengumuman, menandai token yang
+// berhasil, dan menghapus token yang tidak valid.
+func (service *NotificationService) subscribeAnnouncements(ctx context.Context, tokens []string) (int, error) {
+	report, err := service.fcmClient.SubscribeToTopic(ctx, tokens, fcm.TopicAnnouncements)
+	succeeded := make([]string, 0, len(report.Results))
+	for _, result := range report.Results {
+		if result.Status == fcm.StatusSent {
+			succeeded = append(succeeded, result.Token)
+		}
+	}
+	if markErr := service.tokens.MarkTopicSubscribed(succeeded, service.now()); markErr != nil {
+		return 0, markErr
+	}
+	if invalid := report.InvalidTokens(); len(invalid) > 0 {
+		if deleteErr := service.tokens.DeleteByTokens(invalid); deleteErr != nil {
+			log.Printf("failed to delete invalid fcm tokens count=%d: %v", len(invalid), deleteErr)
+		}
+	}
+	return len(succeeded), err
 }
 
 func (service *NotificationService) UnregisterToken(user domain.User, req FCMUnregisterRequest) error {
@@ -75,5 +155,28 @@ func (service *NotificationService) UnregisterToken(user domain.User, req FCMUnr
 	if tokenValue == "" {
 		return nil
 	}
-	return service.tokens.DeleteByUserAndToken(user.ID, tokenValue)
+	if err := service.tokens.DeleteByUserAndToken(user.ID, tokenValue); err != nil {
+		return err
+	}
+	if err := service.sessions.DetachFCMToken(user.ID, tokenValue, service.now()); err != nil {
+		log.Printf("failed to detach fcm token from session: %v", err)
+	}
+	if _, err := service.fcmClient.UnsubscribeFromTopic(context.Background(), []string{tokenValue}, fcm.TopicAnnouncements); err != nil {
+		log.Printf("failed to unsubscribe fcm token from %s: %v", fcm.TopicAnnouncements, err)
+	}
+	return nil
+}
+
+// UnregisterAll menghapus seluruh push token user, mis. saat logout paksa.
+func (service *NotificationService) UnregisterAll(user domain.User) error {
+	tokens, err := service.tokens.ListTokens(user.ID)
+	if err != nil {
+		return err
+	}
+	for _, token := range tokens {
+		if err := service.UnregisterToken(user, FCMUnregisterRequest{Token: token.Token}); err != nil {
+			return err
+		}
+	}
+	return nil
 }
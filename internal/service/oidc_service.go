@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"unila_helpdesk_backend/internal/config"
+	"unila_helpdesk_backend/internal/domain"
+	"unila_helpdesk_backend/internal/oidc"
+	"unila_helpdesk_backend/internal/repository"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	authProviderOIDC = "oidc"
+	oidcStateTTL     = 10 * time.Minute
+)
+
+// OIDCService menangani login SSO kampus dengan authorization code + PKCE.
+// Redirect URI mengarah ke frontend, yang meneruskan code dan state ke callback API.
+type OIDCService struct {
+	provider    *oidc.Provider
+	states      *repository.OIDCStateRepository
+	auth        *AuthService
+	groupsClaim string
+	adminGroups []string
+	entityClaim string
+	entityMap   map[string]string
+	now         func() time.Time
+}
+
+type OIDCAuthorizeResult struct {
+	AuthorizationURL string    `json:"authorizationUrl"`
+	State            string    `json:"state"`
+	ExpiresAt        time.Time `json:"expiresAt"`
+}
+
+type OIDCCallbackRequest struct {
+	Code  string `json:"code"`
+	State string `json:"state"`
+}
+
+// NewOIDCService mengembalikan service nonaktif jika OIDC_ISSUER atau
+// OIDC_CLIENT_ID kosong.
+func NewOIDCService(
+	cfg config.Config,
+	states *repository.OIDCStateRepository,
+	auth *AuthService,
+) *OIDCService {
+	service := &OIDCService{
+		states:      states,
+		auth:        auth,
+		groupsClaim: cfg.OIDCGroupsClaim,
+		adminGroups: splitList(cfg.OIDCAdminGroups),
+		entityClaim: cfg.OIDCEntityClaim,
+		entityMap:   parseEntityMap(cfg.OIDCEntityMap),
+		now:         time.Now,
+	}
+	if cfg.OIDCIssuer != "" && cfg.OIDCClientID != "" {
+		service.provider = oidc.NewProvider(oidc.Config{
+			Issuer:       cfg.OIDCIssuer,
+			ClientID:     cfg.OIDCClientID,
+			ClientSecret: cfg.OIDCClientSecret,
+			RedirectURL:  cfg.OIDCRedirectURL,
+			Scopes:       strings.Fields(cfg.OIDCScopes),
+		})
+	}
+	return service
+}
+
+func (service *OIDCService) Enabled() bool {
+	return service.provider != nil
+}
+
+// Authorize menyiapkan state, nonce, dan PKCE verifier lalu mengembalikan URL
+// login provider. Verifier tidak pernah dikirim ke browser.
+func (service *OIDCService) Authorize(ctx context.Context, client ClientInfo) (OIDCAuthorizeResult, error) {
+	if !service.Enabled() {
+		return OIDCAuthorizeResult{}, errors.New("login SSO belum dikonfigurasi")
+	}
+	state, err := randomToken()
+	if err != nil {
+		return OIDCAuthorizeResult{}, err
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		return OIDCAuthorizeResult{}, err
+	}
+	verifier := oauth2.GenerateVerifier()
+	authorizationURL, err := service.provider.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		return OIDCAuthorizeResult{}, err
+	}
+
+	now := service.now()
+	if err := service.states.DeleteExpired(now); err != nil {
+		log.Printf("failed to delete expired oidc states: %v", err)
+	}
+	loginState := domain.OIDCLoginState{
+		State:        state,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ClientType:   truncateString(strings.ToLower(strings.TrimSpace(client.Type)), 20),
+		DeviceName:   truncateString(strings.TrimSpace(client.DeviceName), 120),
+		Platform:     truncateString(strings.TrimSpace(client.Platform), 40),
+		ExpiresAt:    now.Add(oidcStateTTL),
+		CreatedAt:    now,
+	}
+	if err := service.states.Create(&loginState); err != nil {
+		return OIDCAuthorizeResult{}, err
+	}
+	return OIDCAuthorizeResult{
+		AuthorizationURL: authorizationURL,
+		State:            state,
+		ExpiresAt:        loginState.ExpiresAt,
+	}, nil
+}
+
+// Callback menukar code, memverifikasi ID token, menyinkronkan user, lalu
+// menerbitkan token helpdesk seperti login password.
+func (service *OIDCService) Callback(ctx context.Context, req OIDCCallbackRequest, client ClientInfo) (AuthResult, error) {
+	if !service.Enabled() {
+		return AuthResult{}, errors.New("login SSO belum dikonfigurasi")
+	}
+	code := strings.TrimSpace(req.Code)
+	if code == "" || strings.TrimSpace(req.State) == "" {
+		return AuthResult{}, errors.New("code dan state wajib diisi")
+	}
+	loginState, err := service.states.Consume(strings.TrimSpace(req.State))
+	if err != nil || service.now().After(loginState.ExpiresAt) {
+		return AuthResult{}, errors.New("state login tidak valid atau kadaluarsa")
+	}
+
+	claims, err := service.provider.Exchange(ctx, code, loginState.CodeVerifier, loginState.Nonce)
+	if err != nil {
+		service.auth.recordLoginFailure(ctx, nil, "", err.Error())
+		return AuthResult{}, errors.New("login SSO gagal")
+	}
+	identity := service.identityFromClaims(claims)
+	user, action, err := service.auth.syncExternalUser(identity)
+	if err != nil {
+		service.auth.recordLoginFailure(ctx, nil, identity.Email, err.Error())
+		return AuthResult{}, err
+	}
+	service.auth.recordProvisioning(ctx, *user, authProviderOIDC, action)
+
+	// Jenis client mengikuti saat login dimulai agar admin tetap dibatasi ke web.
+	client.Type = loginState.ClientType
+	client.DeviceName = loginState.DeviceName
+	client.Platform = loginState.Platform
+	return service.auth.completeLogin(ctx, user, client, authProviderOIDC)
+}
+
+func (service *OIDCService) identityFromClaims(claims oidc.Claims) externalIdentity {
+	// Email hanya dipakai untuk menautkan akun lokal jika provider menyatakan
+	// email_verified=true; klaim yang tidak ada dianggap belum terverifikasi.
+	verified, _ := claims.Bool("email_verified")
+	name := claims.String("name")
+	if name == "" {
+		name = strings.TrimSpace(claims.String("given_name") + " " + claims.String("family_name"))
+	}
+	return externalIdentity{
+		Provider:      authProviderOIDC,
+		Subject:       claims.String("sub"),
+		Username:      claims.String("preferred_username"),
+		Email:         claims.String("email"),
+		EmailVerified: verified,
+		Name:          name,
+		Entity:        mapEntity(claims.Strings(service.entityClaim), service.entityMap),
+		Role:          mapRole(claims.Strings(service.groupsClaim), service.adminGroups),
+	}
+}
+
+func randomToken() (string, error) {
+	buffer := make([]byte, 32)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buffer), nil
+}
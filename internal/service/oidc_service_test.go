@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"unila_helpdesk_backend/internal/config"
+	"unila_helpdesk_backend/internal/oidc/oidctest"
+	"unila_helpdesk_backend/internal/repository"
+)
+
+// newOIDCTestService menghubungkan OIDCService ke provider oidctest dan SQLite
+// in-memory sebagai pengganti Postgres.
+func newOIDCTestService(t *testing.T) (*OIDCService, *repository.UserRepository) {
+	t.Helper()
+	handler, err := oidctest.NewHandler(oidctest.Config{ClientID: "helpdesk"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider := httptest.NewServer(handler)
+	t.Cleanup(provider.Close)
+
+	db := newTestDB(t)
+
+	cfg := config.Config{
+		JWTSecret:        "test-secret",
+		JWTExpiry:        time.Minute,
+		JWTRefreshExpiry: time.Hour,
+		OIDCIssuer:       provider.URL,
+		OIDCClientID:     "helpdesk",
+		OIDCRedirectURL:  "http://frontend.test/sso/callback",
+		OIDCScopes:       "openid email profile",
+	}
+	users := repository.NewUserRepository(db)
+	auth := NewAuthService(
+		cfg,
+		users,
+		repository.NewRefreshTokenRepository(db),
+		repository.NewSessionRepository(db),
+		nil,
+		nil,
+	)
+	return NewOIDCService(cfg, repository.NewOIDCStateRepository(db), auth), users
+}
+
+// followAuthorize membuka authorizationURL seperti browser yang mengisi form login
+// mock lalu mengembalikan code dan state dari redirect ke frontend. rewrite dapat
+// mengubah query sebelum request dikirim.
+func followAuthorize(t *testing.T, authorizationURL string, rewrite func(url.Values)) OIDCCallbackRequest {
+	t.Helper()
+	target, err := url.Parse(authorizationURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := target.Query()
+	query.Set("login_hint", "budi@unila.local")
+	query.Set("name", "Budi Santoso")
+	if rewrite != nil {
+		rewrite(query)
+	}
+	target.RawQuery = query.Encode()
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	response, err := client.Get(target.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusFound {
+		t.Fatalf("authorize status = %d", response.StatusCode)
+	}
+	location, err := url.Parse(response.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(location.String(), "http://frontend.test/sso/callback?") {
+		t.Fatalf("redirect ke %s", location)
+	}
+	return OIDCCallbackRequest{Code: location.Query().Get("code"), State: location.Query().Get("state")}
+}
+
+func TestOIDCAuthorizeCallback(t *testing.T) {
+	service, _ := newOIDCTestService(t)
+	ctx := context.Background()
+	client := ClientInfo{Type: "mobile", DeviceName: "Pixel"}
+
+	authorized, err := service.Authorize(ctx, client)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	callback := followAuthorize(t, authorized.AuthorizationURL, nil)
+	if callback.State != authorized.State {
+		t.Fatalf("state = %q, want %q", callback.State, authorized.State)
+	}
+
+	result, err := service.Callback(ctx, callback, client)
+	if err != nil {
+		t.Fatalf("Callback: %v", err)
+	}
+	if result.Token == "" || result.RefreshToken == "" {
+		t.Fatal("token tidak diterbitkan")
+	}
+	if result.User.Email != "budi@unila.local" || result.User.Name != "Budi Santoso" {
+		t.Fatalf("user = %+v", result.User)
+	}
+
+	// State hanya bisa dipakai sekali, termasuk untuk replay callback yang sama.
+	if _, err := service.Callback(ctx, callback, client); err == nil || !strings.Contains(err.Error(), "state") {
+		t.Fatalf("replay callback err = %v, want state tidak valid", err)
+	}
+}
+
+func TestOIDCCallbackRejectsReusedState(t *testing.T) {
+	service, _ := newOIDCTestService(t)
+	ctx := context.Background()
+
+	first, err := service.Authorize(ctx, ClientInfo{Type: "mobile"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	callback := followAuthorize(t, first.AuthorizationURL, nil)
+	if _, err := service.Callback(ctx, callback, ClientInfo{}); err != nil {
+		t.Fatalf("Callback: %v", err)
+	}
+
+	// Code baru dari login berikutnya tidak boleh dipasangkan dengan state lama.
+	second, err := service.Authorize(ctx, ClientInfo{Type: "mobile"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fresh := followAuthorize(t, second.AuthorizationURL, nil)
+	if _, err := service.Callback(ctx, OIDCCallbackRequest{Code: fresh.Code, State: first.State}, ClientInfo{}); err == nil {
+		t.Fatal("state yang sudah dipakai seharusnya ditolak")
+	}
+}
+
+func TestOIDCCallbackRejectsNonceMismatch(t *testing.T) {
+	service, users := newOIDCTestService(t)
+	ctx := context.Background()
+
+	authorized, err := service.Authorize(ctx, ClientInfo{Type: "mobile"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// PKCE dan state tetap sah sehingga hanya nonce ID token yang berbeda.
+	callback := followAuthorize(t, authorized.AuthorizationURL, func(query url.Values) {
+		query.Set("nonce", "nonce-lain")
+	})
+	if _, err := service.Callback(ctx, callback, ClientInfo{}); err == nil || err.Error() != "login SSO gagal" {
+		t.Fatalf("err = %v, want login SSO gagal", err)
+	}
+	if _, err := users.FindByEmail("budi@unila.local"); err == nil {
+		t.Fatal("user tidak boleh dibuat saat nonce tidak cocok")
+	}
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"unila_helpdesk_backend/internal/domain"
+	"unila_helpdesk_backend/internal/fcm"
+	"unila_helpdesk_backend/internal/repository"
+	"unila_helpdesk_backend/internal/util"
+)
+
+// PushLogService menyimpan hasil pengiriman push dari fcm.Client (sebagai
+// fcm.Recorder) dan menyajikannya untuk admin.
+type PushLogService struct {
+	logs   *repository.PushLogRepository
+	tokens *repository.FCMTokenRepository
+	now    func() time.Time
+}
+
+func NewPushLogService(logs *repository.PushLogRepository, tokens *repository.FCMTokenRepository) *PushLogService {
+	return &PushLogService{logs: logs, tokens: tokens, now: time.Now}
+}
+
+// RecordDeliveries dipanggil fcm.Client setelah setiap pengiriman. Pemilik token
+// dicari sebelum token invalid dihapus pemanggil. Kegagalan hanya dicatat di log.
+func (service *PushLogService) RecordDeliveries(_ context.Context, records []fcm.DeliveryRecord) {
+	tokenValues := make([]string, 0, len(records))
+	for _, record := range records {
+		if record.Token != "" {
+			tokenValues = append(tokenValues, record.Token)
+		}
+	}
+	owners := make(map[string]string, len(tokenValues))
+	if tokens, err := service.tokens.ListByTokens(tokenValues); err == nil {
+		for _, token := range tokens {
+			owners[token.Token] = token.UserID
+		}
+	}
+
+	now := service.now()
+	rows := make([]domain.PushLog, 0, len(records))
+	for _, record := range records {
+		row := domain.PushLog{
+			ID:        util.NewUUID(),
+			Backend:   record.Backend,
+			UserID:    owners[record.Token],
+			Topic:     record.Topic,
+			Title:     truncateString(record.Title, 160),
+			Status:    string(record.Status),
+			MessageID: truncateString(record.MessageID, 200),
+			Error:     record.Error,
+			CreatedAt: now,
+		}
+		if record.Token != "" {
+			row.TokenHint = fcm.TokenHint(record.Token)
+		}
+		rows = append(rows, row)
+	}
+	if err := service.logs.CreateBatch(rows); err != nil {
+		log.Printf("failed to write push logs count=%d: %v", len(rows), err)
+	}
+}
+
+// CleanupExpired menghapus log yang lebih tua dari retention. Retention <= 0 berarti
+// log disimpan tanpa batas.
+func (service *PushLogService) CleanupExpired(retention time.Duration) (int64, error) {
+	if retention <= 0 {
+		return 0, nil
+	}
+	return service.logs.DeleteBefore(service.now().Add(-retention))
+}
+
+// RunCleanup menjalankan CleanupExpired secara berkala sampai ctx dibatalkan.
+func (service *PushLogService) RunCleanup(ctx context.Context, interval time.Duration, retention time.Duration) {
+	if retention <= 0 {
+		return
+	}
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if removed, err := service.CleanupExpired(retention); err != nil {
+			log.Printf("push log cleanup failed: %v", err)
+		} else if removed > 0 {
+			log.Printf("push log cleanup removed push_logs=%d", removed)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (service *PushLogService) List(filter repository.PushLogFilter, page int, limit int) (domain.PushLogPageDTO, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if page < 1 {
+		page = 1
+	}
+	logs, total, err := service.logs.List(filter, page, limit)
+	if err != nil {
+		return domain.PushLogPageDTO{}, err
+	}
+	items := make([]domain.PushLogDTO, 0, len(logs))
+	for _, item := range logs {
+		items = append(items, domain.PushLogDTO{
+			ID:        item.ID,
+			Backend:   item.Backend,
+			UserID:    item.UserID,
+			TokenHint: item.TokenHint,
+			Topic:     item.Topic,
+			Title:     item.Title,
+			Status:    item.Status,
+			MessageID: item.MessageID,
+			Error:     item.Error,
+			CreatedAt: item.CreatedAt,
+		})
+	}
+	return domain.PushLogPageDTO{
+		Items:      items,
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: util.CalcTotalPages(total, limit),
+	}, nil
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"encoding/json"
 	"errors"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -19,6 +20,8 @@ type ReportService struct {
 	reports    *repository.ReportRepository
 	categories *repository.CategoryRepository
 	surveys    *repository.SurveyRepository
+	tags       *repository.TagRepository
+	articles   *repository.ArticleRepository
 	now        func() time.Time
 }
 
@@ -26,11 +29,15 @@ func NewReportService(
 	reports *repository.ReportRepository,
 	categories *repository.CategoryRepository,
 	surveys *repository.SurveyRepository,
+	tags *repository.TagRepository,
+	articles *repository.ArticleRepository,
 ) *ReportService {
 	return &ReportService{
 		reports:    reports,
 		categories: categories,
 		surveys:    surveys,
+		tags:       tags,
+		articles:   articles,
 		now:        time.Now,
 	}
 }
@@ -192,34 +199,80 @@ func calculateCohortScores(responses []domain.SurveyResponse) (float64, float64)
 	return avg, responseRate
 }
 
-func (service *ReportService) ServiceTrends(start time.Time, end time.Time) ([]domain.ServiceTrendDTO, error) {
+// ServiceTrends menghitung persentase tiket per kategori. Tiket di sub-kategori
+// digabung ke anak langsung parentID (kategori root jika parentID kosong).
+func (service *ReportService) ServiceTrends(start time.Time, end time.Time, parentID string) ([]domain.ServiceTrendDTO, error) {
 	rows, err := service.reports.ListTicketTotalsByCategory(start, end)
 	if err != nil {
 		return nil, err
 	}
 
+	tree := service.categoryTree()
+	totals := make(map[string]int64)
+	order := make([]string, 0, len(rows))
 	var overall int64
 	for _, item := range rows {
+		target, ok := tree.rollupTarget(item.CategoryID, parentID)
+		if !ok {
+			continue
+		}
+		if _, seen := totals[target]; !seen {
+			order = append(order, target)
+		}
+		totals[target] += item.Total
 		overall += item.Total
 	}
 	if overall == 0 {
 		return []domain.ServiceTrendDTO{}, nil
 	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return totals[order[i]] > totals[order[j]]
+	})
 
-	categories := service.categoryNameMap()
+	trends := make([]domain.ServiceTrendDTO, 0, len(order))
+	for _, categoryID := range order {
+		trends = append(trends, domain.ServiceTrendDTO{
+			CategoryID: categoryID,
+			Label:      tree.name(categoryID),
+			Percentage: float64(totals[categoryID]) / float64(overall) * 100,
+		})
+	}
 
-	trends := make([]domain.ServiceTrendDTO, 0, len(rows))
-	for _, item := range rows {
-		label := categories[item.CategoryID]
-		if label == "" {
-			label = item.CategoryID
+	return trends, nil
+}
+
+// ServiceTagTrends adalah dimensi tag untuk ServiceTrends. Persentase dihitung terhadap
+// seluruh tiket pada rentang (dan subtree kategori jika diisi), sehingga totalnya bisa
+// melebihi 100% karena satu tiket dapat memiliki beberapa tag.
+func (service *ReportService) ServiceTagTrends(start time.Time, end time.Time, categoryID string) ([]domain.ServiceTrendDTO, error) {
+	categoryIDs := service.categorySubtree(categoryID)
+	rows, err := service.tags.ListTicketTotalsByTag(start, end, categoryIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	categoryRows, err := service.reports.ListTicketTotalsByCategory(start, end)
+	if err != nil {
+		return nil, err
+	}
+	var overall int64
+	for _, item := range categoryRows {
+		if len(categoryIDs) == 0 || slices.Contains(categoryIDs, item.CategoryID) {
+			overall += item.Total
 		}
+	}
+	if overall == 0 {
+		return []domain.ServiceTrendDTO{}, nil
+	}
+
+	trends := make([]domain.ServiceTrendDTO, 0, len(rows))
+	for _, row := range rows {
 		trends = append(trends, domain.ServiceTrendDTO{
-			Label:      label,
-			Percentage: float64(item.Total) / float64(overall) * 100,
+			TagID:      row.TagID,
+			Label:      row.Name,
+			Percentage: float64(row.Total) / float64(overall) * 100,
 		})
 	}
-
 	return trends, nil
 }
 
@@ -262,38 +315,241 @@ func (service *ReportService) DashboardSummary() (domain.DashboardSummaryDTO, er
 	}, nil
 }
 
-func (service *ReportService) ServiceSatisfactionSummary(period string, periods int) ([]domain.ServiceSatisfactionDTO, error) {
+// KnowledgeBaseDeflection membandingkan jumlah deflection (pengunjung batal membuat
+// tiket setelah membaca artikel) dengan tiket yang tetap dibuat per kategori.
+func (service *ReportService) KnowledgeBaseDeflection(period string, periods int) (domain.KBDeflectionReportDTO, error) {
 	start, end := periodRange(period, periods, service.now)
+	report := domain.KBDeflectionReportDTO{
+		Period:      normalizePeriod(period),
+		Start:       start,
+		End:         end,
+		Rows:        []domain.KBDeflectionRowDTO{},
+		TopArticles: []domain.KBArticleStatDTO{},
+	}
 
-	rows, err := service.reports.ListServiceSatisfactionRows(start, end)
+	deflections, err := service.articles.CountDeflectionsByCategory(start, end)
+	if err != nil {
+		return domain.KBDeflectionReportDTO{}, err
+	}
+	tickets, err := service.reports.ListTicketTotalsByCategory(start, end)
+	if err != nil {
+		return domain.KBDeflectionReportDTO{}, err
+	}
+
+	tree := service.categoryTree()
+	rows := make(map[string]*domain.KBDeflectionRowDTO)
+	rowFor := func(categoryID string) *domain.KBDeflectionRowDTO {
+		row, ok := rows[categoryID]
+		if !ok {
+			row = &domain.KBDeflectionRowDTO{CategoryID: categoryID, Category: tree.path(categoryID)}
+			if row.Category == "" {
+				row.Category = categoryID
+			}
+			rows[categoryID] = row
+		}
+		return row
+	}
+	for _, item := range deflections {
+		rowFor(item.CategoryID).Deflections += item.Total
+		report.Deflections += item.Total
+	}
+	for _, item := range tickets {
+		rowFor(item.CategoryID).Tickets += int(item.Total)
+		report.Tickets += int(item.Total)
+	}
+	for _, row := range rows {
+		row.Rate = deflectionRate(row.Deflections, row.Tickets)
+		report.Rows = append(report.Rows, *row)
+	}
+	sort.Slice(report.Rows, func(i, j int) bool {
+		if report.Rows[i].Deflections != report.Rows[j].Deflections {
+			return report.Rows[i].Deflections > report.Rows[j].Deflections
+		}
+		return report.Rows[i].Category < report.Rows[j].Category
+	})
+	report.Rate = deflectionRate(report.Deflections, report.Tickets)
+
+	top, err := service.articles.TopDeflectingArticles(start, end, 10)
+	if err != nil {
+		return domain.KBDeflectionReportDTO{}, err
+	}
+	ids := make([]string, 0, len(top))
+	for _, item := range top {
+		ids = append(ids, item.ArticleID)
+	}
+	articles, err := service.articles.FindByIDs(ids)
+	if err != nil {
+		return domain.KBDeflectionReportDTO{}, err
+	}
+	byID := make(map[string]domain.Article, len(articles))
+	for _, article := range articles {
+		byID[article.ID] = article
+	}
+	for _, item := range top {
+		article, ok := byID[item.ArticleID]
+		if !ok {
+			continue
+		}
+		report.TopArticles = append(report.TopArticles, domain.KBArticleStatDTO{
+			ID:          article.ID,
+			Title:       article.Title,
+			Deflections: item.Total,
+			Views:       article.ViewCount,
+			Helpful:     article.HelpfulCount,
+			NotHelpful:  article.NotHelpfulCount,
+		})
+	}
+	return report, nil
+}
+
+// KnowledgeBaseUsage menampilkan artikel yang paling sering dilampirkan pada
+// penyelesaian tiket per kategori. parentID berperilaku seperti ServiceTrends.
+func (service *ReportService) KnowledgeBaseUsage(
+	period string,
+	periods int,
+	parentID string,
+	limit int,
+) ([]domain.KBCategoryUsageDTO, error) {
+	if limit <= 0 {
+		limit = 5
+	}
+	start, end := periodRange(period, periods, service.now)
+	usage, err := service.articles.CountResolutionUsage(start, end)
 	if err != nil {
 		return nil, err
 	}
+	tickets, err := service.articles.CountTicketsResolvedWithArticles(start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := service.categoryTree()
+	rows := make(map[string]*domain.KBCategoryUsageDTO)
+	uses := make(map[string]map[string]int)
+	for _, item := range tickets {
+		target, ok := tree.rollupTarget(item.CategoryID, parentID)
+		if !ok {
+			continue
+		}
+		row, exists := rows[target]
+		if !exists {
+			row = &domain.KBCategoryUsageDTO{CategoryID: target, Category: tree.path(target)}
+			rows[target] = row
+			uses[target] = make(map[string]int)
+		}
+		row.Tickets += int(item.Total)
+	}
+	articleIDs := make([]string, 0)
+	for _, item := range usage {
+		target, ok := tree.rollupTarget(item.CategoryID, parentID)
+		if !ok || rows[target] == nil {
+			continue
+		}
+		uses[target][item.ArticleID] += item.Total
+		if !slices.Contains(articleIDs, item.ArticleID) {
+			articleIDs = append(articleIDs, item.ArticleID)
+		}
+	}
 
-	categories := service.categoryNameMap()
+	articles, err := service.articles.FindByIDs(articleIDs)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]domain.Article, len(articles))
+	for _, article := range articles {
+		byID[article.ID] = article
+	}
 
+	result := make([]domain.KBCategoryUsageDTO, 0, len(rows))
+	for categoryID, row := range rows {
+		items := make([]domain.KBArticleUsageDTO, 0, len(uses[categoryID]))
+		for articleID, total := range uses[categoryID] {
+			article, ok := byID[articleID]
+			if !ok {
+				continue
+			}
+			items = append(items, domain.KBArticleUsageDTO{
+				ID:    article.ID,
+				Slug:  article.Slug,
+				Title: article.Title,
+				Uses:  total,
+			})
+		}
+		sort.Slice(items, func(i, j int) bool {
+			if items[i].Uses != items[j].Uses {
+				return items[i].Uses > items[j].Uses
+			}
+			return items[i].Title < items[j].Title
+		})
+		if len(items) > limit {
+			items = items[:limit]
+		}
+		row.Articles = items
+		result = append(result, *row)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Tickets != result[j].Tickets {
+			return result[i].Tickets > result[j].Tickets
+		}
+		return result[i].Category < result[j].Category
+	})
+	return result, nil
+}
+
+func deflectionRate(deflections int, tickets int) float64 {
+	if deflections+tickets == 0 {
+		return 0
+	}
+	return float64(deflections) / float64(deflections+tickets)
+}
+
+func (service *ReportService) ServiceSatisfactionSummary(
+	period string,
+	periods int,
+	parentID string,
+) ([]domain.ServiceSatisfactionDTO, error) {
+	start, end := periodRange(period, periods, service.now)
+
+	rows, err := service.reports.ListServiceSatisfactionRows(start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rata-rata sub-kategori digabung ke parent dengan bobot jumlah respon.
+	tree := service.categoryTree()
+	weighted := make(map[string]float64)
+	responses := make(map[string]int)
+	order := make([]string, 0, len(rows))
 	totalWeighted := 0.0
 	for _, row := range rows {
-		row.AvgScore = normalizeLegacyScore(row.AvgScore)
-		totalWeighted += row.AvgScore * float64(row.Responses)
+		target, ok := tree.rollupTarget(row.CategoryID, parentID)
+		if !ok {
+			continue
+		}
+		if _, seen := responses[target]; !seen {
+			order = append(order, target)
+		}
+		score := normalizeLegacyScore(row.AvgScore) * float64(row.Responses)
+		weighted[target] += score
+		responses[target] += row.Responses
+		totalWeighted += score
 	}
 
-	result := make([]domain.ServiceSatisfactionDTO, 0, len(rows))
-	for _, row := range rows {
-		row.AvgScore = normalizeLegacyScore(row.AvgScore)
-		label := categories[row.CategoryID]
-		if label == "" {
-			label = row.CategoryID
+	result := make([]domain.ServiceSatisfactionDTO, 0, len(order))
+	for _, categoryID := range order {
+		avgScore := 0.0
+		if responses[categoryID] > 0 {
+			avgScore = weighted[categoryID] / float64(responses[categoryID])
 		}
 		percentage := 0.0
 		if totalWeighted > 0 {
-			percentage = (row.AvgScore * float64(row.Responses)) / totalWeighted * 100
+			percentage = weighted[categoryID] / totalWeighted * 100
 		}
 		result = append(result, domain.ServiceSatisfactionDTO{
-			CategoryID: row.CategoryID,
-			Label:      label,
-			AvgScore:   row.AvgScore,
-			Responses:  row.Responses,
+			CategoryID: categoryID,
+			Label:      tree.name(categoryID),
+			AvgScore:   avgScore,
+			Responses:  responses[categoryID],
 			Percentage: percentage,
 		})
 	}
@@ -321,7 +577,7 @@ func (service *ReportService) SurveySatisfaction(
 	responses, err := service.reports.ListSurveyResponsesByTicketCategoryAndTemplate(
 		start,
 		end,
-		categoryID,
+		service.categorySubtree(categoryID),
 		template.ID,
 		false,
 	)
@@ -404,7 +660,7 @@ func (service *ReportService) SurveySatisfactionExport(
 	responses, err := service.reports.ListSurveyResponsesByTicketCategoryAndTemplate(
 		start,
 		end,
-		categoryID,
+		service.categorySubtree(categoryID),
 		template.ID,
 		true,
 	)
@@ -454,17 +710,18 @@ func (service *ReportService) TemplatesByCategory(categoryID string) ([]domain.S
 		return nil, errors.New("categoryId wajib diisi")
 	}
 
-	category, err := service.categories.FindByID(categoryID)
-	if err != nil {
+	if _, err := service.categories.FindByID(categoryID); err != nil {
 		return nil, err
 	}
+	tree := service.categoryTree()
+	activeTemplateID := tree.effectiveTemplateID(categoryID)
 
 	templateIDs := map[string]struct{}{}
-	if category.SurveyTemplateID != "" {
-		templateIDs[category.SurveyTemplateID] = struct{}{}
+	if activeTemplateID != "" {
+		templateIDs[activeTemplateID] = struct{}{}
 	}
 
-	usedIDs, err := service.reports.ListUsedTemplateIDsByCategory(categoryID)
+	usedIDs, err := service.reports.ListUsedTemplateIDsByCategory(tree.descendants(categoryID))
 	if err != nil {
 		return nil, err
 	}
@@ -489,10 +746,10 @@ func (service *ReportService) TemplatesByCategory(categoryID string) ([]domain.S
 	}
 
 	sort.Slice(templates, func(i, j int) bool {
-		if templates[i].ID == category.SurveyTemplateID {
+		if templates[i].ID == activeTemplateID {
 			return true
 		}
-		if templates[j].ID == category.SurveyTemplateID {
+		if templates[j].ID == activeTemplateID {
 			return false
 		}
 		return templates[i].UpdatedAt.After(templates[j].UpdatedAt)
@@ -540,6 +797,8 @@ func (service *ReportService) EntityServiceMatrix(period string, periods int) ([
 
 	start, end := periodRange(period, periods, service.now)
 
+	// Matriks entitas memakai kategori root; sub-kategori digabung ke root-nya.
+	tree := service.categoryTree()
 	ticketRows, err := service.reports.ListRegisteredTicketRowsByEntityCategory(start, end)
 	if err != nil {
 		return nil, err
@@ -548,7 +807,8 @@ func (service *ReportService) EntityServiceMatrix(period string, periods int) ([
 		if ticketCounts[item.Entity] == nil {
 			ticketCounts[item.Entity] = make(map[string]int)
 		}
-		ticketCounts[item.Entity][item.CategoryID] = item.Total
+		root, _ := tree.rollupTarget(item.CategoryID, "")
+		ticketCounts[item.Entity][root] += item.Total
 	}
 
 	surveyRows, err := service.reports.ListRegisteredSurveyRowsByEntityCategory(start, end)
@@ -559,7 +819,8 @@ func (service *ReportService) EntityServiceMatrix(period string, periods int) ([
 		if surveyCounts[item.Entity] == nil {
 			surveyCounts[item.Entity] = make(map[string]int)
 		}
-		surveyCounts[item.Entity][item.CategoryID] = item.Total
+		root, _ := tree.rollupTarget(item.CategoryID, "")
+		surveyCounts[item.Entity][root] += item.Total
 	}
 
 	categories, err := service.listRegisteredCategories()
@@ -598,7 +859,17 @@ func (service *ReportService) EntityServiceMatrix(period string, periods int) ([
 }
 
 func (service *ReportService) listRegisteredCategories() ([]domain.ServiceCategory, error) {
-	return service.reports.ListRegisteredCategories()
+	categories, err := service.reports.ListRegisteredCategories()
+	if err != nil {
+		return nil, err
+	}
+	roots := make([]domain.ServiceCategory, 0, len(categories))
+	for _, category := range categories {
+		if category.ParentID == "" {
+			roots = append(roots, category)
+		}
+	}
+	return roots, nil
 }
 
 func periodRange(period string, periods int, nowFn func() time.Time) (time.Time, time.Time) {
@@ -623,14 +894,13 @@ func (service *ReportService) resolveTemplate(
 ) (*domain.SurveyTemplate, error) {
 	selectedTemplateID := strings.TrimSpace(templateID)
 	if selectedTemplateID == "" {
-		category, err := service.categories.FindByID(categoryID)
-		if err != nil {
+		if _, err := service.categories.FindByID(categoryID); err != nil {
 			return nil, err
 		}
-		if category.SurveyTemplateID == "" {
+		selectedTemplateID = service.categoryTree().effectiveTemplateID(categoryID)
+		if selectedTemplateID == "" {
 			return nil, gorm.ErrRecordNotFound
 		}
-		selectedTemplateID = category.SurveyTemplateID
 	}
 
 	if withOrdering {
@@ -639,15 +909,20 @@ func (service *ReportService) resolveTemplate(
 	return service.surveys.FindByID(selectedTemplateID)
 }
 
-func (service *ReportService) categoryNameMap() map[string]string {
-	categories := make(map[string]string)
-	categoryRows, err := service.categories.List()
-	if err == nil {
-		for _, cat := range categoryRows {
-			categories[cat.ID] = cat.Name
-		}
+func (service *ReportService) categoryTree() categoryTree {
+	items, err := service.categories.List()
+	if err != nil {
+		return newCategoryTree(nil)
+	}
+	return newCategoryTree(items)
+}
+
+// categorySubtree mengembalikan kategori beserta turunannya; nil berarti semua kategori.
+func (service *ReportService) categorySubtree(categoryID string) []string {
+	if categoryID == "" {
+		return nil
 	}
-	return categories
+	return service.categoryTree().descendants(categoryID)
 }
 
 func (service *ReportService) resolveCategoryName(categoryID string) string {
@@ -4,8 +4,7 @@ import (
 	"fmt"
 
 	"unila_helpdesk_backend/internal/domain"
-
-	"gorm.io/gorm"
+	"unila_helpdesk_backend/internal/repository"
 )
 
 const (
@@ -19,6 +18,7 @@ const (
 	CategoryGuestEmailRegistration = "guest-email-unila"
 )
 
+// DefaultCategories adalah kategori awal yang dipakai saat instalasi pertama.
 func DefaultCategories() []domain.ServiceCategory {
 	return []domain.ServiceCategory{
 		{ID: CategoryInternet, Name: "Jaringan Internet", GuestAllowed: false},
@@ -32,42 +32,21 @@ func DefaultCategories() []domain.ServiceCategory {
 	}
 }
 
-func CleanupDeprecatedCategories(database *gorm.DB, fallbackCategoryID string) error {
-	if fallbackCategoryID == "" {
-		return fmt.Errorf("fallback category id wajib diisi")
+// SeedDefaultCategories mengisi kategori bawaan hanya jika tabel masih kosong.
+// Setelah itu kategori dikelola admin melalui API.
+func SeedDefaultCategories(categories *repository.CategoryRepository) error {
+	total, err := categories.Count()
+	if err != nil {
+		return err
 	}
-	deprecatedIDs := []string{
-		"membership",
-		"guest-account",
-		"guest-email",
-		"email",
-		"vclass",
+	if total > 0 {
+		return nil
 	}
-	return database.Transaction(func(tx *gorm.DB) error {
-		var fallbackCount int64
-		if err := tx.Model(&domain.ServiceCategory{}).
-			Where("id = ?", fallbackCategoryID).
-			Count(&fallbackCount).Error; err != nil {
-			return err
-		}
-		if fallbackCount == 0 {
-			return fmt.Errorf("fallback category '%s' tidak ditemukan", fallbackCategoryID)
-		}
-
-		if err := tx.Model(&domain.Ticket{}).
-			Where("category_id IN ?", deprecatedIDs).
-			Update("category_id", fallbackCategoryID).Error; err != nil {
-			return err
+	for index, category := range DefaultCategories() {
+		category.SortOrder = index
+		if err := categories.Create(&category); err != nil {
+			return fmt.Errorf("seed kategori %s: %w", category.ID, err)
 		}
-		if err := tx.Model(&domain.SurveyTemplate{}).
-			Where("category_id IN ?", deprecatedIDs).
-			Update("category_id", fallbackCategoryID).Error; err != nil {
-			return err
-		}
-		if err := tx.Where("id IN ?", deprecatedIDs).
-			Delete(&domain.ServiceCategory{}).Error; err != nil {
-			return err
-		}
-		return nil
-	})
+	}
+	return nil
 }
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"unila_helpdesk_backend/internal/domain"
+	"unila_helpdesk_backend/internal/repository"
+)
+
+// SessionService menampilkan dan mencabut perangkat yang sedang login. Mencabut
+// sesi menghapus refresh token dan push token milik perangkat tersebut.
+type SessionService struct {
+	users         *repository.UserRepository
+	sessions      *repository.SessionRepository
+	refreshTokens *repository.RefreshTokenRepository
+	notifications *NotificationService
+	audit         *AuditService
+	now           func() time.Time
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+	// All mencabut seluruh sesi, refresh token, push token, dan access token user.
+	All bool `json:"all"`
+}
+
+func NewSessionService(
+	users *repository.UserRepository,
+	sessions *repository.SessionRepository,
+	refreshTokens *repository.RefreshTokenRepository,
+	notifications *NotificationService,
+	audit *AuditService,
+) *SessionService {
+	return &SessionService{
+		users:         users,
+		sessions:      sessions,
+		refreshTokens: refreshTokens,
+		notifications: notifications,
+		audit:         audit,
+		now:           time.Now,
+	}
+}
+
+func (service *SessionService) List(user domain.User, currentSessionID string) ([]domain.SessionDTO, error) {
+	sessions, err := service.sessions.ListActiveByUser(user.ID, service.now())
+	if err != nil {
+		return nil, err
+	}
+	result := make([]domain.SessionDTO, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, toSessionDTO(session, currentSessionID))
+	}
+	return result, nil
+}
+
+func (service *SessionService) Revoke(ctx context.Context, user domain.User, sessionID string) error {
+	session, err := service.sessions.FindByID(sessionID)
+	if err != nil || session.UserID != user.ID {
+		return errors.New("sesi tidak ditemukan")
+	}
+	if err := service.revoke(user, *session); err != nil {
+		return err
+	}
+	service.audit.Record(ctx, AuditEntry{
+		Actor:      &user,
+		Action:     AuditActionSessionRevoke,
+		EntityType: AuditEntitySession,
+		EntityID:   session.ID,
+		Before:     sessionAuditSnapshot(*session),
+	})
+	return nil
+}
+
+// Logout mencabut sesi saat ini dan menghapus refresh token yang dikirim. Access
+// token lama tanpa klaim sesi memakai refresh token tersebut untuk menemukan sesinya.
+func (service *SessionService) Logout(ctx context.Context, user domain.User, sessionID string, req LogoutRequest) error {
+	if req.All {
+		if err := service.revokeAll(user); err != nil {
+			return err
+		}
+		service.audit.Record(ctx, AuditEntry{
+			Actor:      &user,
+			Action:     AuditActionLogout,
+			EntityType: AuditEntityUser,
+			EntityID:   user.ID,
+			After:      map[string]any{"all": true},
+		})
+		return nil
+	}
+
+	if token := strings.TrimSpace(req.RefreshToken); token != "" {
+		tokenHash := hashToken(token)
+		stored, err := service.refreshTokens.FindByHash(tokenHash)
+		if err != nil || stored.UserID != user.ID {
+			return errors.New("refresh token tidak valid")
+		}
+		if err := service.refreshTokens.DeleteByUserAndHash(user.ID, tokenHash); err != nil {
+			return err
+		}
+		if sessionID == "" {
+			sessionID = stored.SessionID
+		}
+	}
+	if sessionID == "" {
+		if strings.TrimSpace(req.RefreshToken) == "" {
+			return errors.New("refresh token wajib diisi")
+		}
+		return nil
+	}
+
+	session, err := service.sessions.FindByID(sessionID)
+	if err != nil || session.UserID != user.ID {
+		return errors.New("sesi tidak ditemukan")
+	}
+	if err := service.revoke(user, *session); err != nil {
+		return err
+	}
+	service.audit.Record(ctx, AuditEntry{
+		Actor:      &user,
+		Action:     AuditActionLogout,
+		EntityType: AuditEntitySession,
+		EntityID:   session.ID,
+	})
+	return nil
+}
+
+// LogoutWithRefreshToken dipakai saat tidak ada access token yang valid (mis. sudah
+// kadaluarsa). Refresh token yang masih berlaku menjadi bukti kepemilikan sesi.
+func (service *SessionService) LogoutWithRefreshToken(ctx context.Context, req LogoutRequest) error {
+	token := strings.TrimSpace(req.RefreshToken)
+	if token == "" {
+		return errors.New("refresh token wajib diisi")
+	}
+	stored, err := service.refreshTokens.FindByHash(hashToken(token))
+	if err != nil || stored.RevokedAt != nil || stored.UsedAt != nil || service.now().After(stored.ExpiresAt) {
+		return errors.New("refresh token tidak valid")
+	}
+	user, err := service.users.FindByID(stored.UserID)
+	if err != nil {
+		return errors.New("refresh token tidak valid")
+	}
+	return service.Logout(ctx, *user, stored.SessionID, req)
+}
+
+// ForceLogout dipakai admin untuk mengeluarkan user dari semua perangkat.
+func (service *SessionService) ForceLogout(ctx context.Context, actor domain.User, userID string) error {
+	user, err := service.users.FindByID(userID)
+	if err != nil {
+		return errors.New("user tidak ditemukan")
+	}
+	if err := service.revokeAll(*user); err != nil {
+		return err
+	}
+	service.audit.Record(ctx, AuditEntry{
+		Actor:      &actor,
+		Action:     AuditActionForceLogout,
+		EntityType: AuditEntityUser,
+		EntityID:   user.ID,
+	})
+	return nil
+}
+
+// SetUserActive mengaktifkan atau menonaktifkan akun. Penonaktifan langsung
+// mencabut seluruh sesi dan access token user.
+func (service *SessionService) SetUserActive(ctx context.Context, actor domain.User, userID string, active bool) (domain.UserDTO, error) {
+	user, err := service.users.FindByID(userID)
+	if err != nil {
+		return domain.UserDTO{}, errors.New("user tidak ditemukan")
+	}
+	if !active && user.ID == actor.ID {
+		return domain.UserDTO{}, errors.New("tidak dapat menonaktifkan akun sendiri")
+	}
+	if err := service.users.SetActive(user.ID, active); err != nil {
+		return domain.UserDTO{}, err
+	}
+	action := AuditActionUserActivate
+	if !active {
+		action = AuditActionUserDeactivate
+		if err := service.revokeAll(*user); err != nil {
+			return domain.UserDTO{}, err
+		}
+	}
+	service.audit.Record(ctx, AuditEntry{
+		Actor:      &actor,
+		Action:     action,
+		EntityType: AuditEntityUser,
+		EntityID:   user.ID,
+		Before:     map[string]any{"isActive": user.IsActive},
+		After:      map[string]any{"isActive": active},
+	})
+	user.IsActive = active
+	return domain.ToUserDTO(*user), nil
+}
+
+func (service *SessionService) revokeAll(user domain.User) error {
+	if err := service.sessions.RevokeAllByUser(user.ID, service.now()); err != nil {
+		return err
+	}
+	if err := service.notifications.UnregisterAll(user); err != nil {
+		return err
+	}
+	return service.users.RevokeTokens(user.ID)
+}
+
+func (service *SessionService) revoke(user domain.User, session domain.Session) error {
+	if err := service.sessions.Revoke(session.ID, service.now()); err != nil {
+		return err
+	}
+	if session.FCMToken == "" {
+		return nil
+	}
+	return service.notifications.UnregisterToken(user, FCMUnregisterRequest{Token: session.FCMToken})
+}
+
+func toSessionDTO(session domain.Session, currentSessionID string) domain.SessionDTO {
+	return domain.SessionDTO{
+		ID:           session.ID,
+		DeviceName:   session.DeviceName,
+		Platform:     session.Platform,
+		ClientType:   session.ClientType,
+		IPAddress:    session.IPAddress,
+		UserAgent:    session.UserAgent,
+		HasPushToken: session.FCMToken != "",
+		Current:      session.ID == currentSessionID,
+		LastUsedAt:   session.LastUsedAt,
+		ExpiresAt:    session.ExpiresAt,
+		CreatedAt:    session.CreatedAt,
+	}
+}
+
+func sessionAuditSnapshot(session domain.Session) map[string]any {
+	return map[string]any{
+		"deviceName": session.DeviceName,
+		"platform":   session.Platform,
+		"clientType": session.ClientType,
+		"ipAddress":  session.IPAddress,
+	}
+}
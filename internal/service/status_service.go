@@ -0,0 +1,422 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"unila_helpdesk_backend/internal/domain"
+	"unila_helpdesk_backend/internal/repository"
+	"unila_helpdesk_backend/internal/util"
+
+	"gorm.io/gorm"
+)
+
+const (
+	statusHistoryDays = 90
+	// recoveryAnnouncementTTL adalah lama pengumuman "kembali normal" ditampilkan.
+	recoveryAnnouncementTTL = 24 * time.Hour
+)
+
+type StatusService struct {
+	incidents     *repository.ServiceIncidentRepository
+	categories    *repository.CategoryRepository
+	announcements *AnnouncementService
+	audit         *AuditService
+	now           func() time.Time
+}
+
+type ServiceStateRequest struct {
+	State   domain.ServiceState `json:"state"`
+	Message string              `json:"message"`
+	// Broadcast opsional diteruskan ke pengumuman yang dibuat: "all" atau "affected".
+	Broadcast string `json:"broadcast"`
+}
+
+func NewStatusService(
+	incidents *repository.ServiceIncidentRepository,
+	categories *repository.CategoryRepository,
+	announcements *AnnouncementService,
+	audit *AuditService,
+) *StatusService {
+	return &StatusService{
+		incidents:     incidents,
+		categories:    categories,
+		announcements: announcements,
+		audit:         audit,
+		now:           time.Now,
+	}
+}
+
+// Page menyusun halaman status publik: layanan root beserta sub-layanan, status
+// terkini, riwayat harian 90 hari, dan pengumuman yang sedang berlaku.
+func (service *StatusService) Page() (domain.StatusPageDTO, error) {
+	tree, roots, err := service.loadServices()
+	if err != nil {
+		return domain.StatusPageDTO{}, err
+	}
+	now := service.now()
+	incidents, err := service.incidents.ListSince(historyStart(now))
+	if err != nil {
+		return domain.StatusPageDTO{}, err
+	}
+	page := domain.StatusPageDTO{
+		State:     domain.StateOperational,
+		UpdatedAt: now,
+		Services:  make([]domain.ServiceStatusDTO, 0, len(roots)),
+	}
+	for _, rootID := range roots {
+		status := service.buildStatus(tree, rootID, incidents, now)
+		page.State = worseState(page.State, status.State)
+		page.Services = append(page.Services, status)
+	}
+	announcements, err := service.announcements.ListActive("")
+	if err != nil {
+		return domain.StatusPageDTO{}, err
+	}
+	page.Announcements = announcements
+	return page, nil
+}
+
+// ServiceStatus mengembalikan status efektif satu kategori, termasuk gangguan pada
+// parent-nya, agar form tiket dapat memberi tahu gangguan yang sudah diketahui.
+func (service *StatusService) ServiceStatus(categoryID string) (domain.ServiceStatusDTO, error) {
+	tree, _, err := service.loadServices()
+	if err != nil {
+		return domain.ServiceStatusDTO{}, err
+	}
+	if _, ok := tree.byID[categoryID]; !ok {
+		return domain.ServiceStatusDTO{}, errors.New("layanan tidak ditemukan")
+	}
+	now := service.now()
+	incidents, err := service.incidents.ListSince(historyStart(now))
+	if err != nil {
+		return domain.ServiceStatusDTO{}, err
+	}
+	status := service.buildStatus(tree, categoryID, incidents, now)
+	for _, ancestorID := range tree.ancestors(categoryID)[1:] {
+		if incident := openIncident(incidents, ancestorID); incident != nil && worseState(status.State, incident.State) != status.State {
+			status.State = incident.State
+			status.Since = &incident.StartedAt
+			status.Message = incident.Message
+		}
+	}
+	return status, nil
+}
+
+// SetState mengubah status layanan. Insiden terbuka ditutup, insiden baru dibuka jika
+// status bukan operational, dan setiap perubahan menghasilkan pengumuman.
+func (service *StatusService) SetState(
+	ctx context.Context,
+	user domain.User,
+	categoryID string,
+	req ServiceStateRequest,
+) (domain.ServiceStatusDTO, error) {
+	switch req.State {
+	case domain.StateOperational, domain.StateDegraded, domain.StateDown:
+	default:
+		return domain.ServiceStatusDTO{}, errors.New("status layanan harus operational, degraded, atau down")
+	}
+	category, err := service.categories.FindByID(categoryID)
+	if err != nil || category.ArchivedAt != nil {
+		return domain.ServiceStatusDTO{}, errors.New("layanan tidak ditemukan")
+	}
+
+	now := service.now()
+	previous := domain.StateOperational
+	current, err := service.incidents.FindOpenByCategory(category.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.ServiceStatusDTO{}, err
+	}
+	if current != nil {
+		previous = current.State
+	}
+	if previous == req.State {
+		return domain.ServiceStatusDTO{}, errors.New("status layanan tidak berubah")
+	}
+
+	message := strings.TrimSpace(req.Message)
+	if message == "" {
+		message = defaultStateMessage(category.Name, req.State)
+	}
+	announcement, audience, err := service.announcements.newAnnouncement(user, AnnouncementRequest{
+		Title:       fmt.Sprintf("%s: %s", category.Name, serviceStateLabel(req.State)),
+		Body:        message,
+		Severity:    stateSeverity(req.State),
+		CategoryIDs: []string{category.ID},
+		StartsAt:    &now,
+		EndsAt:      recoveryEnd(req.State, now),
+		Broadcast:   req.Broadcast,
+	})
+	if err != nil {
+		return domain.ServiceStatusDTO{}, err
+	}
+
+	if current != nil {
+		current.ResolvedAt = &now
+		current.ResolvedBy = user.ID
+	}
+	var incident *domain.ServiceIncident
+	if req.State != domain.StateOperational {
+		incident = &domain.ServiceIncident{
+			ID:             util.NewUUID(),
+			CategoryID:     category.ID,
+			State:          req.State,
+			Message:        message,
+			AnnouncementID: announcement.ID,
+			StartedAt:      now,
+			CreatedBy:      user.ID,
+		}
+	}
+	if err := service.incidents.Transition(&announcement, current, incident); err != nil {
+		return domain.ServiceStatusDTO{}, err
+	}
+	// Push baru dikirim setelah commit agar pengguna tidak menerima pengumuman untuk
+	// perubahan status yang batal tersimpan. Kegagalan broadcast tidak membatalkan
+	// perubahan status; admin dapat mengirim ulang lewat endpoint broadcast.
+	if _, err := service.announcements.publish(ctx, user, &announcement, audience); err != nil {
+		log.Printf("failed to broadcast service state announcement id=%s: %v", announcement.ID, err)
+	}
+
+	service.audit.Record(ctx, AuditEntry{
+		Actor:      &user,
+		Action:     AuditActionServiceState,
+		EntityType: AuditEntityCategory,
+		EntityID:   category.ID,
+		Before:     map[string]any{"state": previous},
+		After:      map[string]any{"state": req.State, "announcementId": announcement.ID},
+	})
+	return service.ServiceStatus(category.ID)
+}
+
+func (service *StatusService) loadServices() (categoryTree, []string, error) {
+	items, err := service.categories.List()
+	if err != nil {
+		return categoryTree{}, nil, err
+	}
+	active := make([]domain.ServiceCategory, 0, len(items))
+	for _, item := range items {
+		if item.ArchivedAt == nil {
+			active = append(active, item)
+		}
+	}
+	tree := newCategoryTree(active)
+	roots := make([]string, 0)
+	for _, item := range active {
+		if _, ok := tree.byID[item.ParentID]; !ok {
+			roots = append(roots, item.ID)
+		}
+	}
+	return tree, roots, nil
+}
+
+// buildStatus menghitung status satu layanan dari insiden di seluruh subtree-nya.
+// Status layanan adalah status terburuk dari dirinya dan komponennya.
+func (service *StatusService) buildStatus(
+	tree categoryTree,
+	categoryID string,
+	incidents []domain.ServiceIncident,
+	now time.Time,
+) domain.ServiceStatusDTO {
+	subtree := tree.descendants(categoryID)
+	scoped := make([]domain.ServiceIncident, 0)
+	for _, incident := range incidents {
+		for _, id := range subtree {
+			if incident.CategoryID == id {
+				scoped = append(scoped, incident)
+				break
+			}
+		}
+	}
+
+	status := domain.ServiceStatusDTO{
+		CategoryID: categoryID,
+		Name:       tree.name(categoryID),
+		State:      domain.StateOperational,
+	}
+	for index := range scoped {
+		incident := scoped[index]
+		if incident.ResolvedAt != nil || worseState(status.State, incident.State) == status.State {
+			continue
+		}
+		status.State = incident.State
+		status.Since = &scoped[index].StartedAt
+		status.Message = incident.Message
+	}
+	status.History, status.UptimePercent = uptimeHistory(scoped, now)
+
+	for _, childID := range tree.children[categoryID] {
+		status.Components = append(status.Components, service.buildStatus(tree, childID, incidents, now))
+	}
+	sort.SliceStable(status.Components, func(i, j int) bool {
+		return tree.byID[status.Components[i].CategoryID].SortOrder < tree.byID[status.Components[j].CategoryID].SortOrder
+	})
+	return status
+}
+
+type stateInterval struct {
+	start time.Time
+	end   time.Time
+}
+
+// uptimeHistory membagi 90 hari terakhir per hari (WIB). Hanya status down yang
+// mengurangi uptime; degraded dicatat terpisah.
+func uptimeHistory(incidents []domain.ServiceIncident, now time.Time) ([]domain.ServiceDayDTO, float64) {
+	down := make([]stateInterval, 0)
+	degraded := make([]stateInterval, 0)
+	for _, incident := range incidents {
+		end := now
+		if incident.ResolvedAt != nil {
+			end = *incident.ResolvedAt
+		}
+		interval := stateInterval{start: incident.StartedAt, end: end}
+		if incident.State == domain.StateDown {
+			down = append(down, interval)
+		} else if incident.State == domain.StateDegraded {
+			degraded = append(degraded, interval)
+		}
+	}
+	down = mergeIntervals(down)
+	degraded = mergeIntervals(degraded)
+
+	start := historyStart(now)
+	days := make([]domain.ServiceDayDTO, 0, statusHistoryDays)
+	var total, downtime time.Duration
+	for day := start; day.Before(now); day = day.AddDate(0, 0, 1) {
+		dayEnd := day.AddDate(0, 0, 1)
+		if dayEnd.After(now) {
+			dayEnd = now
+		}
+		downDuration := overlapDuration(down, day, dayEnd)
+		degradedDuration := overlapDuration(degraded, day, dayEnd)
+		state := domain.StateOperational
+		if downDuration > 0 {
+			state = domain.StateDown
+		} else if degradedDuration > 0 {
+			state = domain.StateDegraded
+		}
+		days = append(days, domain.ServiceDayDTO{
+			Date:            day.Format("2006-01-02"),
+			State:           state,
+			DowntimeMinutes: int(downDuration.Minutes()),
+			DegradedMinutes: int(degradedDuration.Minutes()),
+		})
+		total += dayEnd.Sub(day)
+		downtime += downDuration
+	}
+	uptime := 100.0
+	if total > 0 {
+		uptime = math.Round((1-downtime.Seconds()/total.Seconds())*10000) / 100
+	}
+	return days, uptime
+}
+
+func mergeIntervals(intervals []stateInterval) []stateInterval {
+	if len(intervals) < 2 {
+		return intervals
+	}
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i].start.Before(intervals[j].start) })
+	merged := []stateInterval{intervals[0]}
+	for _, interval := range intervals[1:] {
+		last := &merged[len(merged)-1]
+		if !interval.start.After(last.end) {
+			if interval.end.After(last.end) {
+				last.end = interval.end
+			}
+			continue
+		}
+		merged = append(merged, interval)
+	}
+	return merged
+}
+
+func overlapDuration(intervals []stateInterval, start time.Time, end time.Time) time.Duration {
+	var total time.Duration
+	for _, interval := range intervals {
+		from := interval.start
+		if from.Before(start) {
+			from = start
+		}
+		to := interval.end
+		if to.After(end) {
+			to = end
+		}
+		if to.After(from) {
+			total += to.Sub(from)
+		}
+	}
+	return total
+}
+
+func historyStart(now time.Time) time.Time {
+	local := now.In(reportLocationWIB)
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, reportLocationWIB)
+	return today.AddDate(0, 0, -(statusHistoryDays - 1))
+}
+
+func openIncident(incidents []domain.ServiceIncident, categoryID string) *domain.ServiceIncident {
+	for index := len(incidents) - 1; index >= 0; index-- {
+		if incidents[index].CategoryID == categoryID && incidents[index].ResolvedAt == nil {
+			return &incidents[index]
+		}
+	}
+	return nil
+}
+
+func worseState(current domain.ServiceState, candidate domain.ServiceState) domain.ServiceState {
+	rank := map[domain.ServiceState]int{
+		domain.StateOperational: 0,
+		domain.StateDegraded:    1,
+		domain.StateDown:        2,
+	}
+	if rank[candidate] > rank[current] {
+		return candidate
+	}
+	return current
+}
+
+func serviceStateLabel(state domain.ServiceState) string {
+	switch state {
+	case domain.StateDown:
+		return "Gangguan"
+	case domain.StateDegraded:
+		return "Performa Menurun"
+	default:
+		return "Beroperasi Normal"
+	}
+}
+
+func defaultStateMessage(name string, state domain.ServiceState) string {
+	switch state {
+	case domain.StateDown:
+		return fmt.Sprintf("Layanan %s sedang tidak dapat diakses. Tim kami sedang menangani masalah ini.", name)
+	case domain.StateDegraded:
+		return fmt.Sprintf("Layanan %s mengalami penurunan performa. Tim kami sedang menangani masalah ini.", name)
+	default:
+		return fmt.Sprintf("Layanan %s sudah kembali beroperasi normal.", name)
+	}
+}
+
+func stateSeverity(state domain.ServiceState) domain.AnnouncementSeverity {
+	switch state {
+	case domain.StateDown:
+		return domain.SeverityCritical
+	case domain.StateDegraded:
+		return domain.SeverityWarning
+	default:
+		return domain.SeverityInfo
+	}
+}
+
+func recoveryEnd(state domain.ServiceState, now time.Time) *time.Time {
+	if state != domain.StateOperational {
+		return nil
+	}
+	end := now.Add(recoveryAnnouncementTTL)
+	return &end
+}
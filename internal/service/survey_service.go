@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"strings"
@@ -14,6 +15,7 @@ import (
 type SurveyService struct {
 	surveys *repository.SurveyRepository
 	tickets *repository.TicketRepository
+	audit   *AuditService
 	now     func() time.Time
 }
 
@@ -36,10 +38,15 @@ type SurveyResponseRequest struct {
 	Answers  map[string]interface{} `json:"answers"`
 }
 
-func NewSurveyService(surveys *repository.SurveyRepository, tickets *repository.TicketRepository) *SurveyService {
+func NewSurveyService(
+	surveys *repository.SurveyRepository,
+	tickets *repository.TicketRepository,
+	audit *AuditService,
+) *SurveyService {
 	return &SurveyService{
 		surveys: surveys,
 		tickets: tickets,
+		audit:   audit,
 		now:     time.Now,
 	}
 }
@@ -60,7 +67,7 @@ func (service *SurveyService) TemplateByCategory(categoryID string) (domain.Surv
 	return mapSurveyTemplate(*template), nil
 }
 
-func (service *SurveyService) CreateTemplate(req SurveyTemplateRequest) (domain.SurveyTemplateDTO, error) {
+func (service *SurveyService) CreateTemplate(ctx context.Context, user domain.User, req SurveyTemplateRequest) (domain.SurveyTemplateDTO, error) {
 	if strings.TrimSpace(req.Title) == "" {
 		return domain.SurveyTemplateDTO{}, errors.New("judul template wajib diisi")
 	}
@@ -98,10 +105,18 @@ func (service *SurveyService) CreateTemplate(req SurveyTemplateRequest) (domain.
 	if err := service.surveys.CreateTemplate(&template); err != nil {
 		return domain.SurveyTemplateDTO{}, err
 	}
-	return mapSurveyTemplate(template), nil
+	result := mapSurveyTemplate(template)
+	service.audit.Record(ctx, AuditEntry{
+		Actor:      &user,
+		Action:     AuditActionSurveyTemplateCreate,
+		EntityType: AuditEntitySurveyTemplate,
+		EntityID:   template.ID,
+		After:      surveyTemplateAuditSnapshot(result),
+	})
+	return result, nil
 }
 
-func (service *SurveyService) UpdateTemplate(templateID string, req SurveyTemplateRequest) (domain.SurveyTemplateDTO, error) {
+func (service *SurveyService) UpdateTemplate(ctx context.Context, user domain.User, templateID string, req SurveyTemplateRequest) (domain.SurveyTemplateDTO, error) {
 	if strings.TrimSpace(templateID) == "" {
 		return domain.SurveyTemplateDTO{}, errors.New("template id wajib diisi")
 	}
@@ -116,6 +131,7 @@ func (service *SurveyService) UpdateTemplate(templateID string, req SurveyTempla
 	if err != nil {
 		return domain.SurveyTemplateDTO{}, err
 	}
+	before := surveyTemplateAuditSnapshot(mapSurveyTemplate(*template))
 
 	template.Title = strings.TrimSpace(req.Title)
 	template.Description = strings.TrimSpace(req.Description)
@@ -143,14 +159,37 @@ func (service *SurveyService) UpdateTemplate(templateID string, req SurveyTempla
 	if err := service.surveys.ReplaceTemplate(template); err != nil {
 		return domain.SurveyTemplateDTO{}, err
 	}
-	return mapSurveyTemplate(*template), nil
+	result := mapSurveyTemplate(*template)
+	service.audit.Record(ctx, AuditEntry{
+		Actor:      &user,
+		Action:     AuditActionSurveyTemplateUpdate,
+		EntityType: AuditEntitySurveyTemplate,
+		EntityID:   template.ID,
+		Before:     before,
+		After:      surveyTemplateAuditSnapshot(result),
+	})
+	return result, nil
 }
 
-func (service *SurveyService) DeleteTemplate(templateID string) error {
+func (service *SurveyService) DeleteTemplate(ctx context.Context, user domain.User, templateID string) error {
 	if strings.TrimSpace(templateID) == "" {
 		return errors.New("template id wajib diisi")
 	}
-	return service.surveys.DeleteTemplate(templateID)
+	var before map[string]any
+	if template, err := service.surveys.FindByID(templateID); err == nil {
+		before = surveyTemplateAuditSnapshot(mapSurveyTemplate(*template))
+	}
+	if err := service.surveys.DeleteTemplate(templateID); err != nil {
+		return err
+	}
+	service.audit.Record(ctx, AuditEntry{
+		Actor:      &user,
+		Action:     AuditActionSurveyTemplateDelete,
+		EntityType: AuditEntitySurveyTemplate,
+		EntityID:   templateID,
+		Before:     before,
+	})
+	return nil
 }
 
 func (service *SurveyService) SubmitSurvey(user domain.User, req SurveyResponseRequest) error {
@@ -228,6 +267,53 @@ func (service *SurveyService) ListResponsesPaged(
 	if err != nil {
 		return domain.SurveyResponsePageDTO{}, err
 	}
+	totalPages := util.CalcTotalPages(total, limit)
+	return domain.SurveyResponsePageDTO{
+		Items:      mapSurveyResponseRows(rows),
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// ListResponsesCursor mengembalikan halaman respon survey berbasis cursor (created_at, id).
+func (service *SurveyService) ListResponsesCursor(
+	filter repository.SurveyResponseFilter,
+	rawCursor string,
+	limit int,
+) (domain.SurveyResponsePageDTO, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 50 {
+		limit = 50
+	}
+
+	cursor, err := util.DecodeCursor(rawCursor)
+	if err != nil {
+		return domain.SurveyResponsePageDTO{}, err
+	}
+	rows, hasMore, err := service.surveys.ListResponsesCursor(filter, cursor, limit)
+	if err != nil {
+		return domain.SurveyResponsePageDTO{}, err
+	}
+
+	var first, last util.Cursor
+	if len(rows) > 0 {
+		first = util.Cursor{CreatedAt: rows[0].CreatedAt, ID: rows[0].ID}
+		last = util.Cursor{CreatedAt: rows[len(rows)-1].CreatedAt, ID: rows[len(rows)-1].ID}
+	}
+	next, prev := cursorLinks(cursor, hasMore, len(rows), first, last)
+	return domain.SurveyResponsePageDTO{
+		Items:      mapSurveyResponseRows(rows),
+		Limit:      limit,
+		NextCursor: next,
+		PrevCursor: prev,
+	}, nil
+}
+
+func mapSurveyResponseRows(rows []repository.SurveyResponseRow) []domain.SurveyResponseItemDTO {
 	items := make([]domain.SurveyResponseItemDTO, 0, len(rows))
 	for _, row := range rows {
 		items = append(items, domain.SurveyResponseItemDTO{
@@ -245,14 +331,7 @@ func (service *SurveyService) ListResponsesPaged(
 			CreatedAt:  row.CreatedAt,
 		})
 	}
-	totalPages := util.CalcTotalPages(total, limit)
-	return domain.SurveyResponsePageDTO{
-		Items:      items,
-		Page:       page,
-		Limit:      limit,
-		Total:      total,
-		TotalPages: totalPages,
-	}, nil
+	return items
 }
 
 func mapSurveyTemplates(templates []domain.SurveyTemplate) []domain.SurveyTemplateDTO {
@@ -311,3 +390,21 @@ func calculateSurveyScore(answers map[string]interface{}, template *domain.Surve
 	}
 	return total / float64(count)
 }
+
+func surveyTemplateAuditSnapshot(template domain.SurveyTemplateDTO) map[string]any {
+	questions := make([]map[string]any, 0, len(template.Questions))
+	for _, question := range template.Questions {
+		questions = append(questions, map[string]any{
+			"text":    question.Text,
+			"type":    question.Type,
+			"options": question.Options,
+		})
+	}
+	return map[string]any{
+		"title":       template.Title,
+		"description": template.Description,
+		"framework":   template.Framework,
+		"categoryId":  template.CategoryID,
+		"questions":   questions,
+	}
+}
@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"unila_helpdesk_backend/internal/domain"
+	"unila_helpdesk_backend/internal/repository"
+	"unila_helpdesk_backend/internal/util"
+)
+
+const maxTagsPerTicket = 10
+
+var tagNamePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+type TagService struct {
+	tags      *repository.TagRepository
+	tickets   *repository.TicketRepository
+	ticketing *TicketService
+	audit     *AuditService
+}
+
+type TagRequest struct {
+	Name        string `json:"name"`
+	Color       string `json:"color"`
+	Description string `json:"description"`
+}
+
+func NewTagService(
+	tags *repository.TagRepository,
+	tickets *repository.TicketRepository,
+	ticketing *TicketService,
+	audit *AuditService,
+) *TagService {
+	return &TagService{
+		tags:      tags,
+		tickets:   tickets,
+		ticketing: ticketing,
+		audit:     audit,
+	}
+}
+
+func (service *TagService) List() ([]domain.TagDTO, error) {
+	items, err := service.tags.List()
+	if err != nil {
+		return nil, err
+	}
+	usage, err := service.tags.UsageCounts()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]domain.TagDTO, 0, len(items))
+	for _, item := range items {
+		result = append(result, toTagDTO(item, usage[item.ID]))
+	}
+	return result, nil
+}
+
+func (service *TagService) Create(ctx context.Context, user domain.User, req TagRequest) (domain.TagDTO, error) {
+	name, err := normalizeTagName(req.Name)
+	if err != nil {
+		return domain.TagDTO{}, err
+	}
+	if _, err := service.tags.FindByName(name); err == nil {
+		return domain.TagDTO{}, errors.New("tag sudah ada")
+	}
+	tag := domain.Tag{
+		ID:          util.NewUUID(),
+		Name:        name,
+		Color:       strings.TrimSpace(req.Color),
+		Description: strings.TrimSpace(req.Description),
+	}
+	if err := service.tags.Create(&tag); err != nil {
+		return domain.TagDTO{}, err
+	}
+	service.audit.Record(ctx, AuditEntry{
+		Actor:      &user,
+		Action:     AuditActionTagCreate,
+		EntityType: AuditEntityTag,
+		EntityID:   tag.ID,
+		After:      tagAuditSnapshot(tag),
+	})
+	return toTagDTO(tag, 0), nil
+}
+
+// Update mengganti nama/warna tag. Karena tiket menaut ke ID, penggantian nama
+// langsung berlaku untuk semua tiket yang memakai tag tersebut.
+func (service *TagService) Update(ctx context.Context, user domain.User, tagID string, req TagRequest) (domain.TagDTO, error) {
+	tag, err := service.tags.FindByID(tagID)
+	if err != nil {
+		return domain.TagDTO{}, errors.New("tag tidak ditemukan")
+	}
+	name, err := normalizeTagName(req.Name)
+	if err != nil {
+		return domain.TagDTO{}, err
+	}
+	if existing, err := service.tags.FindByName(name); err == nil && existing.ID != tag.ID {
+		return domain.TagDTO{}, errors.New("tag sudah ada")
+	}
+	before := tagAuditSnapshot(*tag)
+	tag.Name = name
+	tag.Color = strings.TrimSpace(req.Color)
+	tag.Description = strings.TrimSpace(req.Description)
+	if err := service.tags.Update(tag); err != nil {
+		return domain.TagDTO{}, err
+	}
+	service.audit.Record(ctx, AuditEntry{
+		Actor:      &user,
+		Action:     AuditActionTagUpdate,
+		EntityType: AuditEntityTag,
+		EntityID:   tag.ID,
+		Before:     before,
+		After:      tagAuditSnapshot(*tag),
+	})
+	usage, _ := service.tags.UsageCounts()
+	return toTagDTO(*tag, usage[tag.ID]), nil
+}
+
+func (service *TagService) Delete(ctx context.Context, user domain.User, tagID string) error {
+	tag, err := service.tags.FindByID(tagID)
+	if err != nil {
+		return errors.New("tag tidak ditemukan")
+	}
+	if err := service.tags.Delete(tag.ID); err != nil {
+		return err
+	}
+	service.audit.Record(ctx, AuditEntry{
+		Actor:      &user,
+		Action:     AuditActionTagDelete,
+		EntityType: AuditEntityTag,
+		EntityID:   tag.ID,
+		Before:     tagAuditSnapshot(*tag),
+	})
+	return nil
+}
+
+// AddToTicket memasang tag yang sudah terdaftar ke tiket. Tag yang sudah terpasang diabaikan.
+func (service *TagService) AddToTicket(
+	ctx context.Context,
+	user domain.User,
+	ticketID string,
+	names []string,
+) (domain.TicketDTO, error) {
+	ticket, err := service.tickets.FindByID(ticketID)
+	if err != nil {
+		return domain.TicketDTO{}, errors.New("tiket tidak ditemukan")
+	}
+
+	normalized := make([]string, 0, len(names))
+	for _, raw := range names {
+		name, err := normalizeTagName(raw)
+		if err != nil {
+			return domain.TicketDTO{}, err
+		}
+		if !slices.Contains(normalized, name) {
+			normalized = append(normalized, name)
+		}
+	}
+	if len(normalized) == 0 {
+		return domain.TicketDTO{}, errors.New("tag wajib diisi")
+	}
+	tags, err := service.tags.FindByNames(normalized)
+	if err != nil {
+		return domain.TicketDTO{}, err
+	}
+	for _, name := range normalized {
+		if !slices.ContainsFunc(tags, func(tag domain.Tag) bool { return tag.Name == name }) {
+			return domain.TicketDTO{}, fmt.Errorf("tag %s belum terdaftar", name)
+		}
+	}
+
+	tagIDs := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tagIDs = append(tagIDs, tag.ID)
+	}
+	// Batas dihitung ulang di dalam transaksi repository terhadap tag yang tersimpan,
+	// bukan terhadap ticket.Tags yang mungkin sudah basi.
+	addedIDs, err := service.tags.AddToTicket(ticket.ID, tagIDs, user.ID, maxTagsPerTicket)
+	if errors.Is(err, repository.ErrTicketTagLimit) {
+		return domain.TicketDTO{}, fmt.Errorf("maksimal %d tag per tiket", maxTagsPerTicket)
+	}
+	if err != nil {
+		return domain.TicketDTO{}, err
+	}
+	added := make([]string, 0, len(addedIDs))
+	for _, tag := range tags {
+		if slices.Contains(addedIDs, tag.ID) {
+			added = append(added, tag.Name)
+		}
+	}
+	if len(added) > 0 {
+		existing := tagNames(ticket.Tags)
+		_ = service.ticketing.addHistory(
+			ticket.ID,
+			&user,
+			"Tag Updated",
+			fmt.Sprintf("Tag %s ditambahkan oleh %s", strings.Join(added, ", "), user.Name),
+		)
+		service.audit.Record(ctx, AuditEntry{
+			Actor:      &user,
+			Action:     AuditActionTicketTag,
+			EntityType: AuditEntityTicket,
+			EntityID:   ticket.ID,
+			Before:     map[string]any{"tags": existing},
+			After:      map[string]any{"tags": append(slices.Clone(existing), added...)},
+		})
+	}
+	return service.ticketing.GetTicket(&user, ticket.ID)
+}
+
+func (service *TagService) RemoveFromTicket(
+	ctx context.Context,
+	user domain.User,
+	ticketID string,
+	tagID string,
+) (domain.TicketDTO, error) {
+	ticket, err := service.tickets.FindByID(ticketID)
+	if err != nil {
+		return domain.TicketDTO{}, errors.New("tiket tidak ditemukan")
+	}
+	tag, err := service.tags.FindByID(tagID)
+	if err != nil {
+		tag, err = service.tags.FindByName(strings.ToLower(strings.TrimSpace(tagID)))
+		if err != nil {
+			return domain.TicketDTO{}, errors.New("tag tidak ditemukan")
+		}
+	}
+	removed, err := service.tags.RemoveFromTicket(ticket.ID, tag.ID)
+	if err != nil {
+		return domain.TicketDTO{}, err
+	}
+	if removed {
+		existing := tagNames(ticket.Tags)
+		_ = service.ticketing.addHistory(
+			ticket.ID,
+			&user,
+			"Tag Updated",
+			fmt.Sprintf("Tag %s dihapus oleh %s", tag.Name, user.Name),
+		)
+		service.audit.Record(ctx, AuditEntry{
+			Actor:      &user,
+			Action:     AuditActionTicketUntag,
+			EntityType: AuditEntityTicket,
+			EntityID:   ticket.ID,
+			Before:     map[string]any{"tags": existing},
+			After: map[string]any{"tags": slices.DeleteFunc(slices.Clone(existing), func(name string) bool {
+				return name == tag.Name
+			})},
+		})
+	}
+	return service.ticketing.GetTicket(&user, ticket.ID)
+}
+
+func normalizeTagName(raw string) (string, error) {
+	name := strings.ToLower(strings.TrimSpace(raw))
+	name = strings.Join(strings.Fields(name), "-")
+	if name == "" {
+		return "", errors.New("nama tag wajib diisi")
+	}
+	if len(name) > 40 || !tagNamePattern.MatchString(name) {
+		return "", fmt.Errorf("nama tag %q hanya boleh huruf kecil, angka, dan tanda hubung", raw)
+	}
+	return name, nil
+}
+
+func tagNames(tags []domain.Tag) []string {
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	return names
+}
+
+func tagAuditSnapshot(tag domain.Tag) map[string]any {
+	return map[string]any{
+		"name":        tag.Name,
+		"color":       tag.Color,
+		"description": tag.Description,
+	}
+}
+
+func toTagDTO(tag domain.Tag, usage int64) domain.TagDTO {
+	return domain.TagDTO{
+		ID:          tag.ID,
+		Name:        tag.Name,
+		Color:       tag.Color,
+		Description: tag.Description,
+		Usage:       usage,
+	}
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"testing"
+
+	"unila_helpdesk_backend/internal/domain"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB membuka SQLite in-memory sebagai pengganti Postgres untuk test yang
+// hanya menyentuh tabel autentikasi.
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(
+		&domain.User{},
+		&domain.UserIdentity{},
+		&domain.RefreshToken{},
+		&domain.Session{},
+		&domain.OIDCLoginState{},
+	); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+	return db
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"unila_helpdesk_backend/internal/domain"
+	"unila_helpdesk_backend/internal/repository"
+)
+
+const (
+	BulkActionStatus   = "status"
+	BulkActionAssign   = "assign"
+	BulkActionPriority = "priority"
+	BulkActionCategory = "category"
+	BulkActionComment  = "comment"
+	BulkActionDelete   = "delete"
+)
+
+const maxBulkTickets = 500
+
+// TicketBulkRequest memilih tiket lewat TicketIDs atau Filter (salah satu),
+// lalu menerapkan satu Action ke semua tiket tersebut.
+type TicketBulkRequest struct {
+	TicketIDs []string                     `json:"ticketIds"`
+	Filter    *repository.TicketListFilter `json:"filter"`
+	Action    string                       `json:"action"`
+	Status    *domain.TicketStatus         `json:"status"`
+	Assignee  *string                      `json:"assignee"`
+	Priority  *domain.TicketPriority       `json:"priority"`
+	Category  *string                      `json:"category"`
+	Message   string                       `json:"message"`
+	Reason    string                       `json:"reason"`
+}
+
+// BulkUpdate menerapkan satu aksi admin ke banyak tiket. Setiap tiket diproses
+// sendiri-sendiri (riwayat dan notifikasi per tiket); kegagalan satu tiket tidak
+// membatalkan yang lain.
+func (service *TicketService) BulkUpdate(ctx context.Context, user domain.User, req TicketBulkRequest) (domain.BulkTicketResultDTO, error) {
+	if user.Role != domain.RoleAdmin {
+		return domain.BulkTicketResultDTO{}, errors.New("hanya admin yang dapat melakukan aksi massal")
+	}
+	if err := validateBulkRequest(req); err != nil {
+		return domain.BulkTicketResultDTO{}, err
+	}
+
+	result := domain.BulkTicketResultDTO{
+		Action:  req.Action,
+		Results: make([]domain.BulkTicketItemResultDTO, 0),
+	}
+	if req.Filter == nil {
+		service.applyBulkBatch(ctx, user, req, uniqueTicketIDs(req.TicketIDs), &result)
+		return result, nil
+	}
+
+	// Hasil filter diproses per halaman maxBulkTickets dengan keyset pada ID, sehingga
+	// tiket yang keluar dari filter setelah diubah tidak menggeser halaman berikutnya.
+	afterID := ""
+	for {
+		ids, err := service.tickets.ListIDsFiltered(*req.Filter, afterID, maxBulkTickets)
+		if err != nil {
+			if result.Total == 0 {
+				return domain.BulkTicketResultDTO{}, err
+			}
+			return domain.BulkTicketResultDTO{}, fmt.Errorf("gagal memuat tiket setelah %d tiket diproses: %w", result.Total, err)
+		}
+		service.applyBulkBatch(ctx, user, req, ids, &result)
+		if len(ids) < maxBulkTickets {
+			return result, nil
+		}
+		afterID = ids[len(ids)-1]
+	}
+}
+
+func (service *TicketService) applyBulkBatch(
+	ctx context.Context,
+	user domain.User,
+	req TicketBulkRequest,
+	ids []string,
+	result *domain.BulkTicketResultDTO,
+) {
+	for _, ticketID := range ids {
+		item := domain.BulkTicketItemResultDTO{TicketID: ticketID, Success: true}
+		if err := service.applyBulkAction(ctx, user, ticketID, req); err != nil {
+			item.Success = false
+			item.Error = err.Error()
+			result.Failed++
+		} else {
+			result.Succeeded++
+		}
+		result.Total++
+		result.Results = append(result.Results, item)
+	}
+}
+
+func validateBulkRequest(req TicketBulkRequest) error {
+	if len(req.TicketIDs) == 0 && req.Filter == nil {
+		return errors.New("ticketIds atau filter wajib diisi")
+	}
+	if len(req.TicketIDs) > 0 && req.Filter != nil {
+		return errors.New("gunakan ticketIds atau filter, bukan keduanya")
+	}
+	// Filter kosong akan cocok dengan semua tiket di sistem.
+	if req.Filter != nil && req.Filter.IsEmpty() {
+		return errors.New("filter minimal memiliki satu kriteria")
+	}
+	if len(req.TicketIDs) > maxBulkTickets {
+		return fmt.Errorf("maksimal %d tiket per permintaan", maxBulkTickets)
+	}
+	switch req.Action {
+	case BulkActionStatus:
+		if req.Status == nil {
+			return errors.New("status wajib diisi")
+		}
+		switch *req.Status {
+		case domain.StatusWaiting, domain.StatusInProgress, domain.StatusResolved:
+		default:
+			return errors.New("status tiket tidak valid")
+		}
+	case BulkActionAssign:
+		if req.Assignee == nil {
+			return errors.New("assignee wajib diisi")
+		}
+	case BulkActionPriority:
+		if req.Priority == nil {
+			return errors.New("prioritas wajib diisi")
+		}
+		switch *req.Priority {
+		case domain.PriorityLow, domain.PriorityMedium, domain.PriorityHigh:
+		default:
+			return errors.New("prioritas tiket tidak valid")
+		}
+	case BulkActionCategory:
+		if req.Category == nil || strings.TrimSpace(*req.Category) == "" {
+			return errors.New("kategori wajib diisi")
+		}
+	case BulkActionComment:
+		if strings.TrimSpace(req.Message) == "" {
+			return errors.New("komentar tidak boleh kosong")
+		}
+	case BulkActionDelete:
+	default:
+		return errors.New("aksi tidak valid")
+	}
+	return nil
+}
+
+func uniqueTicketIDs(raw []string) []string {
+	seen := make(map[string]struct{}, len(raw))
+	ids := make([]string, 0, len(raw))
+	for _, id := range raw {
+		cleaned := strings.TrimSpace(id)
+		if cleaned == "" {
+			continue
+		}
+		if _, ok := seen[cleaned]; ok {
+			continue
+		}
+		seen[cleaned] = struct{}{}
+		ids = append(ids, cleaned)
+	}
+	return ids
+}
+
+func (service *TicketService) applyBulkAction(ctx context.Context, user domain.User, ticketID string, req TicketBulkRequest) error {
+	switch req.Action {
+	case BulkActionStatus:
+		// UpdateTicket sudah mengirim notifikasi perubahan status.
+		_, err := service.UpdateTicket(ctx, user, ticketID, TicketUpdateRequest{Status: req.Status})
+		return err
+	case BulkActionAssign, BulkActionPriority, BulkActionCategory:
+		// UpdateTicket tetap sukses walau nilainya tidak berubah; notifikasi hanya
+		// dikirim jika nilai tiket benar-benar berbeda dari sebelumnya.
+		before, err := service.tickets.FindByID(ticketID)
+		if err != nil {
+			return err
+		}
+		var update TicketUpdateRequest
+		switch req.Action {
+		case BulkActionAssign:
+			update = TicketUpdateRequest{Assignee: req.Assignee}
+		case BulkActionPriority:
+			update = TicketUpdateRequest{Priority: req.Priority}
+		case BulkActionCategory:
+			update = TicketUpdateRequest{Category: req.Category}
+		}
+		ticket, err := service.UpdateTicket(ctx, user, ticketID, update)
+		if err != nil {
+			return err
+		}
+		switch {
+		case req.Action == BulkActionAssign && ticket.Assignee != before.Assignee:
+			service.notifyBulkChange(ctx, ticketID, fmt.Sprintf("Tiket %s ditangani oleh %s.", ticketID, ticket.Assignee))
+		case req.Action == BulkActionPriority && ticket.Priority != before.Priority:
+			service.notifyBulkChange(ctx, ticketID, fmt.Sprintf("Prioritas tiket %s diubah menjadi %s.", ticketID, ticket.Priority))
+		case req.Action == BulkActionCategory && ticket.CategoryID != before.CategoryID:
+			service.notifyBulkChange(ctx, ticketID, fmt.Sprintf("Kategori tiket %s diubah menjadi %s.", ticketID, ticket.Category))
+		}
+		return nil
+	case BulkActionComment:
+		if _, err := service.AddComment(ctx, user, ticketID, req.Message); err != nil {
+			return err
+		}
+		if err := service.addHistory(ticketID, &user, "Comment Added", "Komentar ditambahkan oleh staf"); err != nil {
+			log.Printf("failed to add ticket history: %v", err)
+		}
+		service.notifyBulkChange(ctx, ticketID, fmt.Sprintf("Ada balasan baru pada tiket %s.", ticketID))
+		return nil
+	case BulkActionDelete:
+		ticket, err := service.tickets.FindByID(ticketID)
+		if err != nil {
+			return err
+		}
+		if err := service.DeleteTicket(ctx, user, ticketID, req.Reason); err != nil {
+			return err
+		}
+		if err := service.notifyTicketStatus(ctx, *ticket, "Tiket Dihapus", fmt.Sprintf("Tiket %s telah dihapus oleh admin.", ticketID)); err != nil {
+			log.Printf("failed to send delete notification: %v", err)
+		}
+		return nil
+	}
+	return errors.New("aksi tidak valid")
+}
+
+func (service *TicketService) notifyBulkChange(ctx context.Context, ticketID string, message string) {
+	ticket, err := service.tickets.FindByID(ticketID)
+	if err != nil {
+		log.Printf("failed to load ticket for notification: %v", err)
+		return
+	}
+	if err := service.notifyTicketStatus(ctx, *ticket, "Tiket Diperbarui", message); err != nil {
+		log.Printf("failed to send bulk notification: %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"unila_helpdesk_backend/internal/domain"
+	"unila_helpdesk_backend/internal/util"
+)
+
+const (
+	ticketFieldTitle       = "title"
+	ticketFieldDescription = "description"
+	ticketFieldCategory    = "category"
+	ticketFieldPriority    = "priority"
+	ticketFieldStatus      = "status"
+	ticketFieldAssignee    = "assignee"
+	ticketFieldCustom      = "customFields"
+)
+
+func (service *TicketService) addHistory(ticketID string, actor *domain.User, title, description string) error {
+	history := domain.TicketHistory{
+		ID:          util.NewUUID(),
+		TicketID:    ticketID,
+		Title:       title,
+		Description: description,
+		Timestamp:   service.now(),
+	}
+	if actor != nil {
+		history.ActorID = actor.ID
+		history.ActorName = actor.Name
+	}
+	return service.tickets.AddHistory(&history)
+}
+
+// addChangeHistory menyimpan satu entri riwayat berisi perubahan per field.
+// Description diisi teks gabungan agar klien lama tetap menampilkan isi yang berarti.
+func (service *TicketService) addChangeHistory(ticketID string, actor domain.User, title string, changes []domain.TicketFieldChange) error {
+	payload, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+	descriptions := make([]string, 0, len(changes))
+	for _, change := range changes {
+		descriptions = append(descriptions, describeTicketChange(change, actor.Name))
+	}
+	return service.tickets.AddHistory(&domain.TicketHistory{
+		ID:          util.NewUUID(),
+		TicketID:    ticketID,
+		ActorID:     actor.ID,
+		ActorName:   actor.Name,
+		Title:       title,
+		Description: strings.Join(descriptions, "; "),
+		Changes:     payload,
+		Timestamp:   service.now(),
+	})
+}
+
+func ticketFieldChange(field string, oldValue string, newValue string, oldLabel string, newLabel string) domain.TicketFieldChange {
+	return domain.TicketFieldChange{
+		Field:    field,
+		OldValue: oldValue,
+		NewValue: newValue,
+		OldLabel: oldLabel,
+		NewLabel: newLabel,
+	}
+}
+
+func toTicketHistoryDTO(item domain.TicketHistory) domain.TicketHistoryDTO {
+	dto := domain.TicketHistoryDTO{
+		Title:       item.Title,
+		Description: item.Description,
+		Actor:       item.ActorName,
+		Timestamp:   item.Timestamp,
+	}
+	if len(item.Changes) == 0 {
+		return dto
+	}
+	var changes []domain.TicketFieldChange
+	if err := json.Unmarshal(item.Changes, &changes); err != nil {
+		return dto
+	}
+	dto.Changes = make([]domain.TicketFieldChangeDTO, 0, len(changes))
+	for _, change := range changes {
+		dto.Changes = append(dto.Changes, domain.TicketFieldChangeDTO{
+			Field:       change.Field,
+			OldValue:    change.OldValue,
+			NewValue:    change.NewValue,
+			Description: describeTicketChange(change, item.ActorName),
+		})
+	}
+	return dto
+}
+
+// describeTicketChange menghasilkan kalimat seperti
+// "Kategori diubah dari SIAKAD ke Website oleh Admin".
+func describeTicketChange(change domain.TicketFieldChange, actorName string) string {
+	label := ticketFieldLabel(change.Field)
+	oldValue := firstNonEmpty(change.OldLabel, change.OldValue)
+	newValue := firstNonEmpty(change.NewLabel, change.NewValue)
+
+	var sentence string
+	switch {
+	case change.Field == ticketFieldDescription || change.Field == ticketFieldCustom:
+		sentence = label + " diperbarui"
+	case oldValue == "":
+		sentence = fmt.Sprintf("%s diatur ke %s", label, newValue)
+	case newValue == "":
+		sentence = label + " dikosongkan"
+	default:
+		sentence = fmt.Sprintf("%s diubah dari %s ke %s", label, oldValue, newValue)
+	}
+	if strings.TrimSpace(actorName) != "" {
+		sentence += " oleh " + actorName
+	}
+	return sentence
+}
+
+func ticketFieldLabel(field string) string {
+	switch field {
+	case ticketFieldTitle:
+		return "Judul"
+	case ticketFieldDescription:
+		return "Deskripsi"
+	case ticketFieldCategory:
+		return "Kategori"
+	case ticketFieldPriority:
+		return "Prioritas"
+	case ticketFieldStatus:
+		return "Status"
+	case ticketFieldAssignee:
+		return "Petugas"
+	case ticketFieldCustom:
+		return "Data tambahan"
+	default:
+		return field
+	}
+}
+
+func priorityLabel(priority domain.TicketPriority) string {
+	switch priority {
+	case domain.PriorityLow:
+		return "Rendah"
+	case domain.PriorityMedium:
+		return "Sedang"
+	case domain.PriorityHigh:
+		return "Tinggi"
+	default:
+		return string(priority)
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -8,6 +8,7 @@ import (
 	"log"
 	"net/url"
 	"path"
+	"reflect"
 	"strings"
 	"time"
 
@@ -15,6 +16,8 @@ import (
 	"unila_helpdesk_backend/internal/fcm"
 	"unila_helpdesk_backend/internal/repository"
 	"unila_helpdesk_backend/internal/util"
+
+	"gorm.io/gorm"
 )
 
 type TicketService struct {
@@ -24,16 +27,24 @@ type TicketService struct {
 	tokens        *repository.FCMTokenRepository
 	attachments   *repository.AttachmentRepository
 	fcmClient     *fcm.Client
+	audit         *AuditService
+	staffNotifier StaffNotifier
 	initialStatus domain.TicketStatus
 	now           func() time.Time
 }
 
+// StaffNotifier memberi tahu admin tentang tiket baru, mis. lewat Web Push.
+type StaffNotifier interface {
+	NotifyNewTicket(ticket domain.Ticket, category domain.ServiceCategory)
+}
+
 type TicketCreateRequest struct {
-	Title       string                `json:"title"`
-	Description string                `json:"description"`
-	Category    string                `json:"category"`
-	Priority    domain.TicketPriority `json:"priority"`
-	Attachments []string              `json:"attachments"`
+	Title        string                `json:"title"`
+	Description  string                `json:"description"`
+	Category     string                `json:"category"`
+	Priority     domain.TicketPriority `json:"priority"`
+	Attachments  []string              `json:"attachments"`
+	CustomFields map[string]any        `json:"customFields"`
 }
 
 type GuestTicketCreateRequest struct {
@@ -43,6 +54,7 @@ type GuestTicketCreateRequest struct {
 	Priority     domain.TicketPriority `json:"priority"`
 	Attachments  []string              `json:"attachments"`
 	ReporterName string                `json:"reporter_name"`
+	CustomFields map[string]any        `json:"customFields"`
 }
 
 type TicketUpdateRequest struct {
@@ -52,6 +64,8 @@ type TicketUpdateRequest struct {
 	Priority    *domain.TicketPriority `json:"priority"`
 	Status      *domain.TicketStatus   `json:"status"`
 	Assignee    *string                `json:"assignee"`
+	// CustomFields menggantikan seluruh nilai field kustom jika dikirim.
+	CustomFields map[string]any `json:"customFields"`
 }
 
 func NewTicketService(
@@ -61,6 +75,7 @@ func NewTicketService(
 	tokens *repository.FCMTokenRepository,
 	attachments *repository.AttachmentRepository,
 	fcmClient *fcm.Client,
+	audit *AuditService,
 	initialStatus domain.TicketStatus,
 ) *TicketService {
 	return &TicketService{
@@ -70,22 +85,30 @@ func NewTicketService(
 		tokens:        tokens,
 		attachments:   attachments,
 		fcmClient:     fcmClient,
+		audit:         audit,
 		initialStatus: normalizeInitialTicketStatus(initialStatus),
 		now:           time.Now,
 	}
 }
 
+// SetStaffNotifier memasang notifikasi admin untuk tiket baru. Dipanggil sekali saat startup.
+func (service *TicketService) SetStaffNotifier(notifier StaffNotifier) {
+	service.staffNotifier = notifier
+}
+
 type ticketCoreParams struct {
 	title          string
 	description    string
 	category       string
 	priority       domain.TicketPriority
 	attachments    []string
+	customFields   map[string]any
 	reporterID     string
 	reporterName   string
 	isGuest        bool
 	surveyEligible bool
 	historyNote    string
+	actor          *domain.User
 }
 
 func (service *TicketService) createTicketCore(params ticketCoreParams) (domain.Ticket, *domain.ServiceCategory, error) {
@@ -101,10 +124,18 @@ func (service *TicketService) createTicketCore(params ticketCoreParams) (domain.
 		return domain.Ticket{}, nil, err
 	}
 
+	if err := service.ensureLeafCategory(category); err != nil {
+		return domain.Ticket{}, nil, err
+	}
 	if params.isGuest && !category.GuestAllowed {
 		return domain.Ticket{}, nil, errors.New("guest hanya dapat membuat tiket kategori guest")
 	}
 
+	customFields, err := normalizeCustomFields(category.Fields, params.customFields)
+	if err != nil {
+		return domain.Ticket{}, nil, err
+	}
+
 	priority := params.priority
 	if priority == "" {
 		priority = domain.PriorityMedium
@@ -117,19 +148,23 @@ func (service *TicketService) createTicketCore(params ticketCoreParams) (domain.
 			return domain.Ticket{}, nil, err
 		}
 
+		createdAt := service.now()
+		dueAt := createdAt.Add(domain.SLATarget(priority))
 		ticket := domain.Ticket{
 			ID:             ticketID,
 			Title:          strings.TrimSpace(params.title),
 			Description:    strings.TrimSpace(params.description),
 			CategoryID:     category.ID,
 			Priority:       priority,
+			CustomFields:   marshalCustomFields(customFields),
 			Status:         service.initialStatus,
 			ReporterID:     params.reporterID,
 			ReporterName:   params.reporterName,
 			IsGuest:        params.isGuest,
 			SurveyRequired: params.surveyEligible && !params.isGuest && service.initialStatus == domain.StatusResolved,
-			CreatedAt:      service.now(),
-			UpdatedAt:      service.now(),
+			DueAt:          &dueAt,
+			CreatedAt:      createdAt,
+			UpdatedAt:      createdAt,
 		}
 		if payload := marshalAttachments(params.attachments); payload != nil {
 			ticket.Attachments = payload
@@ -143,8 +178,11 @@ func (service *TicketService) createTicketCore(params ticketCoreParams) (domain.
 		}
 
 		_ = service.attachments.AttachToTicket(attachmentIDsFromRefs(params.attachments), ticket.ID)
-		_ = service.addHistory(ticket.ID, "Ticket Created", params.historyNote)
-		_ = service.addHistory(ticket.ID, "Status Updated", fmt.Sprintf("Status diperbarui ke %s", ticket.Status))
+		_ = service.addHistory(ticket.ID, params.actor, "Ticket Created", params.historyNote)
+		_ = service.addHistory(ticket.ID, params.actor, "Status Updated", fmt.Sprintf("Status diperbarui ke %s", ticket.Status))
+		if service.staffNotifier != nil {
+			go service.staffNotifier.NotifyNewTicket(ticket, *category)
+		}
 		return ticket, category, nil
 	}
 
@@ -158,15 +196,24 @@ func (service *TicketService) CreateTicket(ctx context.Context, user domain.User
 		category:       req.Category,
 		priority:       req.Priority,
 		attachments:    req.Attachments,
+		customFields:   req.CustomFields,
 		reporterID:     user.ID,
 		reporterName:   user.Name,
 		isGuest:        user.Role == domain.RoleGuest,
 		surveyEligible: user.Role == domain.RoleRegistered,
 		historyNote:    "Dilaporkan oleh pengguna",
+		actor:          &user,
 	})
 	if err != nil {
 		return domain.TicketDTO{}, err
 	}
+	service.audit.Record(ctx, AuditEntry{
+		Actor:      &user,
+		Action:     AuditActionTicketCreate,
+		EntityType: AuditEntityTicket,
+		EntityID:   ticket.ID,
+		After:      ticketAuditSnapshot(ticket),
+	})
 
 	if !ticket.IsGuest {
 		if err := service.notifyTicketStatus(
@@ -194,6 +241,7 @@ func (service *TicketService) CreateGuestTicket(ctx context.Context, req GuestTi
 		category:     req.Category,
 		priority:     req.Priority,
 		attachments:  req.Attachments,
+		customFields: req.CustomFields,
 		reporterID:   "",
 		reporterName: reporterName,
 		isGuest:      true,
@@ -202,6 +250,12 @@ func (service *TicketService) CreateGuestTicket(ctx context.Context, req GuestTi
 	if err != nil {
 		return domain.TicketDTO{}, err
 	}
+	service.audit.Record(ctx, AuditEntry{
+		Action:     AuditActionTicketCreate,
+		EntityType: AuditEntityTicket,
+		EntityID:   ticket.ID,
+		After:      ticketAuditSnapshot(ticket),
+	})
 
 	return service.toTicketDTO(ticket, *category, 0), nil
 }
@@ -220,13 +274,24 @@ func (service *TicketService) UpdateTicket(ctx context.Context, user domain.User
 	if user.Role != domain.RoleAdmin && (ticket.Status == domain.StatusResolved) {
 		return domain.TicketDTO{}, errors.New("tiket yang sudah selesai tidak dapat diedit")
 	}
+	before := ticketAuditSnapshot(*ticket)
+	changes := make([]domain.TicketFieldChange, 0)
 
 	if req.Title != nil {
-		ticket.Title = strings.TrimSpace(*req.Title)
+		title := strings.TrimSpace(*req.Title)
+		if title != ticket.Title {
+			changes = append(changes, ticketFieldChange(ticketFieldTitle, ticket.Title, title, "", ""))
+			ticket.Title = title
+		}
 	}
 	if req.Description != nil {
-		ticket.Description = strings.TrimSpace(*req.Description)
+		description := strings.TrimSpace(*req.Description)
+		if description != ticket.Description {
+			changes = append(changes, ticketFieldChange(ticketFieldDescription, ticket.Description, description, "", ""))
+			ticket.Description = description
+		}
 	}
+	categoryChanged := false
 	if req.Category != nil {
 		category, err := service.resolveCategory(*req.Category)
 		if err != nil {
@@ -235,32 +300,82 @@ func (service *TicketService) UpdateTicket(ctx context.Context, user domain.User
 		if user.Role == domain.RoleGuest && !category.GuestAllowed {
 			return domain.TicketDTO{}, errors.New("guest hanya dapat membuat tiket kategori guest")
 		}
+		if category.ID != ticket.CategoryID {
+			if err := service.ensureLeafCategory(category); err != nil {
+				return domain.TicketDTO{}, err
+			}
+			categoryChanged = true
+			changes = append(changes, ticketFieldChange(
+				ticketFieldCategory,
+				ticket.CategoryID,
+				category.ID,
+				ticket.Category.Name,
+				category.Name,
+			))
+		}
 		ticket.CategoryID = category.ID
 		ticket.Category = *category
 	}
-	if req.Priority != nil {
+	if req.CustomFields != nil || categoryChanged {
+		customFields, err := service.resolveCustomFields(ticket, req.CustomFields)
+		if err != nil {
+			return domain.TicketDTO{}, err
+		}
+		// Isi jsonb dibandingkan setelah didecode; Postgres menyimpan ulang JSON
+		// dengan urutan key dan spasi yang berbeda dari json.Marshal.
+		if !reflect.DeepEqual(decodeCustomFields(customFields), decodeCustomFields(ticket.CustomFields)) {
+			changes = append(changes, ticketFieldChange(
+				ticketFieldCustom,
+				string(ticket.CustomFields),
+				string(customFields),
+				"",
+				"",
+			))
+			ticket.CustomFields = customFields
+		}
+	}
+	if req.Priority != nil && *req.Priority != ticket.Priority {
+		changes = append(changes, ticketFieldChange(
+			ticketFieldPriority,
+			string(ticket.Priority),
+			string(*req.Priority),
+			priorityLabel(ticket.Priority),
+			priorityLabel(*req.Priority),
+		))
 		ticket.Priority = *req.Priority
+		dueAt := ticket.CreatedAt.Add(domain.SLATarget(ticket.Priority))
+		ticket.DueAt = &dueAt
+		ticket.SLAWarnedAt = nil
 	}
 
 	statusChanged := false
 	previousStatus := ticket.Status
-	historyTitle := "Ticket Updated"
-	historyDesc := "Perubahan tiket diperbarui"
 
 	// User biasa tidak bisa mengubah status, assignee - hanya admin
 	if user.Role == domain.RoleAdmin {
 		if req.Status != nil && ticket.Status != *req.Status {
+			changes = append(changes, ticketFieldChange(
+				ticketFieldStatus,
+				string(ticket.Status),
+				string(*req.Status),
+				statusLabel(ticket.Status),
+				statusLabel(*req.Status),
+			))
 			ticket.Status = *req.Status
 			statusChanged = true
 		}
 		if req.Assignee != nil {
-			ticket.Assignee = strings.TrimSpace(*req.Assignee)
+			assignee := strings.TrimSpace(*req.Assignee)
+			if assignee != ticket.Assignee {
+				changes = append(changes, ticketFieldChange(ticketFieldAssignee, ticket.Assignee, assignee, "", ""))
+				ticket.Assignee = assignee
+			}
 		}
 	}
 
-	if statusChanged {
+	historyTitle := "Ticket Updated"
+	if statusChanged && len(changes) == 1 {
 		historyTitle = "Status Updated"
-		historyDesc = fmt.Sprintf("Status diperbarui dari %s ke %s", statusLabel(previousStatus), statusLabel(ticket.Status))
 	}
 
 	ticket.UpdatedAt = service.now()
@@ -268,9 +383,19 @@ func (service *TicketService) UpdateTicket(ctx context.Context, user domain.User
 		return domain.TicketDTO{}, err
 	}
 
-	if err := service.addHistory(ticket.ID, historyTitle, historyDesc); err != nil {
-		log.Printf("failed to add ticket history: %v", err)
+	if len(changes) > 0 {
+		if err := service.addChangeHistory(ticket.ID, user, historyTitle, changes); err != nil {
+			log.Printf("failed to add ticket history: %v", err)
+		}
 	}
+	service.audit.Record(ctx, AuditEntry{
+		Actor:      &user,
+		Action:     AuditActionTicketUpdate,
+		EntityType: AuditEntityTicket,
+		EntityID:   ticket.ID,
+		Before:     before,
+		After:      ticketAuditSnapshot(*ticket),
+	})
 
 	if statusChanged {
 		surveyRequired := ticket.Status == domain.StatusResolved && !ticket.IsGuest
@@ -287,7 +412,7 @@ func (service *TicketService) UpdateTicket(ctx context.Context, user domain.User
 	return service.toTicketDTO(*ticket, ticket.Category, 0), nil
 }
 
-func (service *TicketService) DeleteTicket(user domain.User, ticketID string) error {
+func (service *TicketService) DeleteTicket(ctx context.Context, user domain.User, ticketID string, reason string) error {
 	ticket, err := service.tickets.FindByID(ticketID)
 	if err != nil {
 		return err
@@ -295,7 +420,101 @@ func (service *TicketService) DeleteTicket(user domain.User, ticketID string) er
 	if user.Role != domain.RoleAdmin && ticket.ReporterID != user.ID {
 		return errors.New("tidak memiliki akses untuk menghapus tiket ini")
 	}
-	return service.tickets.SoftDelete(ticketID)
+	reason = strings.TrimSpace(reason)
+	if err := service.tickets.SoftDelete(ticketID, user.ID, user.Name, reason); err != nil {
+		return err
+	}
+	description := fmt.Sprintf("Tiket dihapus oleh %s", user.Name)
+	if reason != "" {
+		description += ": " + reason
+	}
+	if err := service.addHistory(ticketID, &user, "Ticket Deleted", description); err != nil {
+		log.Printf("failed to add ticket history: %v", err)
+	}
+	service.audit.Record(ctx, AuditEntry{
+		Actor:      &user,
+		Action:     AuditActionTicketDelete,
+		EntityType: AuditEntityTicket,
+		EntityID:   ticketID,
+		Before:     ticketAuditSnapshot(*ticket),
+		After:      map[string]any{"reason": reason},
+	})
+	return nil
+}
+
+func (service *TicketService) ListDeletedTickets(query string, page int, limit int) (domain.TicketPageDTO, error) {
+	if limit <= 0 {
+		limit = 15
+	}
+	if limit > 50 {
+		limit = 50
+	}
+	if page < 1 {
+		page = 1
+	}
+	tickets, total, err := service.tickets.ListDeleted(query, page, limit)
+	if err != nil {
+		return domain.TicketPageDTO{}, err
+	}
+	scores, err := service.tickets.GetSurveyScores(ticketIDs(tickets))
+	if err != nil {
+		return domain.TicketPageDTO{}, err
+	}
+	return domain.TicketPageDTO{
+		Items:      service.mapTickets(tickets, scores),
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: util.CalcTotalPages(total, limit),
+	}, nil
+}
+
+func (service *TicketService) RestoreTicket(ctx context.Context, user domain.User, ticketID string) (domain.TicketDTO, error) {
+	deleted, err := service.tickets.FindDeletedByID(ticketID)
+	if err != nil {
+		return domain.TicketDTO{}, errors.New("tiket tidak ditemukan di trash")
+	}
+	if err := service.tickets.Restore(ticketID); err != nil {
+		return domain.TicketDTO{}, err
+	}
+	if err := service.addHistory(ticketID, &user, "Ticket Restored", fmt.Sprintf("Tiket dipulihkan oleh %s", user.Name)); err != nil {
+		log.Printf("failed to add ticket history: %v", err)
+	}
+	service.audit.Record(ctx, AuditEntry{
+		Actor:      &user,
+		Action:     AuditActionTicketRestore,
+		EntityType: AuditEntityTicket,
+		EntityID:   ticketID,
+		Before:     map[string]any{"deletedBy": deleted.DeletedByName, "reason": deleted.DeleteReason},
+	})
+	return service.GetTicket(&user, ticketID)
+}
+
+// ErrTicketNotInTrash dikembalikan PurgeTicket jika tiket tidak ada di trash.
+var ErrTicketNotInTrash = errors.New("tiket tidak ditemukan di trash")
+
+func (service *TicketService) PurgeTicket(ctx context.Context, user domain.User, ticketID string) error {
+	deleted, err := service.tickets.FindDeletedByID(ticketID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTicketNotInTrash
+		}
+		return err
+	}
+	if err := service.tickets.Purge(ticketID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTicketNotInTrash
+		}
+		return err
+	}
+	service.audit.Record(ctx, AuditEntry{
+		Actor:      &user,
+		Action:     AuditActionTicketPurge,
+		EntityType: AuditEntityTicket,
+		EntityID:   ticketID,
+		Before:     ticketAuditSnapshot(*deleted),
+	})
+	return nil
 }
 
 func (service *TicketService) GetTicket(user *domain.User, ticketID string) (domain.TicketDTO, error) {
@@ -341,6 +560,7 @@ func (service *TicketService) ListTickets(user domain.User) ([]domain.TicketDTO,
 func (service *TicketService) ListTicketsPaged(
 	user domain.User,
 	filter repository.TicketListFilter,
+	sort repository.TicketListSort,
 	page int,
 	limit int,
 ) (domain.TicketPageDTO, error) {
@@ -358,7 +578,7 @@ func (service *TicketService) ListTicketsPaged(
 		filter.ReporterID = user.ID
 	}
 
-	tickets, total, err := service.tickets.ListFiltered(filter, page, limit)
+	tickets, total, err := service.tickets.ListFiltered(filter, sort, page, limit)
 	if err != nil {
 		return domain.TicketPageDTO{}, err
 	}
@@ -376,6 +596,53 @@ func (service *TicketService) ListTicketsPaged(
 	}, nil
 }
 
+// ListTicketsCursor mengembalikan halaman tiket berbasis cursor (created_at, id).
+// Total tidak dihitung agar tetap cepat dan stabil saat tiket baru masuk.
+func (service *TicketService) ListTicketsCursor(
+	user domain.User,
+	filter repository.TicketListFilter,
+	asc bool,
+	rawCursor string,
+	limit int,
+) (domain.TicketPageDTO, error) {
+	if limit <= 0 {
+		limit = 15
+	}
+	if limit > 50 {
+		limit = 50
+	}
+
+	if user.Role != domain.RoleAdmin {
+		filter.ReporterID = user.ID
+	}
+
+	cursor, err := util.DecodeCursor(rawCursor)
+	if err != nil {
+		return domain.TicketPageDTO{}, err
+	}
+	tickets, hasMore, err := service.tickets.ListFilteredCursor(filter, asc, cursor, limit)
+	if err != nil {
+		return domain.TicketPageDTO{}, err
+	}
+	scores, err := service.tickets.GetSurveyScores(ticketIDs(tickets))
+	if err != nil {
+		return domain.TicketPageDTO{}, err
+	}
+
+	var first, last util.Cursor
+	if len(tickets) > 0 {
+		first = util.Cursor{CreatedAt: tickets[0].CreatedAt, ID: tickets[0].ID}
+		last = util.Cursor{CreatedAt: tickets[len(tickets)-1].CreatedAt, ID: tickets[len(tickets)-1].ID}
+	}
+	next, prev := cursorLinks(cursor, hasMore, len(tickets), first, last)
+	return domain.TicketPageDTO{
+		Items:      service.mapTickets(tickets, scores),
+		Limit:      limit,
+		NextCursor: next,
+		PrevCursor: prev,
+	}, nil
+}
+
 func (service *TicketService) SearchTickets(query string, guestOnly bool) ([]domain.TicketDTO, error) {
 	tickets, err := service.tickets.Search(query, guestOnly)
 	if err != nil {
@@ -388,16 +655,29 @@ func (service *TicketService) SearchTickets(query string, guestOnly bool) ([]dom
 	return service.mapTickets(tickets, scores), nil
 }
 
-func (service *TicketService) AddComment(user domain.User, ticketID string, message string) (domain.TicketDTO, error) {
+func (service *TicketService) AddComment(ctx context.Context, user domain.User, ticketID string, message string) (domain.TicketDTO, error) {
+	ticket, _, err := service.addComment(ctx, user, ticketID, message)
+	if err != nil {
+		return domain.TicketDTO{}, err
+	}
+	return service.toTicketDTO(*ticket, ticket.Category, 0), nil
+}
+
+func (service *TicketService) addComment(
+	ctx context.Context,
+	user domain.User,
+	ticketID string,
+	message string,
+) (*domain.Ticket, domain.TicketComment, error) {
 	if strings.TrimSpace(message) == "" {
-		return domain.TicketDTO{}, errors.New("komentar tidak boleh kosong")
+		return nil, domain.TicketComment{}, errors.New("komentar tidak boleh kosong")
 	}
 	ticket, err := service.tickets.FindByID(ticketID)
 	if err != nil {
-		return domain.TicketDTO{}, err
+		return nil, domain.TicketComment{}, err
 	}
 	if user.Role != domain.RoleAdmin && ticket.ReporterID != user.ID {
-		return domain.TicketDTO{}, errors.New("tidak memiliki akses untuk menambah komentar")
+		return nil, domain.TicketComment{}, errors.New("tidak memiliki akses untuk menambah komentar")
 	}
 
 	comment := domain.TicketComment{
@@ -409,9 +689,16 @@ func (service *TicketService) AddComment(user domain.User, ticketID string, mess
 		Timestamp: service.now(),
 	}
 	if err := service.tickets.AddComment(&comment); err != nil {
-		return domain.TicketDTO{}, err
-	}
-	return service.toTicketDTO(*ticket, ticket.Category, 0), nil
+		return nil, domain.TicketComment{}, err
+	}
+	service.audit.Record(ctx, AuditEntry{
+		Actor:      &user,
+		Action:     AuditActionTicketComment,
+		EntityType: AuditEntityTicket,
+		EntityID:   ticket.ID,
+		After:      map[string]any{"commentId": comment.ID, "isStaff": comment.IsStaff},
+	})
+	return ticket, comment, nil
 }
 
 func (service *TicketService) resolveCategory(value string) (*domain.ServiceCategory, error) {
@@ -429,6 +716,21 @@ func (service *TicketService) resolveCategory(value string) (*domain.ServiceCate
 	return category, nil
 }
 
+// ensureLeafCategory memastikan tiket hanya diajukan pada kategori aktif paling spesifik.
+func (service *TicketService) ensureLeafCategory(category *domain.ServiceCategory) error {
+	if category.ArchivedAt != nil {
+		return errors.New("kategori sudah tidak aktif")
+	}
+	hasChildren, err := service.categories.HasActiveChildren(category.ID)
+	if err != nil {
+		return err
+	}
+	if hasChildren {
+		return fmt.Errorf("pilih sub-kategori dari %s", category.Name)
+	}
+	return nil
+}
+
 func (service *TicketService) generateTicketID() (string, error) {
 	year := service.now().Year()
 	sequence, err := service.tickets.NextTicketSequence(year)
@@ -441,19 +743,21 @@ func (service *TicketService) generateTicketID() (string, error) {
 func (service *TicketService) toTicketDTO(ticket domain.Ticket, category domain.ServiceCategory, surveyScore float64) domain.TicketDTO {
 	history := make([]domain.TicketHistoryDTO, 0, len(ticket.History))
 	for _, item := range ticket.History {
-		history = append(history, domain.TicketHistoryDTO{
-			Title:       item.Title,
-			Description: item.Description,
-			Timestamp:   item.Timestamp,
-		})
+		history = append(history, toTicketHistoryDTO(item))
 	}
 	comments := make([]domain.TicketCommentDTO, 0, len(ticket.Comments))
 	for _, item := range ticket.Comments {
+		articles := make([]domain.ArticleRefDTO, 0, len(item.Articles))
+		for _, article := range item.Articles {
+			articles = append(articles, domain.ArticleRefDTO{ID: article.ID, Slug: article.Slug, Title: article.Title})
+		}
 		comments = append(comments, domain.TicketCommentDTO{
+			ID:        item.ID,
 			Author:    item.Author,
 			Message:   item.Message,
 			Timestamp: item.Timestamp,
 			IsStaff:   item.IsStaff,
+			Articles:  articles,
 		})
 	}
 
@@ -471,7 +775,7 @@ func (service *TicketService) toTicketDTO(ticket domain.Ticket, category domain.
 	if len(ticket.Attachments) > 0 {
 		_ = json.Unmarshal(ticket.Attachments, &attachments)
 	}
-	return domain.TicketDTO{
+	dto := domain.TicketDTO{
 		ID:             ticket.ID,
 		Title:          ticket.Title,
 		Description:    ticket.Description,
@@ -480,15 +784,26 @@ func (service *TicketService) toTicketDTO(ticket domain.Ticket, category domain.
 		Status:         ticket.Status,
 		Priority:       ticket.Priority,
 		CreatedAt:      ticket.CreatedAt,
+		UpdatedAt:      ticket.UpdatedAt,
+		DueAt:          ticket.DueAt,
 		Reporter:       ticket.ReporterName,
 		IsGuest:        ticket.IsGuest,
 		Assignee:       ticket.Assignee,
 		Attachments:    attachments,
+		CustomFields:   decodeCustomFields(ticket.CustomFields),
+		Tags:           tagNames(ticket.Tags),
 		History:        history,
 		Comments:       comments,
 		SurveyRequired: ticket.SurveyRequired,
 		SurveyScore:    surveyScore,
 	}
+	if ticket.DeletedAt.Valid {
+		deletedAt := ticket.DeletedAt.Time
+		dto.DeletedAt = &deletedAt
+		dto.DeletedBy = ticket.DeletedByName
+		dto.DeleteReason = ticket.DeleteReason
+	}
+	return dto
 }
 
 func marshalAttachments(values []string) []byte {
@@ -606,16 +921,6 @@ func ticketIDs(tickets []domain.Ticket) []string {
 	return ids
 }
 
-func (service *TicketService) addHistory(ticketID, title, description string) error {
-	return service.tickets.AddHistory(&domain.TicketHistory{
-		ID:          util.NewUUID(),
-		TicketID:    ticketID,
-		Title:       title,
-		Description: description,
-		Timestamp:   service.now(),
-	})
-}
-
 func (service *TicketService) notifyTicketStatus(ctx context.Context, ticket domain.Ticket, title string, message string) error {
 	if strings.TrimSpace(ticket.ReporterID) == "" {
 		return nil
@@ -642,28 +947,19 @@ func (service *TicketService) notifyTicketStatus(ctx context.Context, ticket dom
 	for _, token := range tokens {
 		tokenValues = append(tokenValues, token.Token)
 	}
-	invalidTokens, sendErr := service.fcmClient.SendToTokens(ctx, tokenValues, title, message, map[string]string{
+	report, sendErr := service.fcmClient.SendToTokens(ctx, tokenValues, title, message, map[string]string{
 		"ticket_id": ticket.ID,
 		"title":     title,
 		"body":      message,
 	})
-	if len(invalidTokens) > 0 {
-		unique := make([]string, 0, len(invalidTokens))
-		seen := make(map[string]struct{}, len(invalidTokens))
-		for _, token := range invalidTokens {
-			if token == "" {
-				continue
-			}
-			if _, ok := seen[token]; ok {
-				continue
-			}
-			seen[token] = struct{}{}
-			unique = append(unique, token)
-		}
-		if err := service.tokens.DeleteByUserAndTokens(ticket.ReporterID, unique); err != nil {
-			log.Printf("failed to delete invalid fcm tokens user=%s count=%d: %v", ticket.ReporterID, len(unique), err)
+	if sendErr == nil {
+		sendErr = report.Err()
+	}
+	if invalid := report.InvalidTokens(); len(invalid) > 0 {
+		if err := service.tokens.DeleteByUserAndTokens(ticket.ReporterID, invalid); err != nil {
+			log.Printf("failed to delete invalid fcm tokens user=%s count=%d: %v", ticket.ReporterID, len(invalid), err)
 		} else {
-			log.Printf("deleted invalid fcm tokens user=%s count=%d", ticket.ReporterID, len(unique))
+			log.Printf("deleted invalid fcm tokens user=%s count=%d", ticket.ReporterID, len(invalid))
 		}
 	}
 	return sendErr
@@ -0,0 +1,257 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"unila_helpdesk_backend/internal/domain"
+	"unila_helpdesk_backend/internal/repository"
+	"unila_helpdesk_backend/internal/util"
+
+	"gorm.io/gorm"
+)
+
+type TicketViewService struct {
+	views   *repository.TicketViewRepository
+	tickets *repository.TicketRepository
+	listing *TicketService
+	now     func() time.Time
+}
+
+var (
+	ErrTicketViewNotFound  = errors.New("view tidak ditemukan")
+	ErrTicketViewForbidden = errors.New("hanya pemilik yang dapat mengubah view ini")
+	// ErrTicketViewCursorSort dikembalikan saat cursor dipakai pada view yang tidak
+	// diurutkan berdasarkan waktu dibuat.
+	ErrTicketViewCursorSort = errors.New("cursor hanya mendukung sort created")
+)
+
+type TicketViewRequest struct {
+	Name   string                      `json:"name"`
+	Shared bool                        `json:"shared"`
+	Filter repository.TicketListFilter `json:"filter"`
+	Sort   repository.TicketListSort   `json:"sort"`
+}
+
+func NewTicketViewService(
+	views *repository.TicketViewRepository,
+	tickets *repository.TicketRepository,
+	listing *TicketService,
+) *TicketViewService {
+	return &TicketViewService{
+		views:   views,
+		tickets: tickets,
+		listing: listing,
+		now:     time.Now,
+	}
+}
+
+// List mengembalikan view yang terlihat oleh admin beserta jumlah tiket
+// yang cocok saat ini untuk ditampilkan di sidebar.
+func (service *TicketViewService) List(user domain.User) ([]domain.TicketViewDTO, error) {
+	views, err := service.views.ListVisible(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]domain.TicketViewDTO, 0, len(views))
+	for _, view := range views {
+		filter, _, err := decodeTicketView(view)
+		if err != nil {
+			return nil, err
+		}
+		count, err := service.tickets.CountFiltered(filter)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, toTicketViewDTO(view, count))
+	}
+	return result, nil
+}
+
+func (service *TicketViewService) Create(user domain.User, req TicketViewRequest) (domain.TicketViewDTO, error) {
+	view := domain.TicketView{
+		ID:        util.NewUUID(),
+		OwnerID:   user.ID,
+		CreatedAt: service.now(),
+	}
+	if err := service.apply(&view, req); err != nil {
+		return domain.TicketViewDTO{}, err
+	}
+	if err := service.views.Create(&view); err != nil {
+		return domain.TicketViewDTO{}, err
+	}
+	return service.withCount(view)
+}
+
+func (service *TicketViewService) Update(user domain.User, viewID string, req TicketViewRequest) (domain.TicketViewDTO, error) {
+	view, err := service.findOwned(user, viewID)
+	if err != nil {
+		return domain.TicketViewDTO{}, err
+	}
+	if err := service.apply(view, req); err != nil {
+		return domain.TicketViewDTO{}, err
+	}
+	if err := service.views.Update(view); err != nil {
+		return domain.TicketViewDTO{}, err
+	}
+	return service.withCount(*view)
+}
+
+func (service *TicketViewService) Delete(user domain.User, viewID string) error {
+	if _, err := service.findOwned(user, viewID); err != nil {
+		return err
+	}
+	return service.views.Delete(viewID)
+}
+
+// Execute menjalankan filter tersimpan. Jika rawCursor tidak nil, halaman
+// diambil dengan mode cursor seperti pada /tickets/paged.
+func (service *TicketViewService) Execute(
+	user domain.User,
+	viewID string,
+	page int,
+	limit int,
+	rawCursor *string,
+) (domain.TicketPageDTO, error) {
+	view, err := service.findView(viewID)
+	if err != nil {
+		return domain.TicketPageDTO{}, err
+	}
+	if view.OwnerID != user.ID && !view.Shared {
+		return domain.TicketPageDTO{}, ErrTicketViewNotFound
+	}
+	filter, sort, err := decodeTicketView(*view)
+	if err != nil {
+		return domain.TicketPageDTO{}, err
+	}
+	if rawCursor != nil {
+		if sort.Field != repository.TicketSortCreated {
+			return domain.TicketPageDTO{}, ErrTicketViewCursorSort
+		}
+		return service.listing.ListTicketsCursor(user, filter, sort.Asc, *rawCursor, limit)
+	}
+	return service.listing.ListTicketsPaged(user, filter, sort, page, limit)
+}
+
+func (service *TicketViewService) findOwned(user domain.User, viewID string) (*domain.TicketView, error) {
+	view, err := service.findView(viewID)
+	if err != nil {
+		return nil, err
+	}
+	if view.OwnerID != user.ID {
+		return nil, ErrTicketViewForbidden
+	}
+	return view, nil
+}
+
+func (service *TicketViewService) findView(viewID string) (*domain.TicketView, error) {
+	view, err := service.views.FindByID(viewID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTicketViewNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return view, nil
+}
+
+func (service *TicketViewService) apply(view *domain.TicketView, req TicketViewRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return errors.New("nama view wajib diisi")
+	}
+	if err := validateTicketViewFilter(req.Filter, req.Sort); err != nil {
+		return err
+	}
+	if req.Sort.Field == "" {
+		req.Sort.Field = repository.TicketSortCreated
+	}
+	filterPayload, err := json.Marshal(req.Filter)
+	if err != nil {
+		return err
+	}
+	sortPayload, err := json.Marshal(req.Sort)
+	if err != nil {
+		return err
+	}
+	view.Name = name
+	view.Shared = req.Shared
+	view.Filter = filterPayload
+	view.Sort = sortPayload
+	view.UpdatedAt = service.now()
+	return nil
+}
+
+func (service *TicketViewService) withCount(view domain.TicketView) (domain.TicketViewDTO, error) {
+	filter, _, err := decodeTicketView(view)
+	if err != nil {
+		return domain.TicketViewDTO{}, err
+	}
+	count, err := service.tickets.CountFiltered(filter)
+	if err != nil {
+		return domain.TicketViewDTO{}, err
+	}
+	return toTicketViewDTO(view, count), nil
+}
+
+func validateTicketViewFilter(filter repository.TicketListFilter, sort repository.TicketListSort) error {
+	for _, status := range filter.Statuses {
+		switch status {
+		case domain.StatusWaiting, domain.StatusInProgress, domain.StatusResolved:
+		default:
+			return errors.New("status tiket tidak valid")
+		}
+	}
+	for _, priority := range filter.Priorities {
+		switch priority {
+		case domain.PriorityLow, domain.PriorityMedium, domain.PriorityHigh:
+		default:
+			return errors.New("prioritas tiket tidak valid")
+		}
+	}
+	if filter.OlderThanHours < 0 {
+		return errors.New("olderThanHours tidak valid")
+	}
+	switch sort.Field {
+	case "", repository.TicketSortCreated, repository.TicketSortUpdated,
+		repository.TicketSortPriority, repository.TicketSortDue:
+	default:
+		return errors.New("sort tidak valid")
+	}
+	return nil
+}
+
+func decodeTicketView(view domain.TicketView) (repository.TicketListFilter, repository.TicketListSort, error) {
+	var filter repository.TicketListFilter
+	sort := repository.TicketListSort{Field: repository.TicketSortCreated}
+	if len(view.Filter) > 0 {
+		if err := json.Unmarshal(view.Filter, &filter); err != nil {
+			return filter, sort, err
+		}
+	}
+	if len(view.Sort) > 0 {
+		if err := json.Unmarshal(view.Sort, &sort); err != nil {
+			return filter, sort, err
+		}
+	}
+	if sort.Field == "" {
+		sort.Field = repository.TicketSortCreated
+	}
+	return filter, sort, nil
+}
+
+func toTicketViewDTO(view domain.TicketView, count int64) domain.TicketViewDTO {
+	return domain.TicketViewDTO{
+		ID:        view.ID,
+		Name:      view.Name,
+		OwnerID:   view.OwnerID,
+		Shared:    view.Shared,
+		Filter:    view.Filter,
+		Sort:      view.Sort,
+		Count:     count,
+		CreatedAt: view.CreatedAt,
+		UpdatedAt: view.UpdatedAt,
+	}
+}
@@ -0,0 +1,459 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"unila_helpdesk_backend/internal/domain"
+	"unila_helpdesk_backend/internal/repository"
+	"unila_helpdesk_backend/internal/util"
+	"unila_helpdesk_backend/internal/webpush"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const (
+	webPushBackend = "webpush"
+	// slaWarningBatch membatasi jumlah tiket per putaran pemeriksaan SLA.
+	slaWarningBatch = 200
+)
+
+// WebPushService mengirim notifikasi browser (Web Push/VAPID) ke admin web console
+// untuk tiket baru dan peringatan SLA, terpisah dari FCM yang dipakai aplikasi mobile.
+type WebPushService struct {
+	store      *repository.WebPushRepository
+	users      *repository.UserRepository
+	tickets    *repository.TicketRepository
+	categories *repository.CategoryRepository
+	pushLogs   *repository.PushLogRepository
+	client     *webpush.Client
+	slaWindow  time.Duration
+	now        func() time.Time
+}
+
+type WebPushSubscribeRequest struct {
+	Endpoint string `json:"endpoint"`
+	Keys     struct {
+		P256dh string `json:"p256dh"`
+		Auth   string `json:"auth"`
+	} `json:"keys"`
+}
+
+type WebPushPreferenceRequest struct {
+	CategoryIDs []string `json:"categoryIds"`
+	NewTickets  *bool    `json:"newTickets"`
+	SLAWarnings *bool    `json:"slaWarnings"`
+}
+
+// webPushPayload dibaca service worker web console.
+type webPushPayload struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Body     string `json:"body"`
+	TicketID string `json:"ticketId,omitempty"`
+	URL      string `json:"url,omitempty"`
+	Tag      string `json:"tag,omitempty"`
+}
+
+func NewWebPushService(
+	store *repository.WebPushRepository,
+	users *repository.UserRepository,
+	tickets *repository.TicketRepository,
+	categories *repository.CategoryRepository,
+	pushLogs *repository.PushLogRepository,
+	client *webpush.Client,
+	slaWindow time.Duration,
+) *WebPushService {
+	if slaWindow <= 0 {
+		slaWindow = 2 * time.Hour
+	}
+	return &WebPushService{
+		store:      store,
+		users:      users,
+		tickets:    tickets,
+		categories: categories,
+		pushLogs:   pushLogs,
+		client:     client,
+		slaWindow:  slaWindow,
+		now:        time.Now,
+	}
+}
+
+func (service *WebPushService) Enabled() bool {
+	return service.client != nil
+}
+
+func (service *WebPushService) PublicKey() string {
+	return service.client.PublicKey()
+}
+
+func (service *WebPushService) Subscribe(user domain.User, req WebPushSubscribeRequest, userAgent string) (domain.WebPushSubscriptionDTO, error) {
+	if !service.Enabled() {
+		return domain.WebPushSubscriptionDTO{}, errors.New("web push belum dikonfigurasi")
+	}
+	endpoint := strings.TrimSpace(req.Endpoint)
+	parsed, err := url.Parse(endpoint)
+	if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return domain.WebPushSubscriptionDTO{}, errors.New("endpoint harus berupa URL https")
+	}
+	p256dh := strings.TrimSpace(req.Keys.P256dh)
+	auth := strings.TrimSpace(req.Keys.Auth)
+	if p256dh == "" || auth == "" {
+		return domain.WebPushSubscriptionDTO{}, errors.New("keys p256dh dan auth wajib diisi")
+	}
+	if len(p256dh) > 120 || len(auth) > 40 {
+		return domain.WebPushSubscriptionDTO{}, errors.New("keys tidak valid")
+	}
+
+	now := service.now()
+	subscription := domain.WebPushSubscription{
+		ID:        util.NewUUID(),
+		UserID:    user.ID,
+		Endpoint:  endpoint,
+		P256dh:    p256dh,
+		Auth:      auth,
+		UserAgent: truncateString(userAgent, 255),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := service.store.UpsertSubscription(&subscription); err != nil {
+		return domain.WebPushSubscriptionDTO{}, err
+	}
+	return toWebPushSubscriptionDTO(subscription), nil
+}
+
+func (service *WebPushService) Unsubscribe(user domain.User, endpoint string) error {
+	endpoint = strings.TrimSpace(endpoint)
+	if endpoint == "" {
+		return errors.New("endpoint wajib diisi")
+	}
+	return service.store.DeleteByUserAndEndpoint(user.ID, endpoint)
+}
+
+func (service *WebPushService) ListSubscriptions(user domain.User) ([]domain.WebPushSubscriptionDTO, error) {
+	subscriptions, err := service.store.ListByUser(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]domain.WebPushSubscriptionDTO, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		result = append(result, toWebPushSubscriptionDTO(subscription))
+	}
+	return result, nil
+}
+
+func (service *WebPushService) GetPreference(user domain.User) (domain.WebPushPreferenceDTO, error) {
+	preference, err := service.store.FindPreference(user.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return toWebPushPreferenceDTO(defaultWebPushPreference(user.ID)), nil
+	}
+	if err != nil {
+		return domain.WebPushPreferenceDTO{}, err
+	}
+	return toWebPushPreferenceDTO(*preference), nil
+}
+
+// UpdatePreference mengganti daftar kategori yang dipantau. Daftar kosong berarti
+// semua kategori; kategori parent mencakup seluruh turunannya.
+func (service *WebPushService) UpdatePreference(user domain.User, req WebPushPreferenceRequest) (domain.WebPushPreferenceDTO, error) {
+	preference, err := service.store.FindPreference(user.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		defaults := defaultWebPushPreference(user.ID)
+		preference = &defaults
+	} else if err != nil {
+		return domain.WebPushPreferenceDTO{}, err
+	}
+
+	if req.CategoryIDs != nil {
+		categoryIDs := make([]string, 0, len(req.CategoryIDs))
+		for _, categoryID := range req.CategoryIDs {
+			categoryID = strings.TrimSpace(categoryID)
+			if categoryID == "" || slices.Contains(categoryIDs, categoryID) {
+				continue
+			}
+			if _, err := service.categories.FindByID(categoryID); err != nil {
+				return domain.WebPushPreferenceDTO{}, fmt.Errorf("kategori %s tidak ditemukan", categoryID)
+			}
+			categoryIDs = append(categoryIDs, categoryID)
+		}
+		payload, err := json.Marshal(categoryIDs)
+		if err != nil {
+			return domain.WebPushPreferenceDTO{}, err
+		}
+		preference.CategoryIDs = datatypes.JSON(payload)
+	}
+	if req.NewTickets != nil {
+		preference.NewTickets = *req.NewTickets
+	}
+	if req.SLAWarnings != nil {
+		preference.SLAWarnings = *req.SLAWarnings
+	}
+	preference.UpdatedAt = service.now()
+	if err := service.store.SavePreference(preference); err != nil {
+		return domain.WebPushPreferenceDTO{}, err
+	}
+	return toWebPushPreferenceDTO(*preference), nil
+}
+
+// NotifyNewTicket dipanggil TicketService setelah tiket dibuat. Berjalan di
+// goroutine terpisah sehingga kegagalan hanya dicatat di log.
+func (service *WebPushService) NotifyNewTicket(ticket domain.Ticket, category domain.ServiceCategory) {
+	if !service.Enabled() {
+		return
+	}
+	ctx := context.Background()
+	tree, err := service.categoryTree()
+	if err != nil {
+		log.Printf("webpush new ticket %s: %v", ticket.ID, err)
+		return
+	}
+	recipients, err := service.recipients(tree, ticket.CategoryID, func(preference domain.WebPushPreference) bool {
+		return preference.NewTickets
+	})
+	if err != nil {
+		log.Printf("webpush new ticket %s: %v", ticket.ID, err)
+		return
+	}
+	recipients = slices.DeleteFunc(recipients, func(userID string) bool { return userID == ticket.ReporterID })
+
+	categoryName := tree.path(category.ID)
+	if categoryName == "" {
+		categoryName = category.Name
+	}
+	service.deliver(ctx, recipients, webPushPayload{
+		Type:     "ticket.created",
+		Title:    fmt.Sprintf("Tiket baru %s", ticket.ID),
+		Body:     truncateString(fmt.Sprintf("%s · %s (%s)", ticket.Title, categoryName, priorityLabel(ticket.Priority)), 300),
+		TicketID: ticket.ID,
+		URL:      "/tickets/" + ticket.ID,
+		Tag:      "ticket-" + ticket.ID,
+	})
+}
+
+// CheckSLAWarnings mengirim peringatan untuk tiket yang akan atau sudah melewati
+// SLA lalu menandainya agar tidak diperingatkan ulang. Mengembalikan jumlah tiket.
+func (service *WebPushService) CheckSLAWarnings(ctx context.Context) (int, error) {
+	if !service.Enabled() {
+		return 0, nil
+	}
+	now := service.now()
+	// Tiket yang sudah lewat jatuh tempo lebih lama dari jendela (mis. backlog saat
+	// fitur pertama kali aktif) tidak diperingatkan agar admin tidak dibanjiri push.
+	tickets, err := service.tickets.ListSLAWarningCandidates(now.Add(-service.slaWindow), now.Add(service.slaWindow), slaWarningBatch)
+	if err != nil || len(tickets) == 0 {
+		return 0, err
+	}
+	tree, err := service.categoryTree()
+	if err != nil {
+		return 0, err
+	}
+
+	warned := make([]string, 0, len(tickets))
+	for _, ticket := range tickets {
+		warned = append(warned, ticket.ID)
+		recipients, err := service.recipients(tree, ticket.CategoryID, func(preference domain.WebPushPreference) bool {
+			return preference.SLAWarnings
+		})
+		if err != nil {
+			return 0, err
+		}
+		body := fmt.Sprintf("%s jatuh tempo %s WIB", ticket.Title, ticket.DueAt.In(reportLocationWIB).Format("02 Jan 15:04"))
+		title := fmt.Sprintf("SLA tiket %s segera habis", ticket.ID)
+		if !ticket.DueAt.After(now) {
+			title = fmt.Sprintf("SLA tiket %s terlewati", ticket.ID)
+		}
+		service.deliver(ctx, recipients, webPushPayload{
+			Type:     "ticket.sla_warning",
+			Title:    title,
+			Body:     truncateString(body, 300),
+			TicketID: ticket.ID,
+			URL:      "/tickets/" + ticket.ID,
+			Tag:      "sla-" + ticket.ID,
+		})
+	}
+	if err := service.tickets.MarkSLAWarned(warned, now); err != nil {
+		return 0, err
+	}
+	return len(warned), nil
+}
+
+// RunSLAWatcher memeriksa SLA secara berkala sampai ctx dibatalkan.
+func (service *WebPushService) RunSLAWatcher(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if count, err := service.CheckSLAWarnings(ctx); err != nil {
+			log.Printf("sla watcher failed: %v", err)
+		} else if count > 0 {
+			log.Printf("sla watcher warned tickets=%d", count)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// recipients memilih admin aktif yang memantau kategori tiket atau salah satu
+// parent-nya. Admin tanpa preferensi menerima semua notifikasi.
+func (service *WebPushService) recipients(
+	tree categoryTree,
+	categoryID string,
+	wants func(domain.WebPushPreference) bool,
+) ([]string, error) {
+	admins, err := service.users.ListActiveByRole(domain.RoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	adminIDs := make([]string, 0, len(admins))
+	for _, admin := range admins {
+		adminIDs = append(adminIDs, admin.ID)
+	}
+	preferences, err := service.store.ListPreferences(adminIDs)
+	if err != nil {
+		return nil, err
+	}
+	byUser := make(map[string]domain.WebPushPreference, len(preferences))
+	for _, preference := range preferences {
+		byUser[preference.UserID] = preference
+	}
+
+	scope := tree.ancestors(categoryID)
+	result := make([]string, 0, len(adminIDs))
+	for _, adminID := range adminIDs {
+		preference, ok := byUser[adminID]
+		if !ok {
+			preference = defaultWebPushPreference(adminID)
+		}
+		if !wants(preference) {
+			continue
+		}
+		watched := decodeStringList(preference.CategoryIDs)
+		if len(watched) > 0 && !slices.ContainsFunc(watched, func(id string) bool { return slices.Contains(scope, id) }) {
+			continue
+		}
+		result = append(result, adminID)
+	}
+	return result, nil
+}
+
+// deliver mengirim payload ke seluruh subscription milik userIDs, menghapus
+// subscription yang kedaluwarsa, dan mencatat hasilnya di push log.
+func (service *WebPushService) deliver(ctx context.Context, userIDs []string, payload webPushPayload) {
+	if len(userIDs) == 0 {
+		return
+	}
+	subscriptions, err := service.store.ListByUsers(userIDs)
+	if err != nil {
+		log.Printf("webpush list subscriptions failed: %v", err)
+		return
+	}
+	if len(subscriptions) == 0 {
+		return
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("webpush encode payload failed: %v", err)
+		return
+	}
+
+	now := service.now()
+	sent := make([]string, 0, len(subscriptions))
+	expired := make([]string, 0)
+	logs := make([]domain.PushLog, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		entry := domain.PushLog{
+			ID:        util.NewUUID(),
+			Backend:   webPushBackend,
+			UserID:    subscription.UserID,
+			TokenHint: endpointHint(subscription.Endpoint),
+			Title:     truncateString(payload.Title, 160),
+			Status:    "sent",
+			CreatedAt: now,
+		}
+		err := service.client.Send(ctx, webpush.Subscription{
+			Endpoint: subscription.Endpoint,
+			P256dh:   subscription.P256dh,
+			Auth:     subscription.Auth,
+		}, body)
+		switch {
+		case errors.Is(err, webpush.ErrSubscriptionGone):
+			expired = append(expired, subscription.ID)
+			entry.Status = "invalid"
+			entry.Error = err.Error()
+		case err != nil:
+			entry.Status = "failed"
+			entry.Error = err.Error()
+		default:
+			sent = append(sent, subscription.ID)
+		}
+		logs = append(logs, entry)
+	}
+
+	if err := service.store.DeleteByIDs(expired); err != nil {
+		log.Printf("webpush delete expired subscriptions failed: %v", err)
+	}
+	if err := service.store.TouchSubscriptions(sent, now); err != nil {
+		log.Printf("webpush touch subscriptions failed: %v", err)
+	}
+	if err := service.pushLogs.CreateBatch(logs); err != nil {
+		log.Printf("failed to write push logs count=%d: %v", len(logs), err)
+	}
+}
+
+func (service *WebPushService) categoryTree() (categoryTree, error) {
+	categories, err := service.categories.List()
+	if err != nil {
+		return categoryTree{}, err
+	}
+	return newCategoryTree(categories), nil
+}
+
+func defaultWebPushPreference(userID string) domain.WebPushPreference {
+	return domain.WebPushPreference{
+		UserID:      userID,
+		CategoryIDs: datatypes.JSON("[]"),
+		NewTickets:  true,
+		SLAWarnings: true,
+	}
+}
+
+// endpointHint hanya menyimpan host push service (mis. fcm.googleapis.com) karena
+// path endpoint berfungsi sebagai kredensial.
+func endpointHint(endpoint string) string {
+	parsed, err := url.Parse(endpoint)
+	if err != nil {
+		return ""
+	}
+	return truncateString(parsed.Host, 24)
+}
+
+func toWebPushPreferenceDTO(preference domain.WebPushPreference) domain.WebPushPreferenceDTO {
+	return domain.WebPushPreferenceDTO{
+		CategoryIDs: decodeStringList(preference.CategoryIDs),
+		NewTickets:  preference.NewTickets,
+		SLAWarnings: preference.SLAWarnings,
+	}
+}
+
+func toWebPushSubscriptionDTO(subscription domain.WebPushSubscription) domain.WebPushSubscriptionDTO {
+	return domain.WebPushSubscriptionDTO{
+		ID:         subscription.ID,
+		Endpoint:   subscription.Endpoint,
+		UserAgent:  subscription.UserAgent,
+		LastUsedAt: subscription.LastUsedAt,
+		CreatedAt:  subscription.CreatedAt,
+	}
+}
@@ -1,5 +1,12 @@
 package util
 
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
 // CalcTotalPages menghitung jumlah halaman dari total item dan limit per halaman.
 func CalcTotalPages(total int64, limit int) int {
 	if limit <= 0 {
@@ -7,3 +14,41 @@ func CalcTotalPages(total int64, limit int) int {
 	}
 	return int((total + int64(limit) - 1) / int64(limit))
 }
+
+var ErrInvalidCursor = errors.New("cursor tidak valid")
+
+// Cursor menandai posisi keyset (created_at, id) pada daftar berurutan.
+// Backward berarti halaman diambil ke arah sebelum posisi tersebut.
+type Cursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        string    `json:"id"`
+	Backward  bool      `json:"b,omitempty"`
+}
+
+// EncodeCursor mengubah cursor menjadi string opaque untuk klien.
+func EncodeCursor(cursor Cursor) string {
+	payload, err := json.Marshal(cursor)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(payload)
+}
+
+// DecodeCursor membaca cursor dari string opaque. String kosong menghasilkan nil.
+func DecodeCursor(raw string) (*Cursor, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor Cursor
+	if err := json.Unmarshal(payload, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if cursor.ID == "" || cursor.CreatedAt.IsZero() {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
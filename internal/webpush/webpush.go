@@ -0,0 +1,271 @@
+// Package webpush mengirim notifikasi Web Push standar (RFC 8030) dengan enkripsi
+// aes128gcm (RFC 8291) dan autentikasi VAPID (RFC 8292) tanpa bergantung pada Firebase.
+package webpush
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	recordSize = 4096
+	// MaxPayloadSize adalah batas plaintext agar muat dalam satu record aes128gcm.
+	MaxPayloadSize = recordSize - 16 - 1 - 86
+	vapidTokenTTL  = 12 * time.Hour
+	defaultTTL     = 24 * time.Hour
+)
+
+// ErrSubscriptionGone dikembalikan saat push service membalas 404/410; subscription
+// harus dihapus.
+var ErrSubscriptionGone = errors.New("webpush subscription expired")
+
+// Subscription adalah PushSubscription dari browser.
+type Subscription struct {
+	Endpoint string
+	P256dh   string
+	Auth     string
+}
+
+type Client struct {
+	publicKey  string
+	privateKey *ecdsa.PrivateKey
+	subject    string
+	httpClient *http.Client
+}
+
+// NewClient membuat client dari pasangan kunci VAPID berformat base64url (public
+// key 65 byte uncompressed, private key 32 byte). Mengembalikan nil jika kunci
+// kosong sehingga Web Push nonaktif.
+func NewClient(publicKey string, privateKey string, subject string) (*Client, error) {
+	publicKey = strings.TrimSpace(publicKey)
+	privateKey = strings.TrimSpace(privateKey)
+	if publicKey == "" || privateKey == "" {
+		return nil, nil
+	}
+	key, err := parsePrivateKey(publicKey, privateKey)
+	if err != nil {
+		return nil, err
+	}
+	if subject == "" {
+		subject = "mailto:helpdesk@unila.ac.id"
+	}
+	return &Client{
+		publicKey:  publicKey,
+		privateKey: key,
+		subject:    subject,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// PublicKey adalah applicationServerKey untuk pushManager.subscribe di browser.
+func (client *Client) PublicKey() string {
+	if client == nil {
+		return ""
+	}
+	return client.publicKey
+}
+
+// Send mengenkripsi payload untuk subscription lalu mengirimnya ke push service.
+func (client *Client) Send(ctx context.Context, subscription Subscription, payload []byte) error {
+	if client == nil {
+		return nil
+	}
+	if len(payload) > MaxPayloadSize {
+		return fmt.Errorf("webpush payload too large: %d bytes", len(payload))
+	}
+	body, err := encrypt(subscription, payload)
+	if err != nil {
+		return err
+	}
+	authorization, err := client.vapidAuthorization(subscription.Endpoint)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Encoding", "aes128gcm")
+	request.Header.Set("Content-Type", "application/octet-stream")
+	request.Header.Set("TTL", fmt.Sprintf("%d", int(defaultTTL.Seconds())))
+	request.Header.Set("Urgency", "high")
+	request.Header.Set("Authorization", authorization)
+
+	response, err := client.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, 4096))
+	switch {
+	case response.StatusCode == http.StatusNotFound || response.StatusCode == http.StatusGone:
+		return ErrSubscriptionGone
+	case response.StatusCode >= 300:
+		return fmt.Errorf("webpush endpoint returned %d", response.StatusCode)
+	}
+	return nil
+}
+
+func (client *Client) vapidAuthorization(endpoint string) (string, error) {
+	parsed, err := url.Parse(endpoint)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return "", errors.New("webpush endpoint tidak valid")
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": parsed.Scheme + "://" + parsed.Host,
+		"exp": time.Now().Add(vapidTokenTTL).Unix(),
+		"sub": client.subject,
+	})
+	signed, err := token.SignedString(client.privateKey)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("vapid t=%s, k=%s", signed, client.publicKey), nil
+}
+
+// encrypt menghasilkan body aes128gcm sesuai RFC 8291 dengan satu record memakai
+// kunci ephemeral dan salt acak.
+func encrypt(subscription Subscription, payload []byte) ([]byte, error) {
+	local, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return encryptWith(subscription, payload, local, salt)
+}
+
+// encryptWith memisahkan kunci ephemeral dan salt dari encrypt agar hasilnya dapat
+// dicocokkan dengan test vector RFC 8291.
+func encryptWith(subscription Subscription, payload []byte, local *ecdh.PrivateKey, salt []byte) ([]byte, error) {
+	userPublic, err := decodeKey(subscription.P256dh)
+	if err != nil {
+		return nil, fmt.Errorf("p256dh tidak valid: %w", err)
+	}
+	authSecret, err := decodeKey(subscription.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("auth tidak valid: %w", err)
+	}
+	remote, err := ecdh.P256().NewPublicKey(userPublic)
+	if err != nil {
+		return nil, fmt.Errorf("p256dh tidak valid: %w", err)
+	}
+	sharedSecret, err := local.ECDH(remote)
+	if err != nil {
+		return nil, err
+	}
+	localPublic := local.PublicKey().Bytes()
+
+	keyInfo := append([]byte("WebPush: info\x00"), userPublic...)
+	keyInfo = append(keyInfo, localPublic...)
+	ikm, err := deriveKey(sharedSecret, authSecret, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+	contentKey, err := deriveKey(ikm, salt, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := deriveKey(ikm, salt, []byte("Content-Encoding: nonce\x00"), 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(contentKey)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	// 0x02 menandai record terakhir tanpa padding tambahan.
+	plaintext := append(append([]byte{}, payload...), 0x02)
+	ciphertext := gcm.Seal(nil, nonce, plaintext, nil)
+
+	header := make([]byte, 0, 16+4+1+len(localPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, recordSize)
+	header = append(header, byte(len(localPublic)))
+	header = append(header, localPublic...)
+	return append(header, ciphertext...), nil
+}
+
+func deriveKey(secret []byte, salt []byte, info []byte, length int) ([]byte, error) {
+	key := make([]byte, length)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, info), key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// GenerateVAPIDKeys membuat pasangan kunci VAPID baru berformat base64url.
+func GenerateVAPIDKeys() (publicKey string, privateKey string, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	ecdhKey, err := key.ECDH()
+	if err != nil {
+		return "", "", err
+	}
+	private := make([]byte, 32)
+	key.D.FillBytes(private)
+	return base64.RawURLEncoding.EncodeToString(ecdhKey.PublicKey().Bytes()),
+		base64.RawURLEncoding.EncodeToString(private), nil
+}
+
+func parsePrivateKey(publicKey string, privateKey string) (*ecdsa.PrivateKey, error) {
+	raw, err := decodeKey(privateKey)
+	if err != nil || len(raw) != 32 {
+		return nil, errors.New("VAPID private key tidak valid")
+	}
+	ecdhKey, err := ecdh.P256().NewPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("VAPID private key tidak valid: %w", err)
+	}
+	expectedPublic, err := decodeKey(publicKey)
+	if err != nil || !bytes.Equal(expectedPublic, ecdhKey.PublicKey().Bytes()) {
+		return nil, errors.New("VAPID public key tidak cocok dengan private key")
+	}
+	point := ecdhKey.PublicKey().Bytes()
+	return &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(point[1:33]),
+			Y:     new(big.Int).SetBytes(point[33:65]),
+		},
+		D: new(big.Int).SetBytes(raw),
+	}, nil
+}
+
+// decodeKey menerima base64url maupun base64 standar, dengan atau tanpa padding,
+// karena browser dan library klien berbeda-beda.
+func decodeKey(value string) ([]byte, error) {
+	value = strings.TrimRight(strings.TrimSpace(value), "=")
+	value = strings.NewReplacer("+", "-", "/", "_").Replace(value)
+	return base64.RawURLEncoding.DecodeString(value)
+}
@@ -0,0 +1,64 @@
+package webpush
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"encoding/base64"
+	"testing"
+)
+
+// Test vector dari RFC 8291 Appendix A.
+const (
+	rfcPlaintext        = "When I grow up, I want to be a watermelon"
+	rfcServerPrivateKey = "yfWPiYE-n46HLnH0KqZOF1fJJU3MYrct3AELtAQ-oRw"
+	rfcUserPublicKey    = "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4"
+	rfcAuthSecret       = "BTBZMqHH6r4Tts7J_aSIgg"
+	rfcSalt             = "DGv6ra1nlYgDCS1FRnbzlw"
+	rfcBody             = "DGv6ra1nlYgDCS1FRnbzlwAAEABBBP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A_yl95bQpu6cVPTpK4Mqgkf1CXztLVBSt2Ks3oZwbuwXPXLWyouBWLVWGNWQexSgSxsj_Qulcy4a-fN"
+)
+
+func TestEncryptRFC8291Vector(t *testing.T) {
+	rawPrivate, err := decodeKey(rfcServerPrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	local, err := ecdh.P256().NewPrivateKey(rawPrivate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	salt, err := decodeKey(rfcSalt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	subscription := Subscription{P256dh: rfcUserPublicKey, Auth: rfcAuthSecret}
+
+	body, err := encryptWith(subscription, []byte(rfcPlaintext), local, salt)
+	if err != nil {
+		t.Fatalf("encryptWith: %v", err)
+	}
+	expected, err := decodeKey(rfcBody)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(body, expected) {
+		t.Fatalf("body tidak cocok dengan RFC 8291\n got: %s\nwant: %s", base64.RawURLEncoding.EncodeToString(body), rfcBody)
+	}
+}
+
+func TestEncryptUsesFreshKeyAndSalt(t *testing.T) {
+	subscription := Subscription{P256dh: rfcUserPublicKey, Auth: rfcAuthSecret}
+	first, err := encrypt(subscription, []byte(rfcPlaintext))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := encrypt(subscription, []byte(rfcPlaintext))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(first[:16], second[:16]) {
+		t.Fatal("salt harus acak untuk setiap pesan")
+	}
+	if bytes.Equal(first[21:86], second[21:86]) {
+		t.Fatal("kunci ephemeral harus baru untuk setiap pesan")
+	}
+}